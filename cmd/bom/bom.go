@@ -0,0 +1,118 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+const (
+	// FormatCSV writes comma-separated values
+	FormatCSV = "csv"
+	// FormatJSON writes a JSON object
+	FormatJSON = "json"
+	// FormatMarkdown writes Markdown tables
+	FormatMarkdown = "markdown"
+)
+
+type config struct {
+	Format  *string
+	Output  *string
+	GroupBy *string
+	Columns *string
+	Exclude *string
+}
+
+func configureFromFlags() (*config, error) {
+	formatList := "(" + strings.Join([]string{FormatCSV, FormatJSON, FormatMarkdown}, ",") + ")"
+	c := &config{
+		Format:  flag.String("format", FormatCSV, "BOM format to write "+formatList),
+		Output:  flag.String("output", "", "filename to write BOM to (default: standard output)"),
+		GroupBy: flag.String("group-by", "value,package,MPN", "comma-separated fields that identical parts share: value, library, package or attribute names"),
+		Columns: flag.String("attributes", "MANUFACTURER,MPN,SUPPLIER", "comma-separated component attributes to include in the BOM"),
+		Exclude: flag.String("exclude", "DNP,BOM_EXCLUDE,PANEL_ONLY", "comma-separated component attributes that exclude a part from the BOM if set"),
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		return nil, fmt.Errorf("exactly one Eagle board file is required")
+	}
+	return c, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func writeBOM(cfg *config, w io.Writer, bom BOM) error {
+	switch *cfg.Format {
+	case FormatCSV:
+		return WriteCSV(w, bom, splitList(*cfg.Columns))
+	case FormatJSON:
+		return WriteJSON(w, bom)
+	case FormatMarkdown:
+		return WriteMarkdown(w, bom, splitList(*cfg.Columns))
+	default:
+		return fmt.Errorf("unsupported format: %s", *cfg.Format)
+	}
+}
+
+func main() {
+	cfg, err := configureFromFlags()
+	if err != nil {
+		fmt.Printf("configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	board, err := eagle.LoadEagleFile(flag.Arg(0))
+	if err != nil {
+		fmt.Printf("can't load input file %q: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	bom, err := BuildBOM(board.Board, splitList(*cfg.GroupBy), splitList(*cfg.Columns), splitList(*cfg.Exclude))
+	if err != nil {
+		fmt.Printf("error building BOM: %v\n", err)
+		os.Exit(2)
+	}
+	w := os.Stdout
+	if *cfg.Output != "" {
+		if w, err = os.Create(*cfg.Output); err != nil {
+			fmt.Printf("can't create output file: %v\n", err)
+			os.Exit(2)
+		}
+		defer w.Close()
+	}
+	if err := writeBOM(cfg, w, bom); err != nil {
+		fmt.Printf("error writing BOM: %v\n", err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/hardware"
+)
+
+// Line is a single BOM line item: a group of identical parts
+type Line struct {
+	Quantity   int               `json:"quantity"`
+	References []string          `json:"references"`
+	Value      string            `json:"value"`
+	Library    string            `json:"library"`
+	Package    string            `json:"package"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Hardware is a single item of panel hardware, eg. a nut or washer, implied
+// by parts mounted through the panel
+type Hardware struct {
+	Item       string   `json:"item"`
+	Quantity   int      `json:"quantity"`
+	References []string `json:"references"`
+}
+
+// BOM is a complete bill of materials
+type BOM struct {
+	Lines         []Line     `json:"parts"`
+	PanelHardware []Hardware `json:"panelHardware"`
+}
+
+// fieldValue returns the value of one of the built-in grouping fields, or
+// otherwise of the named attribute
+func fieldValue(elem eagle.Element, field string) string {
+	switch strings.ToLower(field) {
+	case "value":
+		return elem.Value
+	case "library":
+		return elem.Library
+	case "package":
+		return elem.Package
+	case "name":
+		return elem.Name
+	default:
+		return eagle.AttributeString(elem, field, "")
+	}
+}
+
+// excluded reports whether an element should be left off the BOM, either
+// because Eagle says it isn't populated, or because it has one of the
+// exclusion attributes set to anything other than a false-ish value
+func excluded(elem eagle.Element, attributes []string) bool {
+	if elem.Populate == "no" {
+		return true
+	}
+	for _, name := range attributes {
+		value, found := attributeValue(elem, name)
+		if !found {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "no", "false", "0":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func attributeValue(c eagle.AttributeCarrier, name string) (string, bool) {
+	for _, attribute := range c.GetAttributes() {
+		if attribute.Name == name {
+			return attribute.Value, true
+		}
+	}
+	return "", false
+}
+
+// PanelOnly is the attribute marking dummy components that exist only to
+// create panel holes. Their panel hardware is still needed, even when the
+// part itself is excluded from the BOM.
+const PanelOnly = "PANEL_ONLY"
+
+// BuildBOM groups the elements of a board into BOM lines, using the values
+// of the groupBy fields to decide which parts are identical
+func BuildBOM(board eagle.Board, groupBy, columns, exclude []string) (BOM, error) {
+	bom := BOM{Lines: []Line{}, PanelHardware: []Hardware{}}
+	lines := make(map[string]*Line)
+	hardware := make(map[string]*Hardware)
+	hardwareExclude := []string{}
+	for _, name := range exclude {
+		if name != PanelOnly {
+			hardwareExclude = append(hardwareExclude, name)
+		}
+	}
+	for _, elem := range board.Elements {
+		if !excluded(elem, hardwareExclude) {
+			items, err := panelHardwareItems(elem)
+			if err != nil {
+				return BOM{}, fmt.Errorf("%s: %v", elem.Name, err)
+			}
+			for _, item := range items {
+				hw, found := hardware[item]
+				if !found {
+					hw = &Hardware{Item: item}
+					hardware[item] = hw
+				}
+				hw.Quantity++
+				hw.References = append(hw.References, elem.Name)
+			}
+		}
+		if excluded(elem, exclude) {
+			continue
+		}
+		keyparts := []string{}
+		for _, field := range groupBy {
+			keyparts = append(keyparts, fieldValue(elem, field))
+		}
+		key := strings.Join(keyparts, "\x00")
+		line, found := lines[key]
+		if !found {
+			line = &Line{
+				Value:      elem.Value,
+				Library:    elem.Library,
+				Package:    elem.Package,
+				Attributes: make(map[string]string),
+			}
+			for _, column := range columns {
+				line.Attributes[column] = eagle.AttributeString(elem, column, "")
+			}
+			lines[key] = line
+		}
+		line.Quantity++
+		line.References = append(line.References, elem.Name)
+	}
+	for _, line := range lines {
+		sort.Slice(line.References, func(i, j int) bool {
+			return referenceLess(line.References[i], line.References[j])
+		})
+		bom.Lines = append(bom.Lines, *line)
+	}
+	sort.Slice(bom.Lines, func(i, j int) bool {
+		return referenceLess(bom.Lines[i].References[0], bom.Lines[j].References[0])
+	})
+	for _, hw := range hardware {
+		sort.Slice(hw.References, func(i, j int) bool {
+			return referenceLess(hw.References[i], hw.References[j])
+		})
+		bom.PanelHardware = append(bom.PanelHardware, *hw)
+	}
+	sort.Slice(bom.PanelHardware, func(i, j int) bool {
+		return bom.PanelHardware[i].Item < bom.PanelHardware[j].Item
+	})
+	return bom, nil
+}
+
+// panelHardwareItems lists the panel hardware needed to mount an element
+// through the panel. The PANEL_HARDWARE attribute, a comma-separated list,
+// overrides everything else. Otherwise, there is a nut and washer, named
+// after the PANEL_NUT catalogue item if given, or else sized for the panel
+// hole, and a knob if PANEL_KNOB or PANEL_KNOB_MM is given. These are the
+// same attributes go-eagle checks for clearance; plugs belong to patch
+// cables rather than the module, so PANEL_PLUG isn't listed.
+func panelHardwareItems(elem eagle.Element) ([]string, error) {
+	drillmm, err := eagle.AttributeFloat(elem, "PANEL_DRILL_MM", -1.0)
+	if err != nil {
+		return nil, err
+	}
+	if override, found := attributeValue(elem, "PANEL_HARDWARE"); found {
+		items := []string{}
+		for _, item := range strings.Split(override, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	if drillmm < 0.0 {
+		return nil, nil
+	}
+	items := []string{}
+	if nut := eagle.AttributeString(elem, "PANEL_NUT", ""); nut != "" {
+		if _, err := hardware.Lookup(hardware.Nut, nut); err != nil {
+			return nil, err
+		}
+		nut = strings.ToLower(nut)
+		items = append(items, nut+" nut", nut+" washer")
+	} else {
+		size := strconv.FormatFloat(drillmm, 'f', -1, 64)
+		items = append(items, "nut for "+size+"mm panel hole", "washer for "+size+"mm panel hole")
+	}
+	knobmm, err := eagle.AttributeFloat(elem, "PANEL_KNOB_MM", 0.0)
+	if err != nil {
+		return nil, err
+	}
+	if knobmm > 0 {
+		items = append(items, strconv.FormatFloat(knobmm, 'f', -1, 64)+"mm knob")
+	} else if knob := eagle.AttributeString(elem, "PANEL_KNOB", ""); knob != "" {
+		if _, err := hardware.Lookup(hardware.Knob, knob); err != nil {
+			return nil, err
+		}
+		items = append(items, strings.ToLower(knob)+" knob")
+	}
+	return items, nil
+}
+
+// referenceLess compares part references such that R2 sorts before R10
+func referenceLess(a, b string) bool {
+	ap, an := splitReference(a)
+	bp, bn := splitReference(b)
+	if ap != bp {
+		return ap < bp
+	}
+	if an != bn {
+		return an < bn
+	}
+	return a < b
+}
+
+func splitReference(ref string) (string, int) {
+	end := len(ref)
+	for end > 0 && unicode.IsDigit(rune(ref[end-1])) {
+		end--
+	}
+	n, err := strconv.Atoi(ref[end:])
+	if err != nil {
+		return ref, -1
+	}
+	return ref[:end], n
+}
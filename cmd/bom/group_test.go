@@ -0,0 +1,238 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+// element returns a board element with the given attributes, as name/value
+// pairs
+func element(name, value, pkg string, attributes ...string) eagle.Element {
+	elem := eagle.Element{Name: name, Value: value, Library: "rcl", Package: pkg}
+	for i := 0; i+1 < len(attributes); i += 2 {
+		elem.Attributes = append(elem.Attributes, eagle.Attribute{Name: attributes[i], Value: attributes[i+1]})
+	}
+	return elem
+}
+
+// testElements is a small synth module: resistors, pots, jacks and a dummy
+// LED bezel that exists only to make a panel hole
+func testElements() []eagle.Element {
+	unpopulated := element("R4", "10k", "0805")
+	unpopulated.Populate = "no"
+	return []eagle.Element{
+		element("R10", "10k", "0805", "MPN", "RC0805-10K"),
+		element("R2", "10k", "0805", "MPN", "RC0805-10K"),
+		element("R3", "10k", "0805", "MPN", "RC0805-10K-ALT"),
+		unpopulated,
+		element("C1", "100n", "0805", "DNP", "yes"),
+		element("C2", "100n", "0805", "DNP", "no"),
+		element("VR1", "B100k", "9MM", "PANEL_DRILL_MM", "7"),
+		element("VR2", "B10k", "9MM", "PANEL_DRILL_MM", "7", "PANEL_NUT", "M7-hex", "PANEL_KNOB", "Medium", "PANEL_PLUG", "3.5mm"),
+		element("VR3", "B10k", "9MM", "PANEL_DRILL_MM", "7", "PANEL_KNOB", "large", "PANEL_KNOB_MM", "12"),
+		element("J1", "jack", "PJ301M", "PANEL_DRILL_MM", "6"),
+		element("J2", "jack", "PJ301M", "PANEL_DRILL_MM", "6"),
+		element("SW1", "toggle", "MTS", "PANEL_DRILL_MM", "6", "PANEL_HARDWARE", "6mm nut, toggle washer,"),
+		element("LED1", "bezel", "BEZEL", "PANEL_DRILL_MM", "5", "PANEL_ONLY", "yes"),
+		element("LED2", "bezel", "BEZEL", "PANEL_DRILL_MM", "5", "PANEL_ONLY", "yes", "DNP", "yes"),
+	}
+}
+
+func TestBuildBOM(t *testing.T) {
+	exclude := []string{"DNP", "BOM_EXCLUDE", "PANEL_ONLY"}
+	tests := []struct {
+		name     string
+		groupBy  []string
+		exclude  []string
+		lines    map[string][]string
+		hardware map[string][]string
+	}{
+		{
+			name:    "default grouping",
+			groupBy: []string{"value", "package", "MPN"},
+			exclude: exclude,
+			lines: map[string][]string{
+				"100n":   {"C2"},
+				"jack":   {"J1", "J2"},
+				"10k":    {"R2", "R10"},
+				"10k ":   {"R3"},
+				"toggle": {"SW1"},
+				"B100k":  {"VR1"},
+				"B10k":   {"VR2", "VR3"},
+			},
+			hardware: map[string][]string{
+				"6mm nut":                   {"SW1"},
+				"toggle washer":             {"SW1"},
+				"nut for 5mm panel hole":    {"LED1"},
+				"washer for 5mm panel hole": {"LED1"},
+				"nut for 6mm panel hole":    {"J1", "J2"},
+				"washer for 6mm panel hole": {"J1", "J2"},
+				"nut for 7mm panel hole":    {"VR1", "VR3"},
+				"washer for 7mm panel hole": {"VR1", "VR3"},
+				"m7-hex nut":                {"VR2"},
+				"m7-hex washer":             {"VR2"},
+				"medium knob":               {"VR2"},
+				"12mm knob":                 {"VR3"},
+			},
+		},
+		{
+			name:    "group by value only, nothing excluded",
+			groupBy: []string{"value"},
+			lines: map[string][]string{
+				"100n":   {"C1", "C2"},
+				"bezel":  {"LED1", "LED2"},
+				"jack":   {"J1", "J2"},
+				"10k":    {"R2", "R3", "R10"},
+				"toggle": {"SW1"},
+				"B100k":  {"VR1"},
+				"B10k":   {"VR2", "VR3"},
+			},
+			hardware: map[string][]string{
+				"6mm nut":                   {"SW1"},
+				"toggle washer":             {"SW1"},
+				"nut for 5mm panel hole":    {"LED1", "LED2"},
+				"washer for 5mm panel hole": {"LED1", "LED2"},
+				"nut for 6mm panel hole":    {"J1", "J2"},
+				"washer for 6mm panel hole": {"J1", "J2"},
+				"nut for 7mm panel hole":    {"VR1", "VR3"},
+				"washer for 7mm panel hole": {"VR1", "VR3"},
+				"m7-hex nut":                {"VR2"},
+				"m7-hex washer":             {"VR2"},
+				"medium knob":               {"VR2"},
+				"12mm knob":                 {"VR3"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bom, err := BuildBOM(eagle.Board{Elements: testElements()}, test.groupBy, []string{"MPN"}, test.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := map[string][]string{}
+			for _, line := range bom.Lines {
+				key := line.Value
+				if _, found := lines[key]; found {
+					key += " "
+				}
+				if line.Quantity != len(line.References) {
+					t.Errorf("%s: quantity %d doesn't match references %v", line.Value, line.Quantity, line.References)
+				}
+				lines[key] = line.References
+			}
+			if !reflect.DeepEqual(lines, test.lines) {
+				t.Errorf("got lines %v, want %v", lines, test.lines)
+			}
+			hardware := map[string][]string{}
+			for _, hw := range bom.PanelHardware {
+				hardware[hw.Item] = hw.References
+			}
+			if !reflect.DeepEqual(hardware, test.hardware) {
+				t.Errorf("got hardware %v, want %v", hardware, test.hardware)
+			}
+		})
+	}
+}
+
+func TestBuildBOMErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		elem eagle.Element
+	}{
+		{name: "bad drill size", elem: element("J1", "jack", "PJ301M", "PANEL_DRILL_MM", "six")},
+		{name: "unknown nut", elem: element("J1", "jack", "PJ301M", "PANEL_DRILL_MM", "6", "PANEL_NUT", "m5-wing")},
+		{name: "unknown knob", elem: element("VR1", "B10k", "9MM", "PANEL_DRILL_MM", "7", "PANEL_KNOB", "huge")},
+		{name: "bad knob size", elem: element("VR1", "B10k", "9MM", "PANEL_DRILL_MM", "7", "PANEL_KNOB_MM", "big")},
+	}
+	for _, test := range tests {
+		board := eagle.Board{Elements: []eagle.Element{test.elem}}
+		if _, err := BuildBOM(board, []string{"value"}, nil, nil); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "yes", want: true},
+		{value: "", want: true},
+		{value: "1", want: true},
+		{value: "no", want: false},
+		{value: " FALSE ", want: false},
+		{value: "0", want: false},
+	}
+	for _, test := range tests {
+		elem := element("R1", "10k", "0805", "DNP", test.value)
+		if got := excluded(elem, []string{"DNP"}); got != test.want {
+			t.Errorf("DNP=%q: got %v, want %v", test.value, got, test.want)
+		}
+		if excluded(elem, []string{"BOM_EXCLUDE"}) {
+			t.Errorf("DNP=%q: excluded by an unset attribute", test.value)
+		}
+	}
+}
+
+func TestReferenceLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "R2", b: "R10", want: true},
+		{a: "R10", b: "R2", want: false},
+		{a: "C10", b: "R1", want: true},
+		{a: "R1", b: "R1", want: false},
+		{a: "R", b: "R1", want: true},
+		{a: "R01", b: "R1", want: true},
+		{a: "J1A", b: "J1B", want: true},
+	}
+	for _, test := range tests {
+		if got := referenceLess(test.a, test.b); got != test.want {
+			t.Errorf("referenceLess(%q, %q): got %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	tests := []struct {
+		ref    string
+		prefix string
+		n      int
+	}{
+		{ref: "R10", prefix: "R", n: 10},
+		{ref: "VR1", prefix: "VR", n: 1},
+		{ref: "LOGO", prefix: "LOGO", n: -1},
+		{ref: "J1A", prefix: "J1A", n: -1},
+		{ref: "42", prefix: "", n: 42},
+	}
+	for _, test := range tests {
+		prefix, n := splitReference(test.ref)
+		if prefix != test.prefix || n != test.n {
+			t.Errorf("splitReference(%q): got %q, %d, want %q, %d", test.ref, prefix, n, test.prefix, test.n)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes the BOM as a single CSV table, so that spreadsheets can
+// sort and filter it. Panel hardware rows follow the parts, with the item in
+// the Value column and no library or package.
+func WriteCSV(w io.Writer, bom BOM, columns []string) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{"Quantity", "References", "Value", "Library", "Package"}, columns...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, line := range bom.Lines {
+		record := []string{
+			strconv.Itoa(line.Quantity),
+			strings.Join(line.References, " "),
+			line.Value,
+			line.Library,
+			line.Package,
+		}
+		for _, column := range columns {
+			record = append(record, line.Attributes[column])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	for _, hw := range bom.PanelHardware {
+		record := make([]string, len(header))
+		record[0] = strconv.Itoa(hw.Quantity)
+		record[1] = strings.Join(hw.References, " ")
+		record[2] = hw.Item
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the BOM as an indented JSON object
+func WriteJSON(w io.Writer, bom BOM) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bom)
+}
+
+// WriteMarkdown writes the BOM as Markdown tables, suitable for a README
+func WriteMarkdown(w io.Writer, bom BOM, columns []string) error {
+	var sb strings.Builder
+	header := append([]string{"Quantity", "References", "Value", "Library", "Package"}, columns...)
+	sb.WriteString("## parts\n\n")
+	markdownRow(&sb, header)
+	markdownRule(&sb, len(header))
+	for _, line := range bom.Lines {
+		row := []string{
+			strconv.Itoa(line.Quantity),
+			strings.Join(line.References, ", "),
+			line.Value,
+			line.Library,
+			line.Package,
+		}
+		for _, column := range columns {
+			row = append(row, line.Attributes[column])
+		}
+		markdownRow(&sb, row)
+	}
+	if len(bom.PanelHardware) > 0 {
+		sb.WriteString("\n## panel hardware\n\n")
+		markdownRow(&sb, []string{"Quantity", "Item", "References"})
+		markdownRule(&sb, 3)
+		for _, hw := range bom.PanelHardware {
+			markdownRow(&sb, []string{strconv.Itoa(hw.Quantity), hw.Item, strings.Join(hw.References, ", ")})
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownRow(sb *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for index, cell := range cells {
+		escaped[index] = strings.Replace(cell, "|", "\\|", -1)
+	}
+	fmt.Fprintf(sb, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+func markdownRule(sb *strings.Builder, n int) {
+	sb.WriteString("|" + strings.Repeat(" --- |", n) + "\n")
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestWriters(t *testing.T) {
+	columns := []string{"MPN"}
+	bom, err := BuildBOM(eagle.Board{Elements: testElements()}, []string{"value", "package", "MPN"}, columns, []string{"DNP", "PANEL_ONLY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		golden string
+		write  func(io.Writer) error
+	}{
+		{golden: "bom.csv", write: func(w io.Writer) error { return WriteCSV(w, bom, columns) }},
+		{golden: "bom.json", write: func(w io.Writer) error { return WriteJSON(w, bom) }},
+		{golden: "bom.md", write: func(w io.Writer) error { return WriteMarkdown(w, bom, columns) }},
+	}
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := test.write(&got); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", test.golden)
+			if *update {
+				if err := ioutil.WriteFile(golden, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("output differs from %s:\n%s", golden, got.Bytes())
+			}
+		})
+	}
+}
+
+func TestWriteMarkdownEscaping(t *testing.T) {
+	bom := BOM{Lines: []Line{{Quantity: 1, References: []string{"SW1"}, Value: "on|off"}}}
+	var got bytes.Buffer
+	if err := WriteMarkdown(&got, bom, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got.Bytes(), []byte(`| on\|off |`)) {
+		t.Errorf("pipe not escaped:\n%s", got.Bytes())
+	}
+	if bytes.Contains(got.Bytes(), []byte("panel hardware")) {
+		t.Errorf("empty panel hardware section written:\n%s", got.Bytes())
+	}
+}
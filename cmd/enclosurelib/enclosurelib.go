@@ -0,0 +1,219 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	filespec "github.com/jsleeio/go-eagle/pkg/format/spec"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+
+	"github.com/jsleeio/go-eagle/internal/boardops/util"
+)
+
+const (
+	// DocuWidth is the line width used for documentation-only features
+	DocuWidth = 0.1
+	// OriginMarkerSize is the length of each arm of the origin marker cross
+	OriginMarkerSize = 2.0
+)
+
+type config struct {
+	Output        *string
+	RefBoard      *string
+	SpecFile      *string
+	KeepoutWidth  *float64
+	MountingHoles *bool
+}
+
+func configureFromFlags() (*config, error) {
+	c := &config{
+		RefBoard:      flag.String("reference-board", "", "reference Eagle board file to read layer information from"),
+		SpecFile:      flag.String("spec-file", "", "filename to read YAML enclosure spec from"),
+		Output:        flag.String("output", "", "filename to write new Eagle library file to (default: enclosure name + .lbr)"),
+		KeepoutWidth:  flag.Float64("keepout-width", 2.0, "width of the component keepout ring around each mounting hole"),
+		MountingHoles: flag.Bool("mounting-holes", false, "drill the mounting holes through the PCB too, eg. for screws that pass through it"),
+	}
+	flag.Parse()
+	if *c.RefBoard == "" {
+		return nil, fmt.Errorf("a reference board file (-reference-board option) is required to acquire a list of Eagle layers")
+	}
+	if *c.SpecFile == "" {
+		return nil, fmt.Errorf("an enclosure spec file (-spec-file option) is required")
+	}
+	return c, nil
+}
+
+// enclosurePackage generates a package with the enclosure's outline and
+// cutouts, mounting hole keepouts, any other keepouts and an origin marker.
+// The package origin is the bottom-left corner of the enclosure, matching
+// panel coordinates.
+func enclosurePackage(ref *eagle.Eagle, cfg *config, name string, spec panel.Panel) eagle.Package {
+	pkg := eagle.Package{
+		Name:        name,
+		Description: &eagle.Description{Text: fmt.Sprintf("Outline and keepouts for the %s enclosure", name)},
+	}
+	adjust := spec.HorizontalFit() / 2 // half on left edge, half on right edge
+	if so, ok := spec.(panel.ShapedOutline); ok && len(so.Outline()) > 0 {
+		pkg.Wires = append(pkg.Wires, util.WireOutline(so.Outline(), ref.LayerByName("Dimension"), 0)...)
+	} else if sc, ok := spec.(panel.ShapedCorners); ok {
+		outline := panel.RectangleOutline(0+adjust, 0, spec.Width()-adjust, spec.Height(), sc.Corners())
+		pkg.Wires = append(pkg.Wires, util.WireOutline(outline, ref.LayerByName("Dimension"), 0)...)
+	} else {
+		pkg.Wires = append(pkg.Wires, util.WireRectangle(
+			0+adjust,
+			0,
+			spec.Width()-adjust,
+			spec.Height(),
+			ref.LayerByName("Dimension"),
+			0, // outline wires must be zero-width
+			spec.CornerRadius(),
+		)...)
+	}
+	tKeepout := ref.LayerByName("tKeepout")
+	bKeepout := ref.LayerByName("bKeepout")
+	tDocu := ref.LayerByName("tDocu")
+	for _, hole := range spec.MountingHoles() {
+		radius := spec.MountingHoleDiameter() / 2
+		// zero-width circles are drawn filled
+		for _, layer := range []int{tKeepout, bKeepout} {
+			pkg.Circles = append(pkg.Circles, eagle.Circle{
+				X: hole.X, Y: hole.Y,
+				Radius: radius + *cfg.KeepoutWidth,
+				Layer:  layer,
+			})
+		}
+		pkg.Circles = append(pkg.Circles, eagle.Circle{
+			X: hole.X, Y: hole.Y,
+			Radius: radius,
+			Width:  DocuWidth,
+			Layer:  tDocu,
+		})
+		if *cfg.MountingHoles {
+			pkg.Holes = append(pkg.Holes, eagle.Hole{X: hole.X, Y: hole.Y, Drill: spec.MountingHoleDiameter()})
+		}
+	}
+	// keepouts inside the enclosure, eg. screw bosses
+	if kr, ok := spec.(panel.KeepoutRegions); ok {
+		for _, keepout := range kr.Keepouts() {
+			layers := []int{}
+			if keepout.Top() {
+				layers = append(layers, tKeepout)
+			}
+			if keepout.Bottom() {
+				layers = append(layers, bKeepout)
+			}
+			for _, layer := range layers {
+				circles, polygons := util.KeepoutFeatures(keepout, layer)
+				pkg.Circles = append(pkg.Circles, circles...)
+				pkg.Polygons = append(pkg.Polygons, polygons...)
+			}
+		}
+	}
+	// openings in the enclosure, drawn on the outline layer as the panel
+	// generators do
+	if cr, ok := spec.(panel.CutoutRegions); ok {
+		for _, cutout := range cr.Cutouts() {
+			pkg.Wires = append(pkg.Wires, util.WireOutline(cutout.Outline(), ref.LayerByName("Dimension"), 0)...)
+		}
+	}
+	// origin marker
+	pkg.Wires = append(pkg.Wires,
+		eagle.Wire{X1: -OriginMarkerSize, Y1: 0, X2: OriginMarkerSize, Y2: 0, Width: DocuWidth, Layer: tDocu},
+		eagle.Wire{X1: 0, Y1: -OriginMarkerSize, X2: 0, Y2: OriginMarkerSize, Width: DocuWidth, Layer: tDocu},
+	)
+	pkg.Circles = append(pkg.Circles, eagle.Circle{Radius: OriginMarkerSize / 2, Width: DocuWidth, Layer: tDocu})
+	pkg.Texts = append(pkg.Texts,
+		eagle.Text{Text: ">NAME", X: spec.Width() / 2, Y: spec.Height() + 1, Size: 1.27, Layer: ref.LayerByName("tNames"), Align: "bottom-center"},
+		eagle.Text{Text: ">VALUE", X: spec.Width() / 2, Y: -1, Size: 1.27, Layer: ref.LayerByName("tValues"), Align: "top-center"},
+	)
+	return pkg
+}
+
+// enclosureSymbol generates a minimal, pinless symbol so that the package
+// can be placed from a schematic
+func enclosureSymbol(ref *eagle.Eagle, name string) eagle.Symbol {
+	return eagle.Symbol{
+		Name:  name,
+		Wires: util.WireRectangle(-10.16, -5.08, 10.16, 5.08, ref.LayerByName("Symbols"), 0.254, 0),
+		Texts: []eagle.Text{
+			{Text: ">NAME", X: -10.16, Y: 5.842, Size: 1.778, Layer: ref.LayerByName("Names")},
+			{Text: ">VALUE", X: -10.16, Y: -7.62, Size: 1.778, Layer: ref.LayerByName("Values")},
+		},
+	}
+}
+
+func generateLibraryFile(cfg *config, name string, spec panel.Panel) error {
+	// as with panelgen, the user very likely already has an Eagle board file
+	// nearby, so use it to acquire a list of layers
+	ref, err := eagle.LoadEagleFile(*cfg.RefBoard)
+	if err != nil {
+		return fmt.Errorf("can't load reference board: %v", err)
+	}
+	lbr := ref.CloneEmpty()
+	lbr.Library = eagle.NewLibrary("")
+	lbr.Library.Description = &eagle.Description{Text: fmt.Sprintf("Layout quick-start for the %s enclosure", name)}
+	lbr.Library.Packages = append(lbr.Library.Packages, enclosurePackage(ref, cfg, name, spec))
+	lbr.Library.Symbols = append(lbr.Library.Symbols, enclosureSymbol(ref, name))
+	lbr.Library.DeviceSets = append(lbr.Library.DeviceSets, eagle.DeviceSet{
+		Name:      name,
+		Prefix:    "ENC",
+		UserValue: "no",
+		Gates:     []eagle.Gate{{Name: "G$1", Symbol: name}},
+		Devices: []eagle.Device{{
+			Name:         "",
+			Package:      name,
+			Technologies: []eagle.Technology{{Name: ""}},
+		}},
+	})
+	output := *cfg.Output
+	if output == "" {
+		output = name + ".lbr"
+	}
+	if err := lbr.WriteLibraryFile(output); err != nil {
+		return fmt.Errorf("can't write output library: %v", err)
+	}
+	return nil
+}
+
+func main() {
+	cfg, err := configureFromFlags()
+	if err != nil {
+		fmt.Printf("configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	spec, err := filespec.LoadSpec(*cfg.SpecFile)
+	if err != nil {
+		fmt.Printf("error loading YAML enclosure spec from '%v': %v\n", *cfg.SpecFile, err)
+		os.Exit(1)
+	}
+	name := spec.SpecName
+	if name == "" {
+		name = "ENCLOSURE"
+	}
+	if err := generateLibraryFile(cfg, name, spec); err != nil {
+		fmt.Printf("error generating library: %v\n", err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	filespec "github.com/jsleeio/go-eagle/pkg/format/spec"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// layer numbers in data/ref.brd
+const (
+	dimension = 20
+	tKeepout  = 39
+	bKeepout  = 40
+	tDocu     = 51
+)
+
+func TestEnclosurePackage(t *testing.T) {
+	ref, err := eagle.LoadEagleFile("../../data/ref.brd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := filespec.Spec{
+		SpecName:                 "test",
+		SpecWidth:                100,
+		SpecHeight:               50,
+		SpecMountingHoles:        []panel.Point{{X: 5, Y: 5}, {X: 95, Y: 45}},
+		SpecMountingHoleDiameter: 3.2,
+		SpecKeepouts: []panel.Keepout{
+			{Region: panel.Region{Shape: panel.ShapeCircle, X: 50, Y: 25, Radius: 4}, Side: panel.SideTop},
+			{Region: panel.Region{Shape: panel.ShapeRectangle, X1: 70, Y1: 10, X2: 80, Y2: 20}},
+		},
+		SpecCutouts: []panel.Cutout{
+			{Region: panel.Region{Shape: panel.ShapeCircle, X: 20, Y: 25, Radius: 3}},
+		},
+	}
+	keepoutWidth, mountingHoles := 2.0, false
+	cfg := &config{KeepoutWidth: &keepoutWidth, MountingHoles: &mountingHoles}
+	pkg := enclosurePackage(ref, cfg, "test", spec)
+
+	circles := map[int][]eagle.Circle{}
+	for _, circle := range pkg.Circles {
+		circles[circle.Layer] = append(circles[circle.Layer], circle)
+	}
+	// a filled ring around each mounting hole on both sides, and the
+	// top-only keepout on the top side
+	wantTop := []eagle.Circle{
+		{X: 5, Y: 5, Radius: 3.6, Layer: tKeepout},
+		{X: 95, Y: 45, Radius: 3.6, Layer: tKeepout},
+		{X: 50, Y: 25, Radius: 4, Layer: tKeepout},
+	}
+	if !reflect.DeepEqual(circles[tKeepout], wantTop) {
+		t.Errorf("got tKeepout circles %+v, want %+v", circles[tKeepout], wantTop)
+	}
+	wantBottom := []eagle.Circle{
+		{X: 5, Y: 5, Radius: 3.6, Layer: bKeepout},
+		{X: 95, Y: 45, Radius: 3.6, Layer: bKeepout},
+	}
+	if !reflect.DeepEqual(circles[bKeepout], wantBottom) {
+		t.Errorf("got bKeepout circles %+v, want %+v", circles[bKeepout], wantBottom)
+	}
+	// mounting hole outlines, plus the origin marker
+	if len(circles[tDocu]) != 3 {
+		t.Errorf("got %d tDocu circles, want 3", len(circles[tDocu]))
+	}
+
+	// the rectangular keepout applies to both sides
+	layers := []int{}
+	for _, polygon := range pkg.Polygons {
+		layers = append(layers, polygon.Layer)
+		if len(polygon.Vertices) != 4 {
+			t.Errorf("got keepout polygon %+v, want a rectangle", polygon.Vertices)
+		}
+	}
+	if !reflect.DeepEqual(layers, []int{tKeepout, bKeepout}) {
+		t.Errorf("got keepout polygons on layers %v, want %v", layers, []int{tKeepout, bKeepout})
+	}
+
+	// the enclosure outline, then the cutout as two semicircles
+	outline := []eagle.Wire{}
+	for _, wire := range pkg.Wires {
+		if wire.Layer == dimension {
+			outline = append(outline, wire)
+		}
+	}
+	if len(outline) != 6 {
+		t.Fatalf("got %d Dimension wires, want 6: %+v", len(outline), outline)
+	}
+	wantCutout := []eagle.Wire{
+		{X1: 23, Y1: 25, X2: 17, Y2: 25, Curve: 180, Layer: dimension},
+		{X1: 17, Y1: 25, X2: 23, Y2: 25, Curve: 180, Layer: dimension},
+	}
+	if !reflect.DeepEqual(outline[4:], wantCutout) {
+		t.Errorf("got cutout wires %+v, want %+v", outline[4:], wantCutout)
+	}
+
+	if len(pkg.Holes) != 0 {
+		t.Errorf("got holes %+v without -mounting-holes", pkg.Holes)
+	}
+	mountingHoles = true
+	pkg = enclosurePackage(ref, cfg, "test", spec)
+	wantHoles := []eagle.Hole{{X: 5, Y: 5, Drill: 3.2}, {X: 95, Y: 45, Drill: 3.2}}
+	if !reflect.DeepEqual(pkg.Holes, wantHoles) {
+		t.Errorf("got holes %+v with -mounting-holes, want %+v", pkg.Holes, wantHoles)
+	}
+}
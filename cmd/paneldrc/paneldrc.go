@@ -0,0 +1,151 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/jsleeio/go-eagle/internal/cli"
+	"github.com/jsleeio/go-eagle/internal/outline"
+	"github.com/jsleeio/go-eagle/pkg/drc"
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	_ "github.com/jsleeio/go-eagle/pkg/format/all"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+const (
+	// widthTolerance is how closely a board's outline must match a panel
+	// width for the width to be inferred
+	widthTolerance = 0.01
+)
+
+type config struct {
+	Format       *string
+	Width        *int
+	SpecFile     *string
+	JSON         *string
+	MinHoleEdge  *float64
+	MinHoleWeb   *float64
+	MinSilkWidth *float64
+}
+
+func configureFromFlags() (*config, error) {
+	defaults := drc.DefaultRules()
+	c := &config{
+		Format:       cli.FormatFlag("panel format the board was made for"),
+		Width:        flag.Int("width", 0, "width of the panel, in integer units appropriate for the format (default: from the board outline)"),
+		SpecFile:     flag.String("spec-file", "", "filename to read YAML panel spec from"),
+		JSON:         flag.String("json", "", "filename to also write a JSON report to"),
+		MinHoleEdge:  flag.Float64("min-hole-edge", defaults.MinHoleEdge, "minimum material between a hole and the panel edge, a slot or a cutout"),
+		MinHoleWeb:   flag.Float64("min-hole-web", defaults.MinHoleWeb, "minimum material between neighbouring holes, slots and cutouts"),
+		MinSilkWidth: flag.Float64("min-silk-width", defaults.MinSilkWidth, "minimum silkscreen line width"),
+	}
+	flag.Usage = cli.Usage("[options] panel.brd")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		return nil, fmt.Errorf("exactly one Eagle panel board file is required")
+	}
+	return c, nil
+}
+
+// specForBoard creates the panel format a board was made for. Unless given,
+// the width is found by trying widths until the panel outline matches the
+// board's, starting from the smallest panel the board would fit behind.
+func specForBoard(cfg *config, board *eagle.Eagle) (panel.Panel, error) {
+	format, ok := panel.Lookup(*cfg.Format)
+	if !ok {
+		return nil, fmt.Errorf("unsupported panel format: %s", *cfg.Format)
+	}
+	opts := panel.Options{Width: *cfg.Width, SpecFile: *cfg.SpecFile}
+	if opts.Width > 0 || format.WidthForBoard == nil {
+		return format.New(opts)
+	}
+	bc, err := outline.DeriveBoardCoords(board)
+	if err != nil {
+		return nil, fmt.Errorf("can't determine board outline: %v", err)
+	}
+	// panel outlines are narrower than the panel width by the horizontal
+	// fit, so the board may be a panel of the next width down
+	guess := format.WidthForBoard(bc.Width(), bc.Height())
+	for width := guess; width > 0 && width >= guess-1; width-- {
+		opts.Width = width
+		spec, err := format.New(opts)
+		if err == nil && math.Abs(spec.Width()-spec.HorizontalFit()-bc.Width()) < widthTolerance {
+			return spec, nil
+		}
+	}
+	return nil, fmt.Errorf("board outline is %.2fmm wide, which isn't a %s panel width; use -width", bc.Width(), *cfg.Format)
+}
+
+func main() {
+	cfg, err := configureFromFlags()
+	if err != nil {
+		fmt.Printf("configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	board, err := eagle.LoadEagleFile(flag.Arg(0))
+	if err != nil {
+		fmt.Printf("can't load input file %q: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	spec, err := specForBoard(cfg, board)
+	if err != nil {
+		fmt.Printf("configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	rules := drc.Rules{
+		MinHoleEdge:  *cfg.MinHoleEdge,
+		MinHoleWeb:   *cfg.MinHoleWeb,
+		MinSilkWidth: *cfg.MinSilkWidth,
+	}
+	report, err := drc.Check(board, spec, rules)
+	if err != nil {
+		fmt.Printf("error checking panel: %v\n", err)
+		os.Exit(2)
+	}
+	if err := report.WriteText(os.Stdout, flag.Arg(0)); err != nil {
+		fmt.Printf("error writing report: %v\n", err)
+		os.Exit(2)
+	}
+	if *cfg.JSON != "" {
+		f, err := os.Create(*cfg.JSON)
+		if err != nil {
+			fmt.Printf("can't create JSON report: %v\n", err)
+			os.Exit(2)
+		}
+		// os.Exit skips deferred calls, so close explicitly and make sure
+		// the report really was written
+		err = report.WriteJSON(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Printf("error writing JSON report: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if !report.Passed() {
+		os.Exit(3)
+	}
+}
@@ -24,27 +24,17 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/jsleeio/go-eagle/pkg/eagle"
-	"github.com/jsleeio/go-eagle/pkg/format/eurorack"
-	"github.com/jsleeio/go-eagle/pkg/format/intellijel"
-	"github.com/jsleeio/go-eagle/pkg/format/pulplogic"
-	filespec "github.com/jsleeio/go-eagle/pkg/format/spec"
+	_ "github.com/jsleeio/go-eagle/pkg/format/all"
+	"github.com/jsleeio/go-eagle/pkg/gerber"
 	"github.com/jsleeio/go-eagle/pkg/panel"
+	"github.com/jsleeio/go-eagle/pkg/svg"
 
 	"github.com/jsleeio/go-eagle/internal/boardops/standard"
-)
-
-const (
-	// FormatEurorack is the Doepfer-defined 3U specification. Not Eurocard!
-	FormatEurorack = "eurorack"
-	// FormatPulplogic is the PulpLogic-defined 1U specification
-	FormatPulplogic = "pulplogic"
-	// FormatIntellijel is the Intellijel-defined 1U specification
-	FormatIntellijel = "intellijel"
-	// FormatSpec is the YAML-derived panel specification
-	FormatSpec = "spec"
+	"github.com/jsleeio/go-eagle/internal/cli"
 )
 
 type config struct {
@@ -54,18 +44,26 @@ type config struct {
 	RefBoard     *string
 	OutlineLayer *string
 	SpecFile     *string
+	Gerber       *bool
+	SVG          *bool
+	SlotLength   *float64
+	Pullback     *float64
 }
 
 func configureFromFlags() (*config, error) {
-	formatList := "(" + strings.Join([]string{FormatEurorack, FormatPulplogic, FormatIntellijel, FormatSpec}, ",") + ")"
 	c := &config{
 		Width:        flag.Int("width", 4, "width of the panel, in integer units appropriate for the format"),
-		Format:       flag.String("format", FormatEurorack, "panel format to create "+formatList),
+		Format:       cli.FormatFlag("panel format to create"),
 		RefBoard:     flag.String("reference-board", "", "reference Eagle board file to read layer information from"),
 		Output:       flag.String("output", "newpanel.brd", "filename to write new Eagle board file to"),
 		OutlineLayer: flag.String("outline-layer", "Dimension", "layer to draw board outline in"),
 		SpecFile:     flag.String("spec-file", "", "filename to read YAML panel spec from"),
+		Gerber:       flag.Bool("gerber", false, "also write Gerber and Excellon fabrication files for the panel"),
+		SVG:          flag.Bool("svg", false, "also write an SVG preview of the panel"),
+		SlotLength:   cli.MountingSlotLengthFlag(),
+		Pullback:     flag.Float64("copper-pullback", standard.CopperPullback, "distance to pull copper pours back from the panel edge, holes, cutouts and keepouts"),
 	}
+	flag.Usage = cli.Usage("")
 	flag.Parse()
 	if *c.RefBoard == "" {
 		return nil, fmt.Errorf("a reference board file (-reference-board option) is required to acquire a list of Eagle layers")
@@ -82,12 +80,26 @@ func generatePanelBoardFile(cfg *config, spec panel.Panel) error {
 		return fmt.Errorf("can't load reference board: %v", err)
 	}
 	panel := ref.CloneEmpty()
-	if err := standard.ApplyStandardBoardOperations(panel, spec); err != nil {
+	if err := standard.ApplyPanelFeatures(panel, spec); err != nil {
 		return fmt.Errorf("error creating panel features: %v", err)
 	}
+	if err := standard.ApplyCopperFill(panel, *cfg.Pullback); err != nil {
+		return fmt.Errorf("error pouring copper: %v", err)
+	}
 	if err := panel.WriteFile(*cfg.Output); err != nil {
 		return fmt.Errorf("can't write output board: %v", err)
 	}
+	basename := strings.TrimSuffix(*cfg.Output, filepath.Ext(*cfg.Output))
+	if *cfg.Gerber {
+		if _, err := gerber.WriteFabFiles(panel, basename); err != nil {
+			return fmt.Errorf("can't write fabrication files: %v", err)
+		}
+	}
+	if *cfg.SVG {
+		if err := svg.WriteFile(panel, basename+".svg"); err != nil {
+			return fmt.Errorf("can't write SVG preview: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -97,22 +109,14 @@ func main() {
 		fmt.Printf("configuration error: %v\n", err)
 		os.Exit(1)
 	}
-	var spec panel.Panel
-	switch *cfg.Format {
-	case FormatEurorack:
-		spec = eurorack.NewEurorack(*cfg.Width)
-	case FormatPulplogic:
-		spec = pulplogic.NewPulplogic(*cfg.Width)
-	case FormatIntellijel:
-		spec = intellijel.NewIntellijel(*cfg.Width)
-	case FormatSpec:
-		spec, err = filespec.LoadSpec(*cfg.SpecFile)
-		if err != nil {
-			fmt.Printf("error loading YAML panel spec from '%v': %v", *cfg.SpecFile, err)
-			os.Exit(1)
-		}
-	default:
-		fmt.Printf("unsupported format: %s\n", *cfg.Format)
+	opts := panel.Options{
+		Width:              *cfg.Width,
+		SpecFile:           *cfg.SpecFile,
+		MountingSlotLength: cli.MountingSlotLength(cfg.SlotLength),
+	}
+	spec, err := panel.NewPanel(*cfg.Format, opts)
+	if err != nil {
+		fmt.Printf("configuration error: %v\n", err)
 		os.Exit(3)
 	}
 	if err := generatePanelBoardFile(cfg, spec); err != nil {
@@ -0,0 +1,118 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package standard
+
+import (
+	"fmt"
+
+	"github.com/jsleeio/go-eagle/internal/outline"
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// copperSide names the layers that matter to the copper pour on one side
+// of a panel
+type copperSide struct {
+	copper, stop, keepout string
+}
+
+var copperSides = []copperSide{
+	{copper: "Top", stop: "tStop", keepout: "tKeepout"},
+	{copper: "Bottom", stop: "bStop", keepout: "bKeepout"},
+}
+
+// ApplyCopperFill pours copper across the Top and Bottom layers of a panel,
+// pulled back by pullback millimetres from the board outline and from
+// everything that would otherwise cut through it or leave it exposed:
+// cutouts in the Dimension and Milling layers, holes, stop mask rings and
+// keepout circles and polygons on the same side. Rail keepouts, which are
+// rectangles, are left alone, as copper behind the rails does no harm. As
+// the pour must avoid every other panel feature, apply it last.
+func ApplyCopperFill(board *eagle.Eagle, pullback float64) error {
+	bo, err := outline.FindBoardOutline(board)
+	if err != nil {
+		return fmt.Errorf("can't find panel outline for copper fill: %v", err)
+	}
+	area := geometry.Offset(geometry.NewRegion(bo.Outer, OutlineTolerance), -pullback, OutlineTolerance)
+	cutouts, err := outline.Cutouts(board, bo)
+	if err != nil {
+		return fmt.Errorf("can't find panel cutouts for copper fill: %v", err)
+	}
+	// both sides are cleared from the cutouts and holes
+	clear := geometry.Region{}
+	for _, cutout := range cutouts {
+		clear = append(clear, geometry.Offset(geometry.NewRegion(cutout, OutlineTolerance), pullback, OutlineTolerance)...)
+	}
+	for _, hole := range board.Board.Plain.Holes {
+		clear = append(clear, geometry.CircleRegion(hole.X, hole.Y, hole.Drill/2+pullback, OutlineTolerance)...)
+	}
+	for _, side := range copperSides {
+		sideClear := append(geometry.Region{}, clear...)
+		sideClear = append(sideClear, sideClearance(board, side, pullback)...)
+		pour := geometry.Difference(area, sideClear)
+		polygons, err := geometry.Keyhole(pour)
+		if err != nil {
+			return fmt.Errorf("can't pour copper on %s: %v", side.copper, err)
+		}
+		for _, points := range polygons {
+			polygon := eagle.Polygon{
+				Vertices: []eagle.Vertex{},
+				Layer:    board.LayerByName(side.copper),
+			}
+			for _, p := range points {
+				polygon.Vertices = append(polygon.Vertices, eagle.Vertex{X: p.X, Y: p.Y})
+			}
+			board.Board.Plain.Polygons = append(board.Board.Plain.Polygons, polygon)
+		}
+	}
+	return nil
+}
+
+// sideClearance returns the areas the copper pour on one side of a panel
+// must avoid, besides holes and cutouts: stop mask rings, and keepouts
+func sideClearance(board *eagle.Eagle, side copperSide, pullback float64) geometry.Region {
+	clear := geometry.Region{}
+	layers := map[int]bool{}
+	for _, name := range []string{side.stop, side.keepout} {
+		if number, ok := board.LayerNumber(name); ok {
+			layers[number] = true
+		}
+	}
+	plain := board.Board.Plain
+	for _, c := range plain.Circles {
+		if layers[c.Layer] {
+			// zero-width circles are filled, so this covers both kinds
+			clear = append(clear, geometry.CircleRegion(c.X, c.Y, c.Radius+c.Width/2+pullback, OutlineTolerance)...)
+		}
+	}
+	keepout, _ := board.LayerNumber(side.keepout)
+	for _, p := range plain.Polygons {
+		if p.Layer != keepout || len(p.Vertices) < 3 {
+			continue
+		}
+		vertices := []geometry.Vertex{}
+		for _, v := range p.Vertices {
+			vertices = append(vertices, geometry.Vertex{X: v.X, Y: v.Y, Curve: v.Curve})
+		}
+		clear = append(clear, geometry.Offset(geometry.NewRegion(vertices, OutlineTolerance), p.Width/2+pullback, OutlineTolerance)...)
+	}
+	return clear
+}
@@ -0,0 +1,237 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package standard
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// testBoard returns a 20x20mm board with the given Plain objects inside its
+// outline
+func testBoard(plain eagle.Plain) *eagle.Eagle {
+	board := &eagle.Eagle{
+		Layers: []eagle.Layer{
+			{Number: 1, Name: "Top"},
+			{Number: 16, Name: "Bottom"},
+			{Number: 20, Name: "Dimension"},
+			{Number: 29, Name: "tStop"},
+			{Number: 30, Name: "bStop"},
+			{Number: 39, Name: "tKeepout"},
+			{Number: 40, Name: "bKeepout"},
+			{Number: 46, Name: "Milling"},
+		},
+		Board: eagle.NewBoard(),
+	}
+	corners := [][2]float64{{0, 0}, {20, 0}, {20, 20}, {0, 20}}
+	for index, c := range corners {
+		next := corners[(index+1)%len(corners)]
+		plain.Wires = append(plain.Wires, eagle.Wire{X1: c[0], Y1: c[1], X2: next[0], Y2: next[1], Layer: 20})
+	}
+	board.Board.Plain = plain
+	return board
+}
+
+// pours returns the copper pour polygons on a layer, as points
+func pours(board *eagle.Eagle, layer int) [][]geometry.Point {
+	polygons := [][]geometry.Point{}
+	for _, p := range board.Board.Plain.Polygons {
+		if p.Layer != layer {
+			continue
+		}
+		points := []geometry.Point{}
+		for _, v := range p.Vertices {
+			points = append(points, geometry.Point{X: v.X, Y: v.Y})
+		}
+		polygons = append(polygons, points)
+	}
+	return polygons
+}
+
+// poured reports whether p is covered by any of the pours
+func poured(pours [][]geometry.Point, p geometry.Point) bool {
+	for _, pour := range pours {
+		if geometry.PointInPolygon(p, pour) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyCopperFill(t *testing.T) {
+	square := func(layer int, x1, y1, x2, y2 float64) eagle.Polygon {
+		return eagle.Polygon{
+			Layer:    layer,
+			Vertices: []eagle.Vertex{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}},
+		}
+	}
+	tests := []struct {
+		name  string
+		plain eagle.Plain
+		// area is the expected area of the top pour, if non-zero
+		area float64
+		// points that should be covered by copper on each side
+		top, bottom []geometry.Point
+		// points that should be clear of copper on each side
+		clearTop, clearBottom []geometry.Point
+	}{
+		{
+			name:        "empty",
+			area:        19 * 19,
+			top:         []geometry.Point{{X: 0.6, Y: 0.6}, {X: 10, Y: 10}},
+			bottom:      []geometry.Point{{X: 19.4, Y: 19.4}},
+			clearTop:    []geometry.Point{{X: 0.4, Y: 10}, {X: 10, Y: 19.6}},
+			clearBottom: []geometry.Point{{X: 19.6, Y: 10}},
+		},
+		{
+			name:        "hole",
+			plain:       eagle.Plain{Holes: []eagle.Hole{{X: 10, Y: 10, Drill: 3}}},
+			area:        19*19 - math.Pi*2*2,
+			top:         []geometry.Point{{X: 10, Y: 12.1}, {X: 5, Y: 10}},
+			bottom:      []geometry.Point{{X: 7.9, Y: 10}},
+			clearTop:    []geometry.Point{{X: 10, Y: 10}, {X: 10, Y: 11.9}},
+			clearBottom: []geometry.Point{{X: 10, Y: 10}, {X: 8.1, Y: 10}},
+		},
+		{
+			name:  "milling cutout",
+			plain: eagle.Plain{Polygons: []eagle.Polygon{square(46, 5, 5, 9, 9)}},
+			// the cutout grows by the pullback, with rounded corners
+			area:        19*19 - (4*4 + 4*4*0.5 + math.Pi*0.5*0.5),
+			top:         []geometry.Point{{X: 4.4, Y: 7}},
+			bottom:      []geometry.Point{{X: 7, Y: 9.6}},
+			clearTop:    []geometry.Point{{X: 7, Y: 7}, {X: 4.6, Y: 7}},
+			clearBottom: []geometry.Point{{X: 7, Y: 7}, {X: 9.3, Y: 9.3}},
+		},
+		{
+			name:     "stop ring on top only",
+			plain:    eagle.Plain{Circles: []eagle.Circle{{X: 10, Y: 10, Radius: 2, Width: 1, Layer: 29}}},
+			area:     19*19 - math.Pi*3*3,
+			top:      []geometry.Point{{X: 13.1, Y: 10}},
+			bottom:   []geometry.Point{{X: 10, Y: 10}, {X: 12, Y: 10}},
+			clearTop: []geometry.Point{{X: 10, Y: 10}, {X: 12.9, Y: 10}},
+		},
+		{
+			name:        "keepout polygon on bottom only",
+			plain:       eagle.Plain{Polygons: []eagle.Polygon{square(40, 12, 12, 16, 16)}},
+			area:        19 * 19,
+			top:         []geometry.Point{{X: 14, Y: 14}},
+			bottom:      []geometry.Point{{X: 11.4, Y: 14}},
+			clearBottom: []geometry.Point{{X: 14, Y: 14}, {X: 11.6, Y: 14}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := testBoard(test.plain)
+			if err := ApplyCopperFill(board, CopperPullback); err != nil {
+				t.Fatal(err)
+			}
+			top, bottom := pours(board, 1), pours(board, 16)
+			if len(top) == 0 || len(bottom) == 0 {
+				t.Fatalf("got %d top and %d bottom pours, want at least one of each", len(top), len(bottom))
+			}
+			if test.area > 0 {
+				area := 0.0
+				for _, pour := range top {
+					area += math.Abs(geometry.Area(pour))
+				}
+				// arcs are flattened to chords, so holes come out a
+				// little smaller than true circles
+				if math.Abs(area-test.area) > 0.25 {
+					t.Errorf("top pour area is %v, want %v", area, test.area)
+				}
+			}
+			for _, p := range test.top {
+				if !poured(top, p) {
+					t.Errorf("top pour doesn't cover %v", p)
+				}
+			}
+			for _, p := range test.bottom {
+				if !poured(bottom, p) {
+					t.Errorf("bottom pour doesn't cover %v", p)
+				}
+			}
+			for _, p := range test.clearTop {
+				if poured(top, p) {
+					t.Errorf("top pour covers %v", p)
+				}
+			}
+			for _, p := range test.clearBottom {
+				if poured(bottom, p) {
+					t.Errorf("bottom pour covers %v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestSideClearance(t *testing.T) {
+	board := testBoard(eagle.Plain{
+		Circles: []eagle.Circle{
+			// filled tStop circle, and a bKeepout ring
+			{X: 5, Y: 5, Radius: 1, Width: 0, Layer: 29},
+			{X: 15, Y: 5, Radius: 2, Width: 0.5, Layer: 40},
+			// not a clearance layer
+			{X: 10, Y: 10, Radius: 1, Width: 0, Layer: 20},
+		},
+		Polygons: []eagle.Polygon{
+			{Layer: 39, Width: 1, Vertices: []eagle.Vertex{{X: 5, Y: 14}, {X: 8, Y: 14}, {X: 8, Y: 17}}},
+			// too few vertices to be a polygon
+			{Layer: 39, Vertices: []eagle.Vertex{{X: 12, Y: 12}, {X: 14, Y: 14}}},
+		},
+	})
+	tests := []struct {
+		side  copperSide
+		clear []geometry.Point
+		keep  []geometry.Point
+	}{
+		{
+			side: copperSides[0],
+			// circle radius 1 plus 0.5 pullback, and the polygon grown by
+			// half its width plus the pullback
+			clear: []geometry.Point{{X: 5, Y: 5}, {X: 6.4, Y: 5}, {X: 8.9, Y: 15}},
+			keep:  []geometry.Point{{X: 6.6, Y: 5}, {X: 15, Y: 5}, {X: 10, Y: 10}, {X: 13, Y: 13}, {X: 9.1, Y: 15}},
+		},
+		{
+			side: copperSides[1],
+			// radius 2, plus half of the 0.5 width, plus the pullback
+			clear: []geometry.Point{{X: 15, Y: 5}, {X: 17.7, Y: 5}},
+			keep:  []geometry.Point{{X: 5, Y: 5}, {X: 17.8, Y: 5}, {X: 7, Y: 15}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.side.copper, func(t *testing.T) {
+			region := sideClearance(board, test.side, CopperPullback)
+			for _, p := range test.clear {
+				if !region.Contains(p) {
+					t.Errorf("clearance doesn't cover %v", p)
+				}
+			}
+			for _, p := range test.keep {
+				if region.Contains(p) {
+					t.Errorf("clearance covers %v", p)
+				}
+			}
+		})
+	}
+}
@@ -21,33 +21,68 @@
 package standard
 
 import (
+	"fmt"
+
 	"github.com/jsleeio/go-eagle/internal/boardops"
 	"github.com/jsleeio/go-eagle/internal/boardops/util"
 	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
 	"github.com/jsleeio/go-eagle/pkg/panel"
 )
 
 const (
-	// CopperPullback indicates how far a copper pour will be "pulled
-	// back" from the edge of a board. This shouldn't really need to
-	// be configurable, so keep it simple and use a constant
+	// CopperPullback is the default distance a copper pour is "pulled back"
+	// from the edge of a board, and from holes, cutouts and keepouts
 	CopperPullback = 0.5
+
+	// OutlineTolerance is the maximum deviation from true arcs when copper
+	// pours follow curved outlines, holes and cutouts
+	OutlineTolerance = 0.01
 )
 
 // ApplyStandardBoardOperations applies the minimal set of baseline
-// board features to an Eagle board: an outline, some mounting holes
-// and copper pours in the Top and Bottom copper layers.
+// board features to an Eagle board: an outline, some mounting holes,
+// keepouts, cutouts and copper pours in the Top and Bottom copper layers.
+// Callers adding features of their own should use ApplyPanelFeatures and
+// then ApplyCopperFill instead, so that the pours avoid those features too.
 func ApplyStandardBoardOperations(board *eagle.Eagle, spec panel.Panel) error {
+	if err := ApplyPanelFeatures(board, spec); err != nil {
+		return err
+	}
+	return ApplyCopperFill(board, CopperPullback)
+}
+
+// ApplyPanelFeatures applies the baseline board features other than copper
+// pours: an outline, some mounting holes, keepouts and cutouts
+func ApplyPanelFeatures(board *eagle.Eagle, spec panel.Panel) error {
 	ops := []boardops.BoardOperation{
 		outlineWiresOp,
 		mountingHolesOp,
-		copperFillOp,
 		railKeepoutsOp,
+		keepoutsOp,
+		cutoutsOp,
 	}
 	return boardops.ApplyBoardOperations(board, spec, ops)
 }
 
+// shapedOutline returns the outline of a non-rectangular panel, or nil
+func shapedOutline(spec panel.Panel) []geometry.Vertex {
+	if so, ok := spec.(panel.ShapedOutline); ok {
+		return so.Outline()
+	}
+	return nil
+}
+
 func outlineWiresOp(board *eagle.Eagle, spec panel.Panel) error {
+	if outline := shapedOutline(spec); len(outline) > 0 {
+		board.Board.Plain.Wires = append(board.Board.Plain.Wires, util.WireOutline(outline, board.LayerByName("Dimension"), 0)...)
+		return nil
+	}
+	if sc, ok := spec.(panel.ShapedCorners); ok {
+		outline := panel.RectangleOutline(panel.LeftX(spec), panel.BottomY(spec), panel.RightX(spec), panel.TopY(spec), sc.Corners())
+		board.Board.Plain.Wires = append(board.Board.Plain.Wires, util.WireOutline(outline, board.LayerByName("Dimension"), 0)...)
+		return nil
+	}
 	adjust := spec.HorizontalFit() / 2 // half on left edge, half on right edge
 	outline := util.WireRectangle(
 		0+adjust,
@@ -65,6 +100,9 @@ func outlineWiresOp(board *eagle.Eagle, spec panel.Panel) error {
 }
 
 func mountingHolesOp(board *eagle.Eagle, spec panel.Panel) error {
+	if smh, ok := spec.(panel.SlottedMountingHoles); ok && smh.MountingSlotLength() > 0 {
+		return mountingSlotsOp(board, spec, smh.MountingSlotLength())
+	}
 	for _, hole := range spec.MountingHoles() {
 		board.Board.Plain.Holes = append(board.Board.Plain.Holes, eagle.Hole{
 			X:     hole.X,
@@ -75,74 +113,73 @@ func mountingHolesOp(board *eagle.Eagle, spec panel.Panel) error {
 	return nil
 }
 
+// mountingSlotsOp mills oval mounting slots instead of drilling round
+// mounting holes. Slots can't be drilled, so they're drawn on the outline
+// layer like any other cutout.
+func mountingSlotsOp(board *eagle.Eagle, spec panel.Panel, length float64) error {
+	diameter := spec.MountingHoleDiameter()
+	if length < diameter {
+		return fmt.Errorf("mounting slot length %vmm is less than the mounting hole diameter %vmm", length, diameter)
+	}
+	for _, hole := range spec.MountingHoles() {
+		outline := geometry.RoundedRectangle(hole.X, hole.Y, length, diameter, diameter/2)
+		board.Board.Plain.Wires = append(board.Board.Plain.Wires, util.WireOutline(outline, board.LayerByName("Dimension"), 0)...)
+	}
+	return nil
+}
+
 func railKeepoutsOp(board *eagle.Eagle, spec panel.Panel) error {
-	// format may not have rails.
-	// FIXME: find a better way to do this now that custom formats are
-	//        supported. Maybe add a new operation that creates keepouts
-	//        around panel holes to account for mounting hole posts in
-	//        typical off-the-shelf enclosures?
-	if railheight := spec.RailHeightFromMountingHole(); railheight > 0 {
-		layer := board.LayerByName("tKeepout")
-		bRail := eagle.Rectangle{
+	// format may not have rails
+	rails, ok := spec.(panel.Rails)
+	if !ok {
+		return nil
+	}
+	layer := board.LayerByName("tKeepout")
+	for _, band := range panel.RailBands(rails) {
+		board.Board.Plain.Rectangles = append(board.Board.Plain.Rectangles, eagle.Rectangle{
 			X1:    panel.LeftX(spec),
-			Y1:    spec.MountingHoleBottomY(),
+			Y1:    band.Bottom,
 			X2:    panel.RightX(spec),
-			Y2:    spec.MountingHoleBottomY() + spec.RailHeightFromMountingHole(),
+			Y2:    band.Top,
 			Layer: layer,
+		})
+	}
+	return nil
+}
+
+func keepoutsOp(board *eagle.Eagle, spec panel.Panel) error {
+	// format may not define any keepouts
+	kr, ok := spec.(panel.KeepoutRegions)
+	if !ok {
+		return nil
+	}
+	for _, keepout := range kr.Keepouts() {
+		layers := []string{}
+		if keepout.Top() {
+			layers = append(layers, "tKeepout")
 		}
-		tRail := eagle.Rectangle{
-			X1:    panel.LeftX(spec),
-			Y1:    spec.MountingHoleTopY() - spec.RailHeightFromMountingHole(),
-			X2:    panel.RightX(spec),
-			Y2:    spec.MountingHoleTopY(),
-			Layer: layer,
+		if keepout.Bottom() {
+			layers = append(layers, "bKeepout")
+		}
+		for _, layer := range layers {
+			circles, polygons := util.KeepoutFeatures(keepout, board.LayerByName(layer))
+			board.Board.Plain.Circles = append(board.Board.Plain.Circles, circles...)
+			board.Board.Plain.Polygons = append(board.Board.Plain.Polygons, polygons...)
 		}
-		board.Board.Plain.Rectangles = append(board.Board.Plain.Rectangles, bRail)
-		board.Board.Plain.Rectangles = append(board.Board.Plain.Rectangles, tRail)
 	}
 	return nil
 }
 
-func copperFillOp(board *eagle.Eagle, spec panel.Panel) error {
-	// normally the horizontal fit value shouldn't be used anywhere except the
-	// panel outline, but if we don't include it here, the pullback will be
-	// wrong, or possibly even completely ineffective. So, include it.
-	adjust := spec.HorizontalFit() / 2
-	x1 := CopperPullback + adjust
-	y1 := CopperPullback
-	x2 := spec.Width() - (CopperPullback + adjust)
-	y2 := spec.Height() - CopperPullback
-	r := spec.CornerRadius()
-	vertices := []eagle.Vertex{}
-	if r < 0.01 { // effectively zero {
-		vertices = append(vertices,
-			eagle.Vertex{X: x1, Y: y1}, // bottom left,
-			eagle.Vertex{X: x1, Y: y2}, // top left,
-			eagle.Vertex{X: x2, Y: y2}, // top right,
-			eagle.Vertex{X: x2, Y: y1}) // bottom right
-	} else {
-		vertices = append(vertices,
-			eagle.Vertex{X: x1 + r, Y: y1, Curve: -90.0}, // bottom left corner radius start
-			eagle.Vertex{X: x1, Y: y1 + r},               // bottom left corner radius end
-			eagle.Vertex{X: x1, Y: y2 - r, Curve: -90.0}, // left edge edge end
-			eagle.Vertex{X: x1 + r, Y: y2},               // top left corner radius end
-			eagle.Vertex{X: x2 - r, Y: y2, Curve: -90.0}, // top edge end
-			eagle.Vertex{X: x2, Y: y2 - r},               // top right corner radius end
-			eagle.Vertex{X: x2, Y: y1 + r, Curve: -90.0}, // right edge end
-			eagle.Vertex{X: x2 - r, Y: y1},               // bottom right corner radius end
-			eagle.Vertex{X: x1 + r, Y: y1})               // bottom edge end
-	}
-	top := eagle.Polygon{
-		Vertices: []eagle.Vertex{},
-		Layer:    board.LayerByName("Top"),
-	}
-	top.Vertices = append(top.Vertices, vertices...) // copy to avoid later pass-by-reference traps
-	bottom := eagle.Polygon{
-		Vertices: []eagle.Vertex{},
-		Layer:    board.LayerByName("Bottom"),
-	}
-	bottom.Vertices = append(bottom.Vertices, vertices...) // copy to avoid later pass-by-reference traps
-	board.Board.Plain.Polygons = append(board.Board.Plain.Polygons, top)
-	board.Board.Plain.Polygons = append(board.Board.Plain.Polygons, bottom)
+// cutoutsOp mills any openings the format declares, on the outline layer
+func cutoutsOp(board *eagle.Eagle, spec panel.Panel) error {
+	// format may not define any cutouts
+	cr, ok := spec.(panel.CutoutRegions)
+	if !ok {
+		return nil
+	}
+	layer := board.LayerByName("Dimension")
+	for _, cutout := range cr.Cutouts() {
+		board.Board.Plain.Wires = append(board.Board.Plain.Wires, util.WireOutline(cutout.Outline(), layer, 0)...)
+	}
 	return nil
 }
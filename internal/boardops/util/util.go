@@ -22,6 +22,8 @@ package util
 
 import (
 	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/panel"
 )
 
 // WireRectangle generates a rectangle
@@ -41,3 +43,35 @@ func WireRectangle(x1, y1, x2, y2 float64, layer int, width float64, radius floa
 	}
 	return segments
 }
+
+// KeepoutFeatures generates the Eagle features for a keepout area on a
+// single layer: a filled circle for circular keepouts, or a polygon for
+// everything else
+func KeepoutFeatures(k panel.Keepout, layer int) ([]eagle.Circle, []eagle.Polygon) {
+	if k.Shape == panel.KeepoutCircle {
+		// zero-width circles are drawn filled
+		return []eagle.Circle{{X: k.X, Y: k.Y, Radius: k.Radius, Layer: layer}}, nil
+	}
+	polygon := eagle.Polygon{Vertices: []eagle.Vertex{}, Layer: layer}
+	for _, v := range k.Outline() {
+		polygon.Vertices = append(polygon.Vertices, eagle.Vertex{X: v.X, Y: v.Y, Curve: v.Curve})
+	}
+	return nil, []eagle.Polygon{polygon}
+}
+
+// WireOutline generates wires tracing a closed outline, including any
+// curved edges
+func WireOutline(vertices []geometry.Vertex, layer int, width float64) []eagle.Wire {
+	wires := []eagle.Wire{}
+	for index, v := range vertices {
+		next := vertices[(index+1)%len(vertices)]
+		wires = append(wires, eagle.Wire{
+			X1: v.X, Y1: v.Y,
+			X2: next.X, Y2: next.Y,
+			Curve: v.Curve,
+			Layer: layer,
+			Width: width,
+		})
+	}
+	return wires
+}
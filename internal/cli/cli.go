@@ -0,0 +1,89 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package cli holds the commandline handling shared by the commands that
+// create or check panels
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+const (
+	// mountingSlotLengthName is the name of the flag that overrides a
+	// format's mounting slot length
+	mountingSlotLengthName = "mounting-slot-length"
+)
+
+// FormatFlag defines the -format flag, listing every registered panel
+// format in its help text
+func FormatFlag(usage string) *string {
+	formatList := "(" + strings.Join(panel.FormatNames(), ",") + ")"
+	return flag.String("format", panel.DefaultFormat, usage+" "+formatList)
+}
+
+// MountingSlotLengthFlag defines the -mounting-slot-length flag. Use
+// MountingSlotLength to read it once the flags are parsed.
+func MountingSlotLengthFlag() *float64 {
+	return flag.Float64(mountingSlotLengthName, 0, "overall length of oval mounting slots to use instead of round mounting holes, or 0 for round holes (default: the format's own)")
+}
+
+// MountingSlotLength returns the value of the -mounting-slot-length flag,
+// suitable for panel.Options, or nil if the flag wasn't given. Zero asks for
+// round holes, so it is the flag being given that matters, not its value.
+func MountingSlotLength(length *float64) *float64 {
+	if !FlagWasSet(mountingSlotLengthName) {
+		return nil
+	}
+	return length
+}
+
+// FlagWasSet reports whether a flag was given on the commandline, for flags
+// whose zero value is meaningful
+func FlagWasSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// Usage returns a flag.Usage function that follows the usual list of flags
+// with a description of every registered panel format. args describes any
+// arguments expected after the flags.
+func Usage(args string) func() {
+	return func() {
+		out := flag.CommandLine.Output()
+		if args != "" {
+			fmt.Fprintf(out, "Usage of %s: %s\n", os.Args[0], args)
+		} else {
+			fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		}
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nPanel formats:\n%s", panel.FormatHelp())
+	}
+}
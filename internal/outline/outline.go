@@ -21,23 +21,17 @@
 package outline
 
 import (
-	"math"
+	"fmt"
 
 	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
 )
 
-// FindBoardOutlineWires searches through Wires in the Plain section of
-// the board for zero-width wires in the Dimension layer
-func FindBoardOutlineWires(e *eagle.Eagle) []eagle.Wire {
-	wires := []eagle.Wire{}
-	dimension := e.LayerByName("Dimension")
-	for _, wire := range e.Board.Plain.Wires {
-		if wire.Layer == dimension && wire.Width == 0.0 {
-			wires = append(wires, wire)
-		}
-	}
-	return wires
-}
+const (
+	// JoinTolerance is how close, in millimetres, the ends of two Dimension
+	// layer lines must be for them to be considered joined
+	JoinTolerance = 1e-3
+)
 
 // BoardCoords holds information about a board outline and its place in
 // the coordinate space. This is used to determine panel width and to
@@ -45,7 +39,6 @@ func FindBoardOutlineWires(e *eagle.Eagle) []eagle.Wire {
 type BoardCoords struct {
 	XMin, XMax, YMin, YMax float64
 	XOffset, YOffset       float64
-	HP                     int
 }
 
 // Width returns the width of the board outline
@@ -58,31 +51,140 @@ func (bc BoardCoords) Height() float64 {
 	return bc.YMax - bc.YMin
 }
 
-// DeriveBoardCoords creates a BoardCoords object from the discovered outline
-// wires in the Plain section of a board
-func DeriveBoardCoords(e *eagle.Eagle) BoardCoords {
-	bc := BoardCoords{}
-	for _, wire := range FindBoardOutlineWires(e) {
-		txmin, txmax := fsort2(wire.X1, wire.X2)
-		tymin, tymax := fsort2(wire.Y1, wire.Y2)
-		bc.XMin = math.Min(bc.XMin, txmin)
-		bc.XMax = math.Max(bc.XMax, txmax)
-		bc.YMin = math.Min(bc.YMin, tymin)
-		bc.YMax = math.Max(bc.YMax, tymax)
+// DimensionEdges returns the centrelines of everything drawn in the
+// Dimension layer, which together make up the board outline and any internal
+// cutouts
+func DimensionEdges(e *eagle.Eagle) ([]geometry.Edge, error) {
+	return LayerEdges(e, "Dimension")
+}
+
+// LayerEdges returns the centrelines of everything drawn in a layer: wires,
+// circles, polygons and rectangles in the Plain section, and those
+// contributed by the packages of placed elements
+func LayerEdges(e *eagle.Eagle, layer string) ([]geometry.Edge, error) {
+	number := e.LayerByName(layer)
+	edges, err := layerEdges(number, e.Board.Plain.Wires, e.Board.Plain.Circles, e.Board.Plain.Polygons, e.Board.Plain.Rectangles, geometry.Identity())
+	if err != nil {
+		return nil, err
+	}
+	for _, elem := range e.Board.Elements {
+		pkg, found := e.Board.PackageForElement(elem)
+		if !found {
+			continue
+		}
+		rot, err := eagle.ParseRotation(elem.Rotate)
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %v", elem.Name, err)
+		}
+		placement := geometry.Placement(elem.X, elem.Y, rot)
+		pe, err := layerEdges(number, pkg.Wires, pkg.Circles, pkg.Polygons, pkg.Rectangles, placement)
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %v", elem.Name, err)
+		}
+		edges = append(edges, pe...)
 	}
-	if bc.XMin != 0 {
-		bc.XOffset = -bc.XMin
+	return edges, nil
+}
+
+// layerEdges returns the centrelines of those primitives in a given layer,
+// transformed into board coordinates
+func layerEdges(layer int, wires []eagle.Wire, circles []eagle.Circle, polygons []eagle.Polygon, rectangles []eagle.Rectangle, t geometry.Transform) ([]geometry.Edge, error) {
+	edges := []geometry.Edge{}
+	for _, w := range wires {
+		if w.Layer == layer {
+			edges = append(edges, geometry.WireEdge(t.Wire(w)))
+		}
 	}
-	if bc.YMin != 0 {
-		bc.YOffset = -bc.YMin
+	for _, c := range circles {
+		if c.Layer == layer {
+			c = t.Circle(c)
+			edges = append(edges, geometry.CircleEdges(c.X, c.Y, c.Radius)...)
+		}
+	}
+	for _, p := range polygons {
+		if p.Layer == layer {
+			edges = append(edges, geometry.PolygonEdges(t.Polygon(p))...)
+		}
 	}
-	bc.HP = int(math.Ceil(math.Ceil(bc.XMax-bc.XMin) / 5.08))
-	return bc
+	for _, r := range rectangles {
+		if r.Layer != layer {
+			continue
+		}
+		r, err := t.Rectangle(r)
+		if err != nil {
+			return nil, err
+		}
+		re, err := geometry.RectangleEdges(r)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, re...)
+	}
+	return edges, nil
+}
+
+// Outline is a board outline reconstructed from the Dimension layer
+type Outline struct {
+	// Outer is the outside of the board, running counter-clockwise
+	Outer []geometry.Vertex
+	// Cutouts are closed loops inside the board, running counter-clockwise
+	Cutouts [][]geometry.Vertex
 }
 
-func fsort2(a, b float64) (float64, float64) {
-	if a > b {
-		return b, a
+// FindBoardOutline links the Dimension layer edges of a board into closed
+// loops, and separates the outside of the board from its internal cutouts
+func FindBoardOutline(e *eagle.Eagle) (Outline, error) {
+	edges, err := DimensionEdges(e)
+	if err != nil {
+		return Outline{}, err
+	}
+	loops, err := geometry.JoinLoops(edges, JoinTolerance)
+	if err != nil {
+		return Outline{}, err
+	}
+	if len(loops) == 0 {
+		return Outline{}, fmt.Errorf("no board outline found in Dimension layer")
+	}
+	outer, cutouts := geometry.SplitLoops(loops)
+	return Outline{Outer: outer, Cutouts: cutouts}, nil
+}
+
+// Cutouts returns every closed loop cut out of a board: the cutouts inside
+// its Dimension layer outline, plus anything drawn in the Milling layer, if
+// the board has one
+func Cutouts(e *eagle.Eagle, o Outline) ([][]geometry.Vertex, error) {
+	cutouts := append([][]geometry.Vertex{}, o.Cutouts...)
+	if _, ok := e.LayerNumber("Milling"); !ok {
+		return cutouts, nil
+	}
+	edges, err := LayerEdges(e, "Milling")
+	if err != nil {
+		return nil, err
+	}
+	loops, err := geometry.JoinLoops(edges, JoinTolerance)
+	if err != nil {
+		return nil, fmt.Errorf("Milling layer: %v", err)
+	}
+	return append(cutouts, loops...), nil
+}
+
+// DeriveBoardCoords creates a BoardCoords object from the true extents of
+// everything in the Dimension layer, including arcs, circles, polygons and
+// package-contributed outlines. Boards with nothing in the Dimension layer
+// get an empty BoardCoords.
+func DeriveBoardCoords(e *eagle.Eagle) (BoardCoords, error) {
+	bc := BoardCoords{}
+	edges, err := DimensionEdges(e)
+	if err != nil {
+		return bc, err
+	}
+	ext := geometry.EdgesExtents(edges)
+	if ext.Empty() {
+		return bc, nil
 	}
-	return a, b
+	bc.XMin, bc.XMax = ext.XMin, ext.XMax
+	bc.YMin, bc.YMax = ext.YMin, ext.YMax
+	bc.XOffset = -bc.XMin
+	bc.YOffset = -bc.YMin
+	return bc, nil
 }
@@ -0,0 +1,230 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package outline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+func loadTestBoard(t *testing.T, name string) *eagle.Eagle {
+	t.Helper()
+	e, err := eagle.LoadEagleFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestDeriveBoardCoords(t *testing.T) {
+	tests := []struct {
+		file string
+		want BoardCoords
+	}{
+		{
+			file: "positive.brd",
+			want: BoardCoords{XMin: 20, YMin: 30, XMax: 70, YMax: 158.5, XOffset: -20, YOffset: -30},
+		},
+		{
+			file: "arc.brd",
+			want: BoardCoords{XMin: 10, YMin: 10, XMax: 50, YMax: 60, XOffset: -10, YOffset: -10},
+		},
+		{
+			file: "shapes.brd",
+			want: BoardCoords{XMin: 5, YMin: 5, XMax: 80, YMax: 70, XOffset: -5, YOffset: -5},
+		},
+		{
+			file: "package.brd",
+			want: BoardCoords{XMin: 95, YMin: 40, XMax: 105, YMax: 60, XOffset: -95, YOffset: -40},
+		},
+		{
+			file: "cutouts.brd",
+			want: BoardCoords{XMin: 10, YMin: 10, XMax: 110, YMax: 90, XOffset: -10, YOffset: -10},
+		},
+		{
+			file: "empty.brd",
+			want: BoardCoords{},
+		},
+		// real boards. Eagle measures outlines along the centreline of the
+		// Dimension layer wires, whatever their width, and so should we.
+		{
+			file: "../../../data/ref.brd",
+			want: BoardCoords{XMin: 0, YMin: 0, XMax: 20.32, YMax: 128.5, XOffset: 0, YOffset: 0},
+		},
+		{
+			file: "../../../pkg/eagle/testdata/panel.brd",
+			want: BoardCoords{XMin: 0, YMin: 0, XMax: 30.1, YMax: 128.5, XOffset: 0, YOffset: 0},
+		},
+	}
+	for _, test := range tests {
+		got, err := DeriveBoardCoords(loadTestBoard(t, test.file))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		same := true
+		for _, pair := range [][2]float64{
+			{got.XMin, test.want.XMin}, {got.YMin, test.want.YMin},
+			{got.XMax, test.want.XMax}, {got.YMax, test.want.YMax},
+			{got.XOffset, test.want.XOffset}, {got.YOffset, test.want.YOffset},
+		} {
+			same = same && math.Abs(pair[0]-pair[1]) < 1e-9
+		}
+		if !same {
+			t.Errorf("%s: got %+v, want %+v", test.file, got, test.want)
+		}
+	}
+}
+
+// loopExtents returns the true bounds of a loop, including any arcs
+func loopExtents(loop []geometry.Vertex) geometry.Extents {
+	return geometry.EdgesExtents(geometry.LoopEdges(loop))
+}
+
+func TestFindBoardOutline(t *testing.T) {
+	tests := []struct {
+		file    string
+		outer   geometry.Extents
+		cutouts []geometry.Extents
+	}{
+		{
+			file:  "arc.brd",
+			outer: geometry.Extents{XMin: 10, YMin: 10, XMax: 50, YMax: 60},
+		},
+		{
+			file:  "package.brd",
+			outer: geometry.Extents{XMin: 95, YMin: 40, XMax: 105, YMax: 60},
+		},
+		{
+			file:  "cutouts.brd",
+			outer: geometry.Extents{XMin: 10, YMin: 10, XMax: 110, YMax: 90},
+			cutouts: []geometry.Extents{
+				{XMin: 30, YMin: 40, XMax: 50, YMax: 60},
+				{XMin: 70, YMin: 40, XMax: 90, YMax: 60},
+			},
+		},
+		{
+			file:  "../../../data/ref.brd",
+			outer: geometry.Extents{XMin: 0, YMin: 0, XMax: 20.32, YMax: 128.5},
+		},
+		{
+			// the round cutout in this panel is on the Milling layer, so it
+			// isn't part of the outline
+			file:  "../../../pkg/eagle/testdata/panel.brd",
+			outer: geometry.Extents{XMin: 0, YMin: 0, XMax: 30.1, YMax: 128.5},
+		},
+	}
+	same := func(a, b geometry.Extents) bool {
+		return math.Abs(a.XMin-b.XMin) < 1e-9 && math.Abs(a.YMin-b.YMin) < 1e-9 &&
+			math.Abs(a.XMax-b.XMax) < 1e-9 && math.Abs(a.YMax-b.YMax) < 1e-9
+	}
+	for _, test := range tests {
+		bo, err := FindBoardOutline(loadTestBoard(t, test.file))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if got := loopExtents(bo.Outer); !same(got, test.outer) {
+			t.Errorf("%s: outer loop covers %+v, want %+v", test.file, got, test.outer)
+		}
+		if area := geometry.LoopArea(bo.Outer); area <= 0 {
+			t.Errorf("%s: outer loop should run counter-clockwise, has area %v", test.file, area)
+		}
+		if len(bo.Cutouts) != len(test.cutouts) {
+			t.Errorf("%s: got %d cutouts, want %d", test.file, len(bo.Cutouts), len(test.cutouts))
+			continue
+		}
+		for index, cutout := range bo.Cutouts {
+			if got := loopExtents(cutout); !same(got, test.cutouts[index]) {
+				t.Errorf("%s: cutout %d covers %+v, want %+v", test.file, index, got, test.cutouts[index])
+			}
+			if area := geometry.LoopArea(cutout); area <= 0 {
+				t.Errorf("%s: cutout %d should run counter-clockwise, has area %v", test.file, index, area)
+			}
+		}
+	}
+	if _, err := FindBoardOutline(loadTestBoard(t, "empty.brd")); err == nil {
+		t.Errorf("empty.brd: expected an error for a board with no outline")
+	}
+}
+
+func TestCutoutsRealBoard(t *testing.T) {
+	e := loadTestBoard(t, "../../../pkg/eagle/testdata/panel.brd")
+	bo, err := FindBoardOutline(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cutouts, err := Cutouts(e, bo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a 6mm circle milled in two halves
+	want := geometry.Extents{XMin: 12.05, YMin: 57, XMax: 18.05, YMax: 63}
+	if len(cutouts) != 1 {
+		t.Fatalf("got %d cutouts, want 1", len(cutouts))
+	}
+	got := loopExtents(cutouts[0])
+	if math.Abs(got.XMin-want.XMin) > 1e-9 || math.Abs(got.YMin-want.YMin) > 1e-9 ||
+		math.Abs(got.XMax-want.XMax) > 1e-9 || math.Abs(got.YMax-want.YMax) > 1e-9 {
+		t.Errorf("milled cutout covers %+v, want %+v", got, want)
+	}
+}
+
+func TestCutouts(t *testing.T) {
+	e := loadTestBoard(t, "cutouts.brd")
+	bo, err := FindBoardOutline(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cutouts, err := Cutouts(e, bo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cutouts) != 2 {
+		t.Errorf("got %d cutouts with nothing milled, want 2", len(cutouts))
+	}
+	// a milled slot joins the Dimension layer cutouts
+	e.Board.Plain.Polygons = append(e.Board.Plain.Polygons, eagle.Polygon{
+		Layer:    46,
+		Vertices: []eagle.Vertex{{X: 20, Y: 70}, {X: 60, Y: 70}, {X: 60, Y: 75}, {X: 20, Y: 75}},
+	})
+	if cutouts, err = Cutouts(e, bo); err != nil {
+		t.Fatal(err)
+	}
+	if len(cutouts) != 3 {
+		t.Fatalf("got %d cutouts, want 3", len(cutouts))
+	}
+	want := geometry.Extents{XMin: 20, YMin: 70, XMax: 60, YMax: 75}
+	if got := loopExtents(cutouts[2]); got != want {
+		t.Errorf("milled cutout covers %+v, want %+v", got, want)
+	}
+	if len(bo.Cutouts) != 2 {
+		t.Errorf("Cutouts changed the outline's own cutouts")
+	}
+	// an unclosed milling path can't be a cutout
+	e.Board.Plain.Wires = append(e.Board.Plain.Wires, eagle.Wire{X1: 20, Y1: 20, X2: 30, Y2: 20, Layer: 46})
+	if _, err := Cutouts(e, bo); err == nil {
+		t.Errorf("expected an error for an unclosed Milling layer path")
+	}
+}
@@ -24,33 +24,26 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jsleeio/go-eagle/pkg/eagle"
-	"github.com/jsleeio/go-eagle/pkg/format/eurorack"
-	"github.com/jsleeio/go-eagle/pkg/format/intellijel"
-	"github.com/jsleeio/go-eagle/pkg/format/pulplogic"
-	filespec "github.com/jsleeio/go-eagle/pkg/format/spec"
+	_ "github.com/jsleeio/go-eagle/pkg/format/all"
 	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/gerber"
+	"github.com/jsleeio/go-eagle/pkg/hardware"
 	"github.com/jsleeio/go-eagle/pkg/panel"
+	"github.com/jsleeio/go-eagle/pkg/svg"
 
 	"github.com/jsleeio/go-eagle/internal/boardops/standard"
+	"github.com/jsleeio/go-eagle/internal/boardops/util"
+	"github.com/jsleeio/go-eagle/internal/cli"
 	"github.com/jsleeio/go-eagle/internal/outline"
 )
 
-const (
-	// FormatEurorack is the Doepfer-defined 3U specification. Not Eurocard!
-	FormatEurorack = "eurorack"
-	// FormatPulplogic is the PulpLogic-defined 1U specification
-	FormatPulplogic = "pulplogic"
-	// FormatIntellijel is the Intellijel-defined 1U specification
-	FormatIntellijel = "intellijel"
-	// FormatSpec is the YAML-derived panel specification
-	FormatSpec = "spec"
-)
-
 // wrap up all of the context required for creating panel features
 // into one place to simplify and reduce error
 type panelLayoutContext struct {
@@ -67,52 +60,61 @@ type panelLayoutContext struct {
 	legendLayer  string
 	headerLayer  string
 	footerLayer  string
+	cutoutLayer  string
+	// boardToPanel maps source board coordinates to panel coordinates, so
+	// that the board is centred on the panel
+	boardToPanel geometry.Transform
 }
 
-func (plc *panelLayoutContext) panelSpecForFormat() (err error) {
-	err = nil
-	switch *plc.cfg.Format {
-	case FormatEurorack:
-		plc.spec = eurorack.NewEurorack(plc.bc.HP)
-	case FormatPulplogic:
-		plc.spec = pulplogic.NewPulplogic(plc.bc.HP)
-	case FormatIntellijel:
-		plc.spec = intellijel.NewIntellijel(plc.bc.HP)
-	case FormatSpec:
-		plc.spec, err = filespec.LoadSpec(*plc.cfg.SpecFile)
-		if err != nil {
-			err = fmt.Errorf("error loading YAML panel spec from '%v': %v", *plc.cfg.SpecFile, err)
-		}
-	default:
-		err = fmt.Errorf("unsupported format: %s", *plc.cfg.Format)
+func (plc *panelLayoutContext) panelSpecForFormat() error {
+	format, ok := panel.Lookup(*plc.cfg.Format)
+	if !ok {
+		return fmt.Errorf("unsupported format: %s", *plc.cfg.Format)
 	}
-	return
+	opts := panel.Options{
+		SpecFile:           *plc.cfg.SpecFile,
+		MountingSlotLength: cli.MountingSlotLength(plc.cfg.MountingSlotLength),
+	}
+	if format.WidthForBoard != nil {
+		opts.Width = format.WidthForBoard(plc.bc.Width(), plc.bc.Height())
+	}
+	spec, err := panel.NewPanel(format.Name, opts)
+	if err != nil {
+		return err
+	}
+	plc.spec = spec
+	return nil
 }
 
 func setupPanelLayoutContext(board *eagle.Eagle, c config) (panelLayoutContext, error) {
+	bc, err := outline.DeriveBoardCoords(board)
+	if err != nil {
+		return panelLayoutContext{}, fmt.Errorf("can't determine board outline: %v", err)
+	}
 	plc := panelLayoutContext{
 		cfg:          c,
 		board:        board,
-		bc:           outline.DeriveBoardCoords(board),
+		bc:           bc,
 		legendLayer:  eagle.AttributeString(board.Board, "PANEL_LEGEND_LAYER", "tStop"),
 		headerLayer:  eagle.AttributeString(board.Board, "PANEL_HEADER_LAYER", "tStop"),
 		footerLayer:  eagle.AttributeString(board.Board, "PANEL_FOOTER_LAYER", "tStop"),
+		cutoutLayer:  eagle.AttributeString(board.Board, "PANEL_CUTOUT_LAYER", "Dimension"),
 		legendSkipRe: nil,
 	}
 	if lsre := eagle.AttributeString(board.Board, "PANEL_LEGEND_SKIP_RE", ""); lsre != "" {
 		plc.legendSkipRe = regexp.MustCompile(lsre)
 	}
-	err := plc.panelSpecForFormat()
-	if err != nil {
+	if err := plc.panelSpecForFormat(); err != nil {
 		return panelLayoutContext{}, err
 	}
 	plc.panel = plc.board.CloneEmpty()
-	if err := standard.ApplyStandardBoardOperations(plc.panel, plc.spec); err != nil {
+	if err := standard.ApplyPanelFeatures(plc.panel, plc.spec); err != nil {
 		return panelLayoutContext{}, fmt.Errorf("error creating panel features: %v", err)
 	}
 	// centre the board on the panel
 	plc.bc.XOffset += (plc.spec.Width()-plc.bc.Width())/2 + plc.spec.HorizontalFit()/2
 	plc.bc.YOffset += (plc.spec.Height() - plc.bc.Height()) / 2
+	plc.boardToPanel = geometry.Translate(plc.bc.XOffset, plc.bc.YOffset)
 	return plc, nil
 }
 
@@ -126,11 +128,15 @@ func headerOp(plc panelLayoutContext) {
 		if v, err := eagle.AttributeFloat(plc.board.Board, k, defval); err == nil {
 			offsets[k] = v
 		} else {
-			log.Fatalf("invalid global attribute numeric value: %s: %v", err)
+			log.Fatalf("invalid global attribute numeric value: %s: %v", k, err)
 		}
 	}
-	// add the header and footer
-	headerloc := plc.spec.HeaderLocation()
+	// add the header and footer, if the format has somewhere to put them
+	anchors, ok := plc.spec.(panel.Anchors)
+	if !ok {
+		return
+	}
+	headerloc := anchors.HeaderLocation()
 	header := eagle.Text{
 		X:     headerloc.X + offsets["PANEL_HEADER_OFFSET_X"],
 		Y:     headerloc.Y + offsets["PANEL_HEADER_OFFSET_Y"],
@@ -139,7 +145,7 @@ func headerOp(plc panelLayoutContext) {
 		Text:  eagle.AttributeString(plc.board.Board, "PANEL_HEADER_TEXT", "<HEADER>"),
 		Layer: plc.panel.LayerByName(plc.headerLayer),
 	}
-	footerloc := plc.spec.FooterLocation()
+	footerloc := anchors.FooterLocation()
 	plc.panel.Board.Plain.Texts = append(plc.panel.Board.Plain.Texts, header)
 	footer := eagle.Text{
 		X:     footerloc.X + offsets["PANEL_FOOTER_OFFSET_X"],
@@ -162,6 +168,41 @@ type elementConfig struct {
 	ticksLength, ticksWidth        float64
 	ticksCount                     int
 	ticksLabelsTexts               []string
+	// rotation is the element's placement rotation and mirroring. Offsets
+	// and angles supplied via attributes are relative to the element, so
+	// they need to be transformed by this before use on the panel
+	rotation eagle.Rotation
+}
+
+// offset transforms an element-relative offset into panel coordinates
+func (ec elementConfig) offset(x, y float64) (float64, float64) {
+	return geometry.FromRotation(ec.rotation).Apply(x, y)
+}
+
+// legendPosition returns where a legend goes on the panel, for an element
+// whose hole or cutouts are at (x,y) and reach distance mm towards the
+// legend. Legends sit above or below the element on the panel, whichever
+// way the element is rotated, so the distance is always along panel Y. Only
+// the user-supplied offsets are element-relative and follow its rotation;
+// the Y offset nudges the legend away from the hole, whichever side of the
+// hole it is on.
+func (ec elementConfig) legendPosition(x, y, distance float64) (float64, float64) {
+	dx, dy := ec.offset(ec.legendOffsetX, ec.legendOffsetY*ec.legendLocationFactor)
+	return x + dx, y + dy + distance*ec.legendLocationFactor
+}
+
+// toPanel returns the transform from element-relative coordinates to panel
+// coordinates
+func (ec elementConfig) toPanel(plc panelLayoutContext, elem eagle.Element) geometry.Transform {
+	return geometry.Placement(elem.X, elem.Y, ec.rotation).Then(plc.boardToPanel)
+}
+
+// tickAngle transforms an element-relative tick angle into a panel tick
+// angle. Tick angles are measured clockwise from 9 o'clock, whereas Eagle
+// rotations are counter-clockwise from 3 o'clock, so convert to polar and
+// back again either side of the transform.
+func (ec elementConfig) tickAngle(angle float64) float64 {
+	return 180.0 - ec.rotation.ApplyAngle(180.0-angle)
 }
 
 // extract all the per-element config into a nice structure. Later this should help
@@ -172,6 +213,9 @@ func elementConfigFromElement(elem eagle.Element) (elementConfig, error) {
 		legend:           eagle.AttributeString(elem, "PANEL_LEGEND", elem.Name),
 		ticksLabelsTexts: strings.Split(eagle.AttributeString(elem, "PANEL_LEGEND_TICKS_LABELS_TEXTS", ""), ","),
 	}
+	if ec.rotation, err = elem.Rotation(); err != nil {
+		return ec, fmt.Errorf("object %q: %v", elem.Name, err)
+	}
 	if ec.legendOffsetX, err = eagle.AttributeFloat(elem, "PANEL_LEGEND_OFFSET_X", 0.0); err != nil {
 		return ec, err
 	}
@@ -221,28 +265,95 @@ func elementConfigFromElement(elem eagle.Element) (elementConfig, error) {
 	return ec, nil
 }
 
-func elementOp(plc panelLayoutContext, elem eagle.Element) {
+// elementOp creates the panel features for a single element, and returns
+// the knob, nut and plug fitted through its panel hole, if any, in panel
+// coordinates
+func elementOp(plc panelLayoutContext, elem eagle.Element) hardware.Hardware {
 	hole, needHole, err := holeForPanelElement(elem)
 	if err != nil {
 		log.Fatalf("can't find drill size for element %q: %v", elem.Name, err)
 	}
-	if !needHole {
-		return
+	cutouts, err := cutoutsForPanelElement(elem)
+	if err != nil {
+		log.Fatalf("can't find cutout size for element %q: %v", elem.Name, err)
+	}
+	if !needHole && len(cutouts) == 0 {
+		return hardware.Hardware{}
+	}
+	var flatted []geometry.Vertex
+	var tab eagle.Hole
+	var needTab bool
+	if needHole {
+		if flatted, err = flattedHoleForPanelElement(elem, hole.Drill); err != nil {
+			log.Fatalf("can't find flatted hole size for element %q: %v", elem.Name, err)
+		}
+		if tab, needTab, err = tabHoleForPanelElement(elem); err != nil {
+			log.Fatalf("can't find anti-rotation tab hole size for element %q: %v", elem.Name, err)
+		}
 	}
 	// derive the per-element config
 	elementConfig, err := elementConfigFromElement(elem)
 	if err != nil {
 		log.Fatalf("error extracting per-element config from attributes: %v", err)
 	}
-	// the hole was generated with coordinates from the source board, now
-	// adjust them to be in the right place on the panel
+	hsw, err := eagle.AttributeFloat(elem, "PANEL_HOLE_STOP_WIDTH", *plc.cfg.HoleStopRadius)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// element-relative features, including the element origin, need to be
+	// moved to the right place on the panel
+	toPanel := elementConfig.toPanel(plc, elem)
+	originX, originY := toPanel.Apply(0, 0)
 	tstop := plc.panel.LayerByName("tStop")
-	hole.X += plc.bc.XOffset
-	hole.Y += plc.bc.YOffset
-	plc.panel.Board.Plain.Holes = append(plc.panel.Board.Plain.Holes, hole)
+	// how far the panel features extend from the origin towards the legend,
+	// so that the legend can be nudged clear of them
+	clearance := 0.0
+	if needHole {
+		hole = plc.boardToPanel.Hole(hole)
+		if flatted != nil {
+			// flatted holes can't be drilled, so mill them like a cutout
+			cutouts = append(cutouts, flatted)
+		} else {
+			plc.panel.Board.Plain.Holes = append(plc.panel.Board.Plain.Holes, hole)
+			stop := eagle.Circle{
+				X: hole.X, Y: hole.Y,
+				Radius: hole.Drill / 2.0,
+				Width:  hsw,
+				Layer:  tstop,
+			}
+			plc.panel.Board.Plain.Circles = append(plc.panel.Board.Plain.Circles, stop)
+			checkKeepouts(plc, elem.Name, stop.X, stop.Y, stop.Radius+stop.Width/2)
+		}
+		clearance = hole.Drill / 2.0
+	}
+	hw := hardware.Hardware{}
+	if needHole {
+		if hw, err = hardwareForPanelElement(elem); err != nil {
+			log.Fatalf("can't find panel hardware for element %q: %v", elem.Name, err)
+		}
+		hw.X, hw.Y = hole.X, hole.Y
+	}
+	if needTab {
+		// no stop ring here: the tab hole is normally hidden by the nut or
+		// washer, and Eagle opens the stop mask over holes anyway
+		tab = toPanel.Hole(tab)
+		plc.panel.Board.Plain.Holes = append(plc.panel.Board.Plain.Holes, tab)
+		checkKeepouts(plc, elem.Name, tab.X, tab.Y, tab.Drill/2.0)
+	}
+	for _, cutout := range cutouts {
+		outline := toPanel.Vertices(cutout)
+		plc.panel.Board.Plain.Wires = append(plc.panel.Board.Plain.Wires, util.WireOutline(outline, plc.panel.LayerByName(plc.cutoutLayer), 0)...)
+		plc.panel.Board.Plain.Wires = append(plc.panel.Board.Plain.Wires, util.WireOutline(outline, tstop, hsw)...)
+		points := geometry.Flatten(outline, 0.01)
+		checkOutlineKeepouts(plc, elem.Name, points, hsw/2)
+		for _, p := range points {
+			clearance = math.Max(clearance, (p.Y-originY)*elementConfig.legendLocationFactor)
+		}
+	}
+	legendX, legendY := elementConfig.legendPosition(originX, originY, clearance+*plc.cfg.TextSpacing)
 	text := eagle.Text{
-		X:     hole.X + elementConfig.legendOffsetX,
-		Y:     hole.Y + ((elementConfig.legendOffsetY + (hole.Drill / 2.0) + *plc.cfg.TextSpacing) * elementConfig.legendLocationFactor),
+		X:     legendX,
+		Y:     legendY,
 		Size:  *plc.cfg.TextSize,
 		Layer: plc.panel.LayerByName(plc.legendLayer),
 		Text:  elementConfig.legend,
@@ -254,22 +365,11 @@ func elementOp(plc panelLayoutContext, elem eagle.Element) {
 	} else {
 		log.Printf("%s: skipping legend\n", elem.Name)
 	}
-	hsw, err := eagle.AttributeFloat(elem, "PANEL_HOLE_STOP_WIDTH", *plc.cfg.HoleStopRadius)
-	if err != nil {
-		log.Fatal(err)
-	}
-	stop := eagle.Circle{
-		X: hole.X, Y: hole.Y,
-		Radius: hole.Drill / 2.0,
-		Width:  hsw,
-		Layer:  tstop,
-	}
-	plc.panel.Board.Plain.Circles = append(plc.panel.Board.Plain.Circles, stop)
-	if elementConfig.ticks {
+	if elementConfig.ticks && needHole {
 		rpg := geometry.RadialPointGenerator{
 			X: hole.X, Y: hole.Y,
-			StartAngle: elementConfig.ticksStartAngle,
-			EndAngle:   elementConfig.ticksEndAngle,
+			StartAngle: elementConfig.tickAngle(elementConfig.ticksStartAngle),
+			EndAngle:   elementConfig.tickAngle(elementConfig.ticksEndAngle),
 			Count:      elementConfig.ticksCount,
 		}
 		tickstarts := rpg.GenerateAtRadius(hole.Drill/2.0 + *plc.cfg.HoleStopRadius)
@@ -293,6 +393,260 @@ func elementOp(plc panelLayoutContext, elem eagle.Element) {
 			}
 		}
 	}
+	return hw
+}
+
+// checkKeepouts warns, or aborts if strict checking is enabled, when a
+// circular panel feature overlaps any of the panel's keepout areas
+func checkKeepouts(plc panelLayoutContext, name string, x, y, r float64) {
+	kr, ok := plc.spec.(panel.KeepoutRegions)
+	if !ok {
+		return
+	}
+	for index, keepout := range kr.Keepouts() {
+		if !keepout.IntersectsCircle(x, y, r) {
+			continue
+		}
+		msg := fmt.Sprintf("%s: panel hole or its stop ring overlaps %s keepout %d", name, keepout.Shape, index+1)
+		if *plc.cfg.StrictKeepouts {
+			log.Fatal(msg)
+		}
+		log.Printf("warning: %s", msg)
+	}
+}
+
+// checkOutlineKeepouts is like checkKeepouts, but for panel cutouts
+func checkOutlineKeepouts(plc panelLayoutContext, name string, outline []geometry.Point, margin float64) {
+	kr, ok := plc.spec.(panel.KeepoutRegions)
+	if !ok {
+		return
+	}
+	for index, keepout := range kr.Keepouts() {
+		if !keepout.IntersectsOutline(outline, margin) {
+			continue
+		}
+		msg := fmt.Sprintf("%s: panel cutout or its stop outline overlaps %s keepout %d", name, keepout.Shape, index+1)
+		if *plc.cfg.StrictKeepouts {
+			log.Fatal(msg)
+		}
+		log.Printf("warning: %s", msg)
+	}
+}
+
+// hardwareOp warns, or aborts if strict checking is enabled, when the
+// hardware fitted through panel holes collides with other hardware, runs
+// off the edge of the panel or overlaps the rails or keepouts
+func hardwareOp(plc panelLayoutContext, items []hardware.Hardware) {
+	clearance := *plc.cfg.HardwareClearance
+	for _, clash := range hardware.Clashes(items, clearance) {
+		hardwareProblem(plc, clash.String())
+	}
+	bo, err := outline.FindBoardOutline(plc.panel)
+	if err != nil {
+		log.Fatalf("can't find panel outline: %v", err)
+	}
+	outer := geometry.Flatten(bo.Outer, 0.01)
+	rails, hasRails := plc.spec.(panel.Rails)
+	for _, hw := range items {
+		if gap := hw.EdgeGap(outer); gap < 0 {
+			hardwareProblem(plc, fmt.Sprintf("%s hardware overhangs the panel edge by %.2fmm", hw.Name, -gap))
+		} else if gap < clearance {
+			hardwareProblem(plc, fmt.Sprintf("%s hardware is only %.2fmm from the panel edge", hw.Name, gap))
+		}
+		for _, index := range hardwareKeepouts(plc.spec, hw, clearance) {
+			hardwareProblem(plc, fmt.Sprintf("%s hardware overlaps keepout %d", hw.Name, index+1))
+		}
+		if !hasRails {
+			continue
+		}
+		for _, band := range panel.RailBands(rails) {
+			if overlap := hw.RailOverlap(band.Bottom, band.Top, clearance); overlap > 0 {
+				hardwareProblem(plc, fmt.Sprintf("%s hardware overlaps the rail between Y=%.2f and Y=%.2f by %.2fmm", hw.Name, band.Bottom, band.Top, overlap))
+			}
+		}
+	}
+}
+
+// hardwareKeepouts returns the indexes of the keepouts that hardware, plus
+// clearance, overlaps. Hardware sits on the front of the panel, so keepouts
+// that only apply to the back are ignored.
+func hardwareKeepouts(spec panel.Panel, hw hardware.Hardware, clearance float64) []int {
+	kr, ok := spec.(panel.KeepoutRegions)
+	if !ok {
+		return nil
+	}
+	indexes := []int{}
+	for index, keepout := range kr.Keepouts() {
+		if keepout.Top() && keepout.IntersectsCircle(hw.X, hw.Y, hw.Diameter()/2+clearance) {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+func hardwareProblem(plc panelLayoutContext, msg string) {
+	if *plc.cfg.StrictHardware {
+		log.Fatal(msg)
+	}
+	log.Printf("warning: %s", msg)
+}
+
+// hardwareForPanelElement finds the diameters of the knob, nut and plug
+// fitted through an element's panel hole. Each may be given in millimetres
+// by a PANEL_*_MM attribute, or by naming a catalogue item.
+func hardwareForPanelElement(elem eagle.Element) (hardware.Hardware, error) {
+	hw := hardware.Hardware{Name: elem.Name}
+	for _, item := range []struct {
+		kind      string
+		attribute string
+		diameter  *float64
+	}{
+		{kind: hardware.Knob, attribute: "PANEL_KNOB", diameter: &hw.Knob},
+		{kind: hardware.Nut, attribute: "PANEL_NUT", diameter: &hw.Nut},
+		{kind: hardware.Plug, attribute: "PANEL_PLUG", diameter: &hw.Plug},
+	} {
+		diameter, err := eagle.AttributeFloat(elem, item.attribute+"_MM", 0.0)
+		if err != nil {
+			return hw, err
+		}
+		if diameter < 0 {
+			return hw, fmt.Errorf("%s_MM can't be negative", item.attribute)
+		}
+		if name := eagle.AttributeString(elem, item.attribute, ""); diameter == 0 && name != "" {
+			if diameter, err = hardware.Lookup(item.kind, name); err != nil {
+				return hw, err
+			}
+		}
+		*item.diameter = diameter
+	}
+	return hw, nil
+}
+
+// cutoutsForPanelElement generates the outlines of any rectangular cutouts
+// or slots an element needs, relative to the element origin and before
+// the element's rotation is applied
+func cutoutsForPanelElement(elem eagle.Element) ([][]geometry.Vertex, error) {
+	dims := map[string]float64{
+		"PANEL_CUTOUT_WIDTH":    0.0,
+		"PANEL_CUTOUT_HEIGHT":   0.0,
+		"PANEL_CUTOUT_RADIUS":   0.0,
+		"PANEL_CUTOUT_OFFSET_X": 0.0,
+		"PANEL_CUTOUT_OFFSET_Y": 0.0,
+		"PANEL_SLOT_LENGTH":     0.0,
+		"PANEL_SLOT_WIDTH":      0.0,
+	}
+	for k, defval := range dims {
+		v, err := eagle.AttributeFloat(elem, k, defval)
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 && k != "PANEL_CUTOUT_OFFSET_X" && k != "PANEL_CUTOUT_OFFSET_Y" {
+			return nil, fmt.Errorf("%s can't be negative", k)
+		}
+		dims[k] = v
+	}
+	cutouts := [][]geometry.Vertex{}
+	ox, oy := dims["PANEL_CUTOUT_OFFSET_X"], dims["PANEL_CUTOUT_OFFSET_Y"]
+	if width, height := dims["PANEL_CUTOUT_WIDTH"], dims["PANEL_CUTOUT_HEIGHT"]; width > 0 || height > 0 {
+		if width == 0 || height == 0 {
+			return nil, fmt.Errorf("both PANEL_CUTOUT_WIDTH and PANEL_CUTOUT_HEIGHT are required for a cutout")
+		}
+		log.Printf("%s: found %vmm x %vmm cutout", elem.Name, width, height)
+		cutouts = append(cutouts, geometry.RoundedRectangle(ox, oy, width, height, dims["PANEL_CUTOUT_RADIUS"]))
+	}
+	if length, width := dims["PANEL_SLOT_LENGTH"], dims["PANEL_SLOT_WIDTH"]; length > 0 || width > 0 {
+		if length == 0 || width == 0 {
+			return nil, fmt.Errorf("both PANEL_SLOT_LENGTH and PANEL_SLOT_WIDTH are required for a slot")
+		}
+		if length < width {
+			return nil, fmt.Errorf("PANEL_SLOT_LENGTH must be at least PANEL_SLOT_WIDTH")
+		}
+		log.Printf("%s: found %vmm x %vmm slot", elem.Name, length, width)
+		cutouts = append(cutouts, geometry.RoundedRectangle(ox, oy, length, width, width/2))
+	}
+	return cutouts, nil
+}
+
+// flattedHoleForPanelElement generates the outline of a D-shaped (one flat)
+// or double-D (two flats) hole, relative to the element origin and before
+// the element's rotation is applied, if the element needs one. With no
+// rotation, the flat of a D-shaped hole is at the top.
+func flattedHoleForPanelElement(elem eagle.Element, drill float64) ([]geometry.Vertex, error) {
+	flat, err := eagle.AttributeFloat(elem, "PANEL_DRILL_FLAT_MM", -1.0)
+	if err != nil {
+		return nil, err
+	}
+	if flat < 0.0 { // no flats, do nothing
+		return nil, nil
+	}
+	flats, err := eagle.AttributeInt(elem, "PANEL_DRILL_FLATS", 1)
+	if err != nil {
+		return nil, err
+	}
+	angle, err := eagle.AttributeFloat(elem, "PANEL_DRILL_FLAT_ANGLE", 0.0)
+	if err != nil {
+		return nil, err
+	}
+	if flat <= 0 || flat >= drill {
+		return nil, fmt.Errorf("PANEL_DRILL_FLAT_MM must be between 0 and PANEL_DRILL_MM")
+	}
+	r := drill / 2
+	var vertices []geometry.Vertex
+	switch flats {
+	case 1:
+		// across-flats is measured from the flat to the far side of the
+		// circle, so the flat is (flat - r) above the centre
+		d := flat - r
+		w := math.Sqrt(r*r - d*d)
+		alpha := math.Asin(d/r) * 180 / math.Pi
+		vertices = []geometry.Vertex{
+			{X: -w, Y: d, Curve: 180 + 2*alpha},
+			{X: w, Y: d},
+		}
+	case 2:
+		h := flat / 2
+		w := math.Sqrt(r*r - h*h)
+		alpha := math.Asin(h/r) * 180 / math.Pi
+		vertices = []geometry.Vertex{
+			{X: w, Y: -h, Curve: 2 * alpha},
+			{X: w, Y: h},
+			{X: -w, Y: h, Curve: 2 * alpha},
+			{X: -w, Y: -h},
+		}
+	default:
+		return nil, fmt.Errorf("PANEL_DRILL_FLATS must be 1 or 2, not %d", flats)
+	}
+	vertices = geometry.Rotate(angle).Vertices(vertices)
+	log.Printf("%s: found %d flat(s), %vmm across flats", elem.Name, flats, flat)
+	return vertices, nil
+}
+
+// tabHoleForPanelElement generates a small anti-rotation tab hole, relative
+// to the element origin and before the element's rotation is applied, if
+// the element needs one
+func tabHoleForPanelElement(elem eagle.Element) (eagle.Hole, bool, error) {
+	drill, err := eagle.AttributeFloat(elem, "PANEL_TAB_DRILL_MM", -1.0)
+	if err != nil {
+		return eagle.Hole{}, false, err
+	}
+	if drill < 0.0 { // no tab, do nothing
+		return eagle.Hole{}, false, nil
+	}
+	offset, err := eagle.AttributeFloat(elem, "PANEL_TAB_OFFSET_MM", 0.0)
+	if err != nil {
+		return eagle.Hole{}, false, err
+	}
+	if offset <= 0.0 {
+		return eagle.Hole{}, false, fmt.Errorf("PANEL_TAB_OFFSET_MM is required for a tab hole, and must be positive")
+	}
+	// polar angle, counter-clockwise from 3 o'clock like Eagle rotations
+	angle, err := eagle.AttributeFloat(elem, "PANEL_TAB_ANGLE", 90.0)
+	if err != nil {
+		return eagle.Hole{}, false, err
+	}
+	x, y := geometry.Rotate(angle).Apply(offset, 0)
+	log.Printf("%s: found %vmm tab hole %vmm from the origin", elem.Name, drill, offset)
+	return eagle.Hole{X: x, Y: y, Drill: drill}, true, nil
 }
 
 // generate a panel hole for a single element, if necessary
@@ -311,23 +665,107 @@ func holeForPanelElement(elem eagle.Element) (eagle.Hole, bool, error) {
 	return hole, true, nil
 }
 
+// panelAttributes collects the PANEL_* attributes from an attribute carrier
+func panelAttributes(c eagle.AttributeCarrier) map[string]string {
+	attributes := make(map[string]string)
+	for _, attribute := range c.GetAttributes() {
+		if strings.HasPrefix(attribute.Name, "PANEL_") {
+			attributes[attribute.Name] = attribute.Value
+		}
+	}
+	return attributes
+}
+
+// checkSchematicAttributes warns about any PANEL_* attributes that differ
+// between board elements and their schematic parts, or between the board
+// and schematic global attributes. Returns the number of disagreements.
+func checkSchematicAttributes(board eagle.Board, schematic eagle.Schematic) int {
+	problems := comparePanelAttributes("global attributes", panelAttributes(board), panelAttributes(schematic))
+	for _, elem := range board.Elements {
+		part, found := schematic.PartByName(elem.Name)
+		if !found {
+			if len(panelAttributes(elem)) > 0 {
+				log.Printf("warning: %s: board element has panel attributes but no schematic part", elem.Name)
+				problems++
+			}
+			continue
+		}
+		resolved, err := schematic.ResolvePart(part)
+		if err != nil {
+			log.Printf("warning: %v", err)
+			problems++
+			continue
+		}
+		problems += comparePanelAttributes(elem.Name, panelAttributes(elem), panelAttributes(resolved))
+	}
+	return problems
+}
+
+func comparePanelAttributes(what string, board, schematic map[string]string) int {
+	problems := 0
+	for _, name := range sortedKeys(board) {
+		value := board[name]
+		if sv, found := schematic[name]; !found {
+			log.Printf("warning: %s: %s=%q is on the board but not in the schematic", what, name, value)
+			problems++
+		} else if sv != value {
+			log.Printf("warning: %s: %s is %q on the board but %q in the schematic", what, name, value, sv)
+			problems++
+		}
+	}
+	for _, name := range sortedKeys(schematic) {
+		if _, found := board[name]; !found {
+			log.Printf("warning: %s: %s=%q is in the schematic but not on the board", what, name, schematic[name])
+			problems++
+		}
+	}
+	return problems
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type config struct {
-	Format         *string
-	TextSpacing    *float64
-	TextSize       *float64
-	HoleStopRadius *float64
-	SpecFile       *string
+	Format             *string
+	TextSpacing        *float64
+	TextSize           *float64
+	HoleStopRadius     *float64
+	SpecFile           *string
+	Gerber             *bool
+	SVG                *bool
+	Schematic          *bool
+	StrictAttributes   *bool
+	StrictKeepouts     *bool
+	MountingSlotLength *float64
+	CopperPullback     *float64
+	HardwareClearance  *float64
+	StrictHardware     *bool
 }
 
 func configureFromFlags() config {
-	formatList := "(" + strings.Join([]string{FormatEurorack, FormatPulplogic, FormatIntellijel, FormatSpec}, ",") + ")"
 	cfg := config{
-		Format:         flag.String("format", FormatEurorack, "panel format to create "+formatList),
-		TextSpacing:    flag.Float64("text-spacing", 3.5, "spacing between a hole and its related label"),
-		TextSize:       flag.Float64("text-size", 2.25, "label text size"),
-		HoleStopRadius: flag.Float64("hole-stop-radius", 2.0, "Radius to pull back soldermask around a hole"),
-		SpecFile:       flag.String("spec-file", "", "filename to read YAML panel spec from"),
-	}
+		Format:             cli.FormatFlag("panel format to create"),
+		TextSpacing:        flag.Float64("text-spacing", 3.5, "spacing between a hole and its related label"),
+		TextSize:           flag.Float64("text-size", 2.25, "label text size"),
+		HoleStopRadius:     flag.Float64("hole-stop-radius", 2.0, "Radius to pull back soldermask around a hole"),
+		SpecFile:           flag.String("spec-file", "", "filename to read YAML panel spec from"),
+		Gerber:             flag.Bool("gerber", false, "also write Gerber and Excellon fabrication files for each panel"),
+		SVG:                flag.Bool("svg", false, "also write an SVG preview of each panel"),
+		Schematic:          flag.Bool("schematic", false, "cross-check panel attributes against the matching .sch schematic file"),
+		StrictAttributes:   flag.Bool("strict-attributes", false, "fail, rather than warn, when -schematic finds panel attributes that differ"),
+		StrictKeepouts:     flag.Bool("strict-keepouts", false, "fail, rather than warn, when a panel hole overlaps a keepout area"),
+		MountingSlotLength: cli.MountingSlotLengthFlag(),
+		CopperPullback:     flag.Float64("copper-pullback", standard.CopperPullback, "distance to pull copper pours back from the panel edge, holes, cutouts and keepouts"),
+		HardwareClearance:  flag.Float64("hardware-clearance", 1.0, "minimum gap between knobs, nuts and plugs, and from them to the panel edge and rails"),
+		StrictHardware:     flag.Bool("strict-hardware", false, "fail, rather than warn, when knobs, nuts or plugs collide or overlap the panel edge, rails or keepouts"),
+	}
+	flag.Usage = cli.Usage("")
 	flag.Parse()
 	return cfg
 }
@@ -339,17 +777,51 @@ func main() {
 		if err != nil {
 			log.Fatalf("can't load input file %q: %v", filename, err)
 		}
+		if *config.Schematic {
+			schematicFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".sch"
+			schematic, err := eagle.LoadEagleFile(schematicFilename)
+			if err != nil {
+				log.Fatalf("can't load schematic file %q: %v", schematicFilename, err)
+			}
+			if schematic.Schematic == nil {
+				log.Fatalf("%q is not an Eagle schematic file", schematicFilename)
+			}
+			problems := checkSchematicAttributes(board.Board, *schematic.Schematic)
+			if problems > 0 && *config.StrictAttributes {
+				log.Fatalf("%s: %d panel attribute(s) differ from schematic %q", filename, problems, schematicFilename)
+			}
+		}
 		plc, err := setupPanelLayoutContext(board, config)
 		if err != nil {
 			log.Fatalf("can't setup panel layout context: %v", err)
 		}
 		headerOp(plc)
+		items := []hardware.Hardware{}
 		for _, elem := range plc.board.Board.Elements {
-			elementOp(plc, elem)
+			if hw := elementOp(plc, elem); hw.Present() {
+				items = append(items, hw)
+			}
+		}
+		hardwareOp(plc, items)
+		if err := standard.ApplyCopperFill(plc.panel, *config.CopperPullback); err != nil {
+			log.Fatalf("can't pour copper: %v", err)
 		}
 		outFilename := filepath.Base(filename) + ".panel.brd"
 		if err := plc.panel.WriteFile(outFilename); err != nil {
 			log.Fatalf("can't write output file %q: %v", outFilename, err)
 		}
+		if *config.Gerber {
+			written, err := gerber.WriteFabFiles(plc.panel, strings.TrimSuffix(outFilename, ".brd"))
+			if err != nil {
+				log.Fatalf("can't write fabrication files: %v", err)
+			}
+			log.Printf("wrote fabrication files: %s", strings.Join(written, ", "))
+		}
+		if *config.SVG {
+			svgFilename := strings.TrimSuffix(outFilename, ".brd") + ".svg"
+			if err := svg.WriteFile(plc.panel, svgFilename); err != nil {
+				log.Fatalf("can't write SVG preview %q: %v", svgFilename, err)
+			}
+		}
 	}
 }
@@ -0,0 +1,685 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/format/eurorack"
+	filespec "github.com/jsleeio/go-eagle/pkg/format/spec"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/hardware"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// testElement returns an element with the given rotation and attributes
+func testElement(rotate string, attributes map[string]string) eagle.Element {
+	elem := eagle.Element{Name: "P1", Rotate: rotate}
+	for name, value := range attributes {
+		elem.Attributes = append(elem.Attributes, eagle.Attribute{Name: name, Value: value})
+	}
+	return elem
+}
+
+// bounds finds the extents of an outline, with curves flattened
+func bounds(outline []geometry.Vertex) (xmin, ymin, xmax, ymax float64) {
+	xmin, ymin = math.Inf(1), math.Inf(1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	for _, p := range geometry.Flatten(outline, 0.001) {
+		xmin, ymin = math.Min(xmin, p.X), math.Min(ymin, p.Y)
+		xmax, ymax = math.Max(xmax, p.X), math.Max(ymax, p.Y)
+	}
+	return xmin, ymin, xmax, ymax
+}
+
+// sameBounds compares outline extents, allowing for curves being flattened
+func sameBounds(got, want [4]float64) bool {
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 0.01 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCutoutsForPanelElement(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotate     string
+		attributes map[string]string
+		// extents of each cutout on the panel, for an element at (100,50)
+		want [][4]float64
+		err  bool
+	}{
+		{name: "nothing"},
+		{
+			name:       "cutout",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "10", "PANEL_CUTOUT_HEIGHT": "4"},
+			want:       [][4]float64{{95, 48, 105, 52}},
+		},
+		{
+			name:       "offset cutout",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "10", "PANEL_CUTOUT_HEIGHT": "4", "PANEL_CUTOUT_OFFSET_X": "5"},
+			want:       [][4]float64{{100, 48, 110, 52}},
+		},
+		{
+			name:       "rotated offset cutout",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "10", "PANEL_CUTOUT_HEIGHT": "4", "PANEL_CUTOUT_OFFSET_X": "5", "PANEL_CUTOUT_RADIUS": "1"},
+			want:       [][4]float64{{98, 50, 102, 60}},
+		},
+		{
+			name:       "mirrored offset cutout",
+			rotate:     "MR0",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "10", "PANEL_CUTOUT_HEIGHT": "4", "PANEL_CUTOUT_OFFSET_X": "5"},
+			want:       [][4]float64{{90, 48, 100, 52}},
+		},
+		{
+			name:       "slot",
+			attributes: map[string]string{"PANEL_SLOT_LENGTH": "8", "PANEL_SLOT_WIDTH": "3"},
+			want:       [][4]float64{{96, 48.5, 104, 51.5}},
+		},
+		{
+			name:       "rotated slot",
+			rotate:     "R270",
+			attributes: map[string]string{"PANEL_SLOT_LENGTH": "8", "PANEL_SLOT_WIDTH": "3", "PANEL_CUTOUT_OFFSET_Y": "2"},
+			want:       [][4]float64{{100.5, 46, 103.5, 54}},
+		},
+		{
+			name:       "round slot",
+			attributes: map[string]string{"PANEL_SLOT_LENGTH": "3", "PANEL_SLOT_WIDTH": "3"},
+			want:       [][4]float64{{98.5, 48.5, 101.5, 51.5}},
+		},
+		{
+			name:       "slot shorter than its width",
+			attributes: map[string]string{"PANEL_SLOT_LENGTH": "2", "PANEL_SLOT_WIDTH": "3"},
+			err:        true,
+		},
+		{
+			name:       "cutout without height",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "10"},
+			err:        true,
+		},
+		{
+			name:       "slot without width",
+			attributes: map[string]string{"PANEL_SLOT_LENGTH": "8"},
+			err:        true,
+		},
+		{
+			name:       "negative cutout",
+			attributes: map[string]string{"PANEL_CUTOUT_WIDTH": "-10", "PANEL_CUTOUT_HEIGHT": "4"},
+			err:        true,
+		},
+	}
+	for _, test := range tests {
+		elem := testElement(test.rotate, test.attributes)
+		cutouts, err := cutoutsForPanelElement(elem)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if len(cutouts) != len(test.want) {
+			t.Errorf("%s: got %d cutouts, want %d", test.name, len(cutouts), len(test.want))
+			continue
+		}
+		ec, err := elementConfigFromElement(elem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index, cutout := range cutouts {
+			xmin, ymin, xmax, ymax := bounds(geometry.Placement(100, 50, ec.rotation).Vertices(cutout))
+			if got := [4]float64{xmin, ymin, xmax, ymax}; !sameBounds(got, test.want[index]) {
+				t.Errorf("%s: cutout %d covers %v, want %v", test.name, index, got, test.want[index])
+			}
+		}
+	}
+}
+
+func TestFlattedHoleForPanelElement(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotate     string
+		attributes map[string]string
+		// extents of the hole relative to the element origin, or nothing if
+		// the hole is round
+		want []float64
+		err  bool
+	}{
+		{name: "round hole"},
+		{
+			name:       "D hole",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "8"},
+			want:       []float64{-5, -5, 5, 3},
+		},
+		{
+			name:       "D hole, flat to the left",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "8", "PANEL_DRILL_FLAT_ANGLE": "90"},
+			want:       []float64{-3, -5, 5, 5},
+		},
+		{
+			name:       "rotated D hole",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "8", "PANEL_DRILL_FLAT_ANGLE": "90"},
+			want:       []float64{-5, -3, 5, 5},
+		},
+		{
+			name:       "mirrored D hole",
+			rotate:     "MR0",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "8", "PANEL_DRILL_FLAT_ANGLE": "90"},
+			want:       []float64{-5, -5, 3, 5},
+		},
+		{
+			name:       "mirrored and rotated D hole",
+			rotate:     "MR90",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "8", "PANEL_DRILL_FLAT_ANGLE": "90"},
+			want:       []float64{-5, -5, 5, 3},
+		},
+		{
+			name:       "double-D hole",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "6", "PANEL_DRILL_FLATS": "2"},
+			want:       []float64{-5, -3, 5, 3},
+		},
+		{
+			name:       "rotated double-D hole",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "6", "PANEL_DRILL_FLATS": "2"},
+			want:       []float64{-3, -5, 3, 5},
+		},
+		{
+			name:       "mirrored double-D hole",
+			rotate:     "MR90",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "6", "PANEL_DRILL_FLATS": "2", "PANEL_DRILL_FLAT_ANGLE": "90"},
+			want:       []float64{-5, -3, 5, 3},
+		},
+		{
+			name:       "flat wider than the hole",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "10"},
+			err:        true,
+		},
+		{
+			name:       "too many flats",
+			attributes: map[string]string{"PANEL_DRILL_FLAT_MM": "6", "PANEL_DRILL_FLATS": "3"},
+			err:        true,
+		},
+	}
+	for _, test := range tests {
+		elem := testElement(test.rotate, test.attributes)
+		flatted, err := flattedHoleForPanelElement(elem, 10)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if test.want == nil {
+			if flatted != nil {
+				t.Errorf("%s: got outline %v, want a round hole", test.name, flatted)
+			}
+			continue
+		}
+		ec, err := elementConfigFromElement(elem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xmin, ymin, xmax, ymax := bounds(geometry.FromRotation(ec.rotation).Vertices(flatted))
+		want := [4]float64{test.want[0], test.want[1], test.want[2], test.want[3]}
+		if got := [4]float64{xmin, ymin, xmax, ymax}; !sameBounds(got, want) {
+			t.Errorf("%s: hole covers %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestTabHoleForPanelElement(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotate     string
+		attributes map[string]string
+		// tab hole position relative to the element origin
+		x, y float64
+		none bool
+		err  bool
+	}{
+		{name: "no tab", none: true},
+		{
+			name:       "tab above",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2", "PANEL_TAB_OFFSET_MM": "6"},
+			x:          0, y: 6,
+		},
+		{
+			name:       "tab to the right",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2", "PANEL_TAB_OFFSET_MM": "6", "PANEL_TAB_ANGLE": "0"},
+			x:          6, y: 0,
+		},
+		{
+			name:       "rotated tab",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2", "PANEL_TAB_OFFSET_MM": "6", "PANEL_TAB_ANGLE": "0"},
+			x:          0, y: 6,
+		},
+		{
+			name:       "mirrored tab",
+			rotate:     "MR0",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2", "PANEL_TAB_OFFSET_MM": "6", "PANEL_TAB_ANGLE": "0"},
+			x:          -6, y: 0,
+		},
+		{
+			name:       "mirrored and rotated tab",
+			rotate:     "MR90",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2", "PANEL_TAB_OFFSET_MM": "6", "PANEL_TAB_ANGLE": "0"},
+			x:          0, y: -6,
+		},
+		{
+			name:       "tab without offset",
+			attributes: map[string]string{"PANEL_TAB_DRILL_MM": "2"},
+			err:        true,
+		},
+	}
+	for _, test := range tests {
+		elem := testElement(test.rotate, test.attributes)
+		tab, needTab, err := tabHoleForPanelElement(elem)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if needTab == test.none {
+			t.Errorf("%s: got tab %v, want tab %v", test.name, needTab, !test.none)
+			continue
+		}
+		if test.none {
+			continue
+		}
+		ec, err := elementConfigFromElement(elem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := ec.offset(tab.X, tab.Y)
+		if math.Abs(x-test.x) > 1e-9 || math.Abs(y-test.y) > 1e-9 || tab.Drill != 2 {
+			t.Errorf("%s: got %vmm tab at (%v,%v), want 2mm at (%v,%v)", test.name, tab.Drill, x, y, test.x, test.y)
+		}
+	}
+}
+
+func TestHardwareForPanelElement(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		want       hardware.Hardware
+		err        bool
+	}{
+		{
+			name: "nothing",
+			want: hardware.Hardware{},
+		},
+		{
+			name:       "catalogue names",
+			attributes: map[string]string{"PANEL_KNOB": "medium", "PANEL_NUT": "m7-hex", "PANEL_PLUG": "3.5mm"},
+			want:       hardware.Hardware{Knob: 15, Nut: 11.6, Plug: 7},
+		},
+		{
+			name:       "diameters override catalogue names",
+			attributes: map[string]string{"PANEL_NUT": "m7-hex", "PANEL_NUT_MM": "10", "PANEL_KNOB_MM": "12.5"},
+			want:       hardware.Hardware{Knob: 12.5, Nut: 10},
+		},
+		{
+			name:       "unknown catalogue name",
+			attributes: map[string]string{"PANEL_KNOB": "huge"},
+			err:        true,
+		},
+		{
+			name:       "negative diameter",
+			attributes: map[string]string{"PANEL_NUT_MM": "-1"},
+			err:        true,
+		},
+		{
+			name:       "invalid diameter",
+			attributes: map[string]string{"PANEL_PLUG_MM": "wide"},
+			err:        true,
+		},
+	}
+	for _, test := range tests {
+		elem := testElement("", test.attributes)
+		got, err := hardwareForPanelElement(elem)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %+v", test.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		test.want.Name = elem.Name
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestHardwareKeepouts(t *testing.T) {
+	spec := filespec.Spec{
+		SpecWidth:  100,
+		SpecHeight: 100,
+		SpecKeepouts: []panel.Keepout{
+			{Region: panel.Region{Shape: panel.ShapeRectangle, X1: 0, Y1: 0, X2: 10, Y2: 100}, Side: panel.SideBoth},
+			{Region: panel.Region{Shape: panel.ShapeCircle, X: 50, Y: 80, Radius: 5}, Side: panel.SideTop},
+			// behind the panel, where the hardware isn't
+			{Region: panel.Region{Shape: panel.ShapeCircle, X: 50, Y: 20, Radius: 5}, Side: panel.SideBottom},
+		},
+	}
+	tests := []struct {
+		name string
+		hw   hardware.Hardware
+		want []int
+	}{
+		{name: "clear of everything", hw: hardware.Hardware{X: 50, Y: 50, Knob: 20}, want: []int{}},
+		{name: "knob over the rectangle", hw: hardware.Hardware{X: 18, Y: 50, Knob: 15}, want: []int{0}},
+		{name: "knob within clearance of the rectangle", hw: hardware.Hardware{X: 18.4, Y: 50, Knob: 15}, want: []int{0}},
+		{name: "knob just clear of the rectangle", hw: hardware.Hardware{X: 19, Y: 50, Knob: 15}, want: []int{}},
+		{name: "nut over the top circle", hw: hardware.Hardware{X: 50, Y: 70, Nut: 11.6}, want: []int{1}},
+		{name: "plug over the bottom circle", hw: hardware.Hardware{X: 50, Y: 20, Plug: 7}, want: []int{}},
+	}
+	for _, test := range tests {
+		got := hardwareKeepouts(spec, test.hw, 1)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got keepouts %v, want %v", test.name, got, test.want)
+		}
+	}
+	// formats without keepouts have nothing to overlap
+	if got := hardwareKeepouts(eurorack.NewEurorack(10), hardware.Hardware{X: 1, Y: 1, Knob: 20}, 1); len(got) != 0 {
+		t.Errorf("got keepouts %v for a format without any", got)
+	}
+}
+
+// captureLog collects anything logged while f runs
+func captureLog(f func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	f()
+	return buf.String()
+}
+
+func TestComparePanelAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     map[string]string
+		schematic map[string]string
+		want      int
+		logHas    []string
+	}{
+		{name: "both empty"},
+		{
+			name:      "matching",
+			board:     map[string]string{"PANEL_LABEL": "OUT", "PANEL_HOLE_DIAMETER": "6"},
+			schematic: map[string]string{"PANEL_LABEL": "OUT", "PANEL_HOLE_DIAMETER": "6"},
+		},
+		{
+			name:      "different values",
+			board:     map[string]string{"PANEL_LABEL": "OUT"},
+			schematic: map[string]string{"PANEL_LABEL": "IN"},
+			want:      1,
+			logHas:    []string{`PANEL_LABEL is "OUT" on the board but "IN" in the schematic`},
+		},
+		{
+			name:      "board only",
+			board:     map[string]string{"PANEL_LABEL": "OUT", "PANEL_KNOB": "medium"},
+			schematic: map[string]string{"PANEL_LABEL": "OUT"},
+			want:      1,
+			logHas:    []string{`PANEL_KNOB="medium" is on the board but not in the schematic`},
+		},
+		{
+			name:      "schematic only",
+			board:     map[string]string{},
+			schematic: map[string]string{"PANEL_LABEL": "OUT", "PANEL_NUT": "m7-hex"},
+			want:      2,
+			logHas: []string{
+				`PANEL_LABEL="OUT" is in the schematic but not on the board`,
+				`PANEL_NUT="m7-hex" is in the schematic but not on the board`,
+			},
+		},
+	}
+	for _, test := range tests {
+		var got int
+		logged := captureLog(func() {
+			got = comparePanelAttributes("J1", test.board, test.schematic)
+		})
+		if got != test.want {
+			t.Errorf("%s: got %d problems, want %d", test.name, got, test.want)
+		}
+		for _, want := range test.logHas {
+			if !strings.Contains(logged, "J1: "+want) {
+				t.Errorf("%s: log %q doesn't mention %q", test.name, logged, want)
+			}
+		}
+	}
+}
+
+func TestCheckSchematicAttributes(t *testing.T) {
+	load := func(filename string) *eagle.Eagle {
+		e, err := eagle.LoadEagleFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	brd := load("pkg/eagle/testdata/panel.brd")
+	sch := load("pkg/eagle/testdata/panel.sch")
+	element := func(board *eagle.Board, name string) *eagle.Element {
+		for i := range board.Elements {
+			if board.Elements[i].Name == name {
+				return &board.Elements[i]
+			}
+		}
+		t.Fatalf("no element %s in panel.brd", name)
+		return nil
+	}
+	tests := []struct {
+		name string
+		// the test files as saved, rather than with J2's label fixed
+		asSaved bool
+		modify  func(board *eagle.Board, schematic *eagle.Schematic)
+		want    int
+		logHas  string
+	}{
+		{
+			// J2's PANEL_LABEL was set in the schematic but never
+			// forward-annotated to the board
+			name:    "as saved",
+			asSaved: true,
+			want:    1,
+			logHas:  `J2: PANEL_LABEL="OUT" is in the schematic but not on the board`,
+		},
+		{name: "matching"},
+		{
+			name: "technology attribute mismatched",
+			modify: func(board *eagle.Board, schematic *eagle.Schematic) {
+				j1 := element(board, "J1")
+				for i := range j1.Attributes {
+					if j1.Attributes[i].Name == "PANEL_HOLE_DIAMETER" {
+						j1.Attributes[i].Value = "7"
+					}
+				}
+			},
+			want:   1,
+			logHas: `J1: PANEL_HOLE_DIAMETER is "7" on the board but "6" in the schematic`,
+		},
+		{
+			name: "global attribute mismatched",
+			modify: func(board *eagle.Board, schematic *eagle.Schematic) {
+				schematic.Attributes[0].Value = "pulp-logic"
+			},
+			want:   1,
+			logHas: `global attributes: PANEL_FORMAT is "eurorack" on the board but "pulp-logic" in the schematic`,
+		},
+		{
+			name: "part missing from the schematic",
+			modify: func(board *eagle.Board, schematic *eagle.Schematic) {
+				element(board, "J2").Name = "J3"
+			},
+			want:   1,
+			logHas: "J3: board element has panel attributes but no schematic part",
+		},
+		{
+			name: "part with an unknown technology",
+			modify: func(board *eagle.Board, schematic *eagle.Schematic) {
+				for i := range schematic.Parts {
+					if schematic.Parts[i].Name == "J1" {
+						schematic.Parts[i].Technology = "X"
+					}
+				}
+			},
+			want:   1,
+			logHas: `part "J1": no technology "X"`,
+		},
+	}
+	for _, test := range tests {
+		board := brd.Board
+		board.Elements = append([]eagle.Element{}, brd.Board.Elements...)
+		for i := range board.Elements {
+			board.Elements[i].Attributes = append([]eagle.Attribute{}, board.Elements[i].Attributes...)
+		}
+		schematic := *sch.Schematic
+		schematic.Attributes = append([]eagle.Attribute{}, sch.Schematic.Attributes...)
+		schematic.Parts = append([]eagle.Part{}, sch.Schematic.Parts...)
+		if !test.asSaved {
+			j2 := element(&board, "J2")
+			j2.Attributes = append(j2.Attributes, eagle.Attribute{Name: "PANEL_LABEL", Value: "OUT"})
+		}
+		if test.modify != nil {
+			test.modify(&board, &schematic)
+		}
+		var got int
+		logged := captureLog(func() {
+			got = checkSchematicAttributes(board, schematic)
+		})
+		if got != test.want {
+			t.Errorf("%s: got %d problems, want %d; log:\n%s", test.name, got, test.want, logged)
+		}
+		if !strings.Contains(logged, test.logHas) {
+			t.Errorf("%s: log %q doesn't mention %q", test.name, logged, test.logHas)
+		}
+	}
+}
+
+func TestLegendPosition(t *testing.T) {
+	// an element at (10,20), with its hole reaching 5mm towards the legend
+	tests := []struct {
+		name         string
+		rotate       string
+		attributes   map[string]string
+		wantX, wantY float64
+	}{
+		{name: "above", wantX: 10, wantY: 25},
+		{name: "below", attributes: map[string]string{"PANEL_LEGEND_LOCATION": "below"}, wantX: 10, wantY: 15},
+		{
+			name:       "offset",
+			attributes: map[string]string{"PANEL_LEGEND_OFFSET_X": "2", "PANEL_LEGEND_OFFSET_Y": "1"},
+			wantX:      12, wantY: 26,
+		},
+		{
+			// the offset turns with the element, but the legend stays
+			// above the hole on the panel
+			name:       "rotated offset",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_LEGEND_OFFSET_X": "2"},
+			wantX:      10, wantY: 27,
+		},
+		{
+			// the Y offset still nudges away from the hole before rotation
+			name:       "rotated offset below",
+			rotate:     "R90",
+			attributes: map[string]string{"PANEL_LEGEND_LOCATION": "below", "PANEL_LEGEND_OFFSET_Y": "1"},
+			wantX:      11, wantY: 15,
+		},
+		{
+			name:       "mirrored offset",
+			rotate:     "MR0",
+			attributes: map[string]string{"PANEL_LEGEND_OFFSET_X": "2"},
+			wantX:      8, wantY: 25,
+		},
+	}
+	for _, test := range tests {
+		ec, err := elementConfigFromElement(testElement(test.rotate, test.attributes))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		x, y := ec.legendPosition(10, 20, 5)
+		if math.Abs(x-test.wantX) > 1e-9 || math.Abs(y-test.wantY) > 1e-9 {
+			t.Errorf("%s: got legend at (%v,%v), want (%v,%v)", test.name, x, y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestTickAngle(t *testing.T) {
+	// tick angles run clockwise from 9 o'clock
+	tests := []struct {
+		rotate string
+		angle  float64
+		want   float64
+	}{
+		{rotate: "", angle: 45, want: 45},
+		// 9 o'clock turns to 6 o'clock
+		{rotate: "R90", angle: 0, want: -90},
+		// 12 o'clock turns to 9 o'clock
+		{rotate: "R90", angle: 90, want: 0},
+		// mirroring swaps the upper left and upper right
+		{rotate: "MR0", angle: 45, want: 135},
+		// 9 o'clock mirrors to 3 o'clock, then turns to 6 o'clock
+		{rotate: "MR270", angle: 0, want: -90},
+		{rotate: "SR45", angle: 45, want: 0},
+	}
+	for _, test := range tests {
+		ec, err := elementConfigFromElement(testElement(test.rotate, nil))
+		if err != nil {
+			t.Fatalf("%q: %v", test.rotate, err)
+		}
+		if got := ec.tickAngle(test.angle); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%q: tick angle %v became %v, want %v", test.rotate, test.angle, got, test.want)
+		}
+	}
+	if _, err := elementConfigFromElement(testElement("R90x", nil)); err == nil {
+		t.Errorf("expected an error for an invalid rotation")
+	}
+}
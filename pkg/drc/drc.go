@@ -0,0 +1,389 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package drc checks panels against simple mechanical design rules, so that
+// generated or hand-edited panel boards can be verified before they are
+// sent for fabrication.
+package drc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jsleeio/go-eagle/internal/outline"
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+	"github.com/jsleeio/go-eagle/pkg/vectorfont"
+)
+
+// Names of the rules a panel is checked against
+const (
+	// RuleHoleEdge checks the material between a hole and the panel edge or
+	// any slot or cutout
+	RuleHoleEdge = "hole-edge"
+	// RuleHoleRail checks that holes stay clear of the rails
+	RuleHoleRail = "hole-rail"
+	// RuleHoleWeb checks the material between neighbouring holes, and
+	// between neighbouring slots and cutouts
+	RuleHoleWeb = "hole-web"
+	// RuleTextHole checks that text doesn't run into holes or stop rings
+	RuleTextHole = "text-hole"
+	// RuleTextOutline checks that text lies within the panel
+	RuleTextOutline = "text-outline"
+	// RuleSilkWidth checks that silkscreen lines are wide enough to print
+	RuleSilkWidth = "silk-width"
+)
+
+const (
+	// tolerance is the maximum deviation from true arcs when checking
+	// curved outlines
+	tolerance = 0.01
+
+	// mountingHoleTolerance is how close a hole must be to one of the
+	// format's mounting holes to be treated as one
+	mountingHoleTolerance = 0.01
+)
+
+// SilkLayers are the layers printed as silkscreen
+var SilkLayers = []string{"tPlace", "bPlace", "tNames", "bNames"}
+
+// StopLayers are the stop mask layers, whose circles are hole stop rings
+var StopLayers = []string{"tStop", "bStop"}
+
+// Rules are the limits a panel is checked against, in millimetres
+type Rules struct {
+	MinHoleEdge  float64
+	MinHoleWeb   float64
+	MinSilkWidth float64
+}
+
+// DefaultRules returns limits that suit most fab houses' FR4 panels
+func DefaultRules() Rules {
+	return Rules{
+		MinHoleEdge:  1.0,
+		MinHoleWeb:   1.0,
+		MinSilkWidth: 0.15,
+	}
+}
+
+// Violation is a single broken rule, located at (X,Y) on the panel
+type Violation struct {
+	Rule     string  `json:"rule"`
+	Message  string  `json:"message"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Measured float64 `json:"measured"`
+	Limit    float64 `json:"limit"`
+}
+
+// Report is the result of checking a panel
+type Report struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Passed reports whether the panel broke no rules
+func (r Report) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+func (r *Report) add(rule string, x, y, measured, limit float64, format string, args ...interface{}) {
+	r.Violations = append(r.Violations, Violation{
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		X:        x,
+		Y:        y,
+		Measured: measured,
+		Limit:    limit,
+	})
+}
+
+// checker holds what the individual checks need to know about a panel
+type checker struct {
+	board *eagle.Eagle
+	spec  panel.Panel
+	rules Rules
+	outer []geometry.Point
+	// cutouts are the slots and cutouts milled on the Dimension and
+	// Milling layers
+	cutouts [][]geometry.Point
+	report  Report
+}
+
+// Check checks the Plain section of a panel board against the design rules.
+// The panel format supplies the mounting holes and rails.
+func Check(board *eagle.Eagle, spec panel.Panel, rules Rules) (Report, error) {
+	bo, err := outline.FindBoardOutline(board)
+	if err != nil {
+		return Report{}, fmt.Errorf("can't find panel outline: %v", err)
+	}
+	c := &checker{
+		board:  board,
+		spec:   spec,
+		rules:  rules,
+		outer:  geometry.Flatten(bo.Outer, tolerance),
+		report: Report{Violations: []Violation{}},
+	}
+	cutouts, err := outline.Cutouts(board, bo)
+	if err != nil {
+		return Report{}, fmt.Errorf("can't find panel cutouts: %v", err)
+	}
+	for _, cutout := range cutouts {
+		c.cutouts = append(c.cutouts, geometry.Flatten(cutout, tolerance))
+	}
+	c.checkHoleEdges()
+	c.checkHoleRails()
+	c.checkHoleWebs()
+	c.checkCutoutWebs()
+	if err := c.checkTextHoles(); err != nil {
+		return Report{}, err
+	}
+	if err := c.checkTextOutline(); err != nil {
+		return Report{}, err
+	}
+	c.checkSilkWidths()
+	return c.report, nil
+}
+
+// isMountingHole reports whether a hole is one of the format's own mounting
+// holes, which are placed by design and exempt from some checks
+func (c *checker) isMountingHole(h eagle.Hole) bool {
+	for _, mh := range c.spec.MountingHoles() {
+		if math.Hypot(h.X-mh.X, h.Y-mh.Y) < mountingHoleTolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeDistance returns the distance from a point to the nearest panel edge
+// or cutout, negative if the point isn't on the panel at all
+func (c *checker) edgeDistance(p geometry.Point) float64 {
+	distance := geometry.PolygonDistance(p, c.outer)
+	onPanel := geometry.PointInPolygon(p, c.outer)
+	for _, cutout := range c.cutouts {
+		distance = math.Min(distance, geometry.PolygonDistance(p, cutout))
+		if geometry.PointInPolygon(p, cutout) {
+			onPanel = false
+		}
+	}
+	if !onPanel {
+		return -distance
+	}
+	return distance
+}
+
+func (c *checker) checkHoleEdges() {
+	for _, h := range c.board.Board.Plain.Holes {
+		if c.isMountingHole(h) {
+			continue
+		}
+		web := c.edgeDistance(geometry.Point{X: h.X, Y: h.Y}) - h.Drill/2
+		if web < c.rules.MinHoleEdge {
+			c.report.add(RuleHoleEdge, h.X, h.Y, web, c.rules.MinHoleEdge,
+				"%vmm hole is %.2fmm from the panel edge or a cutout, minimum %vmm", h.Drill, web, c.rules.MinHoleEdge)
+		}
+	}
+}
+
+// checkHoleRails checks holes against the rails. panel.RailBands only covers
+// the rails from the mounting hole rows inwards, but a rail also covers the
+// panel between its holes and the panel edge, so the bands are stretched out
+// to the bottom and top edges.
+func (c *checker) checkHoleRails() {
+	// format may not have rails
+	rails, ok := c.spec.(panel.Rails)
+	if !ok {
+		return
+	}
+	bands := panel.RailBands(rails)
+	if len(bands) == 2 {
+		bands[0].Bottom = panel.BottomY(c.spec)
+		bands[1].Top = panel.TopY(c.spec)
+	}
+	for _, h := range c.board.Board.Plain.Holes {
+		if c.isMountingHole(h) {
+			continue
+		}
+		r := h.Drill / 2
+		for _, band := range bands {
+			// how far the hole reaches into the rail
+			overlap := math.Min(h.Y+r-band.Bottom, band.Top-(h.Y-r))
+			if overlap > 0 {
+				c.report.add(RuleHoleRail, h.X, h.Y, overlap, 0,
+					"%vmm hole overlaps the rail between Y=%.2f and Y=%.2f by %.2fmm", h.Drill, band.Bottom, band.Top, overlap)
+			}
+		}
+	}
+}
+
+func (c *checker) checkHoleWebs() {
+	holes := c.board.Board.Plain.Holes
+	for i := range holes {
+		for j := i + 1; j < len(holes); j++ {
+			a, b := holes[i], holes[j]
+			if c.isMountingHole(a) && c.isMountingHole(b) {
+				continue
+			}
+			web := math.Hypot(a.X-b.X, a.Y-b.Y) - a.Drill/2 - b.Drill/2
+			if web < c.rules.MinHoleWeb {
+				c.report.add(RuleHoleWeb, (a.X+b.X)/2, (a.Y+b.Y)/2, web, c.rules.MinHoleWeb,
+					"holes at (%.2f,%.2f) and (%.2f,%.2f) are %.2fmm apart, minimum %vmm", a.X, a.Y, b.X, b.Y, web, c.rules.MinHoleWeb)
+			}
+		}
+	}
+}
+
+// checkCutoutWebs checks the material between neighbouring slots and
+// cutouts. That between a hole and a slot or cutout is checked by
+// checkHoleEdges.
+func (c *checker) checkCutoutWebs() {
+	for i := range c.cutouts {
+		for j := i + 1; j < len(c.cutouts); j++ {
+			a, b := c.cutouts[i], c.cutouts[j]
+			web := geometry.PolygonsDistance(a, b)
+			if web < c.rules.MinHoleWeb {
+				ax, ay := centre(a)
+				bx, by := centre(b)
+				c.report.add(RuleHoleWeb, (ax+bx)/2, (ay+by)/2, web, c.rules.MinHoleWeb,
+					"cutouts at (%.2f,%.2f) and (%.2f,%.2f) are %.2fmm apart, minimum %vmm", ax, ay, bx, by, web, c.rules.MinHoleWeb)
+			}
+		}
+	}
+}
+
+// centre returns the centre of the bounding box of a polygon, which is
+// good enough to locate a slot or cutout in a report
+func centre(polygon []geometry.Point) (float64, float64) {
+	minX, minY, maxX, maxY := geometry.Bounds(polygon)
+	return (minX + maxX) / 2, (minY + maxY) / 2
+}
+
+// textSegments returns the line segments drawn for a text object, with a
+// single-point stroke giving a zero-length segment
+func textSegments(t eagle.Text) ([][2]geometry.Point, error) {
+	strokes, err := vectorfont.Strokes(t)
+	if err != nil {
+		return nil, fmt.Errorf("text %q: %v", t.Text, err)
+	}
+	segments := [][2]geometry.Point{}
+	for _, stroke := range strokes {
+		if len(stroke) == 1 {
+			segments = append(segments, [2]geometry.Point{stroke[0], stroke[0]})
+		}
+		for index := 1; index < len(stroke); index++ {
+			segments = append(segments, [2]geometry.Point{stroke[index-1], stroke[index]})
+		}
+	}
+	return segments, nil
+}
+
+func (c *checker) checkTextHoles() error {
+	// the areas text must avoid, as circles
+	type obstacle struct {
+		what    string
+		x, y, r float64
+	}
+	obstacles := []obstacle{}
+	for _, h := range c.board.Board.Plain.Holes {
+		obstacles = append(obstacles, obstacle{what: "hole", x: h.X, y: h.Y, r: h.Drill / 2})
+	}
+	stop := layerSet(c.board, StopLayers)
+	for _, circle := range c.board.Board.Plain.Circles {
+		if stop[circle.Layer] {
+			obstacles = append(obstacles, obstacle{what: "stop ring", x: circle.X, y: circle.Y, r: circle.Radius + circle.Width/2})
+		}
+	}
+	for _, t := range c.board.Board.Plain.Texts {
+		half := vectorfont.StrokeWidth(t) / 2
+		segments, err := textSegments(t)
+		if err != nil {
+			return err
+		}
+		for _, o := range obstacles {
+			centre := geometry.Point{X: o.x, Y: o.y}
+			distance := math.Inf(1)
+			for _, s := range segments {
+				distance = math.Min(distance, geometry.SegmentDistance(centre, s[0], s[1]))
+			}
+			if clearance := distance - half - o.r; clearance < 0 {
+				c.report.add(RuleTextHole, t.X, t.Y, clearance, 0,
+					"text %q overlaps the %s at (%.2f,%.2f)", t.Text, o.what, o.x, o.y)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *checker) checkTextOutline() error {
+	for _, t := range c.board.Board.Plain.Texts {
+		half := vectorfont.StrokeWidth(t) / 2
+		segments, err := textSegments(t)
+		if err != nil {
+			return err
+		}
+		worst := math.Inf(1)
+		for _, s := range segments {
+			worst = math.Min(worst, c.edgeDistance(s[0])-half)
+			worst = math.Min(worst, c.edgeDistance(s[1])-half)
+		}
+		if worst < 0 {
+			c.report.add(RuleTextOutline, t.X, t.Y, worst, 0,
+				"text %q runs %.2fmm off the panel or into a cutout", t.Text, -worst)
+		}
+	}
+	return nil
+}
+
+func (c *checker) checkSilkWidths() {
+	silk := layerSet(c.board, SilkLayers)
+	limit := c.rules.MinSilkWidth
+	plain := c.board.Board.Plain
+	for _, w := range plain.Wires {
+		if silk[w.Layer] && w.Width < limit {
+			c.report.add(RuleSilkWidth, (w.X1+w.X2)/2, (w.Y1+w.Y2)/2, w.Width, limit,
+				"silkscreen line from (%.2f,%.2f) to (%.2f,%.2f) is %vmm wide, minimum %vmm", w.X1, w.Y1, w.X2, w.Y2, w.Width, limit)
+		}
+	}
+	for _, circle := range plain.Circles {
+		// zero-width circles are drawn filled
+		if silk[circle.Layer] && circle.Width > 0 && circle.Width < limit {
+			c.report.add(RuleSilkWidth, circle.X, circle.Y, circle.Width, limit,
+				"silkscreen circle is %vmm wide, minimum %vmm", circle.Width, limit)
+		}
+	}
+	for _, t := range plain.Texts {
+		if width := vectorfont.StrokeWidth(t); silk[t.Layer] && width < limit {
+			c.report.add(RuleSilkWidth, t.X, t.Y, width, limit,
+				"silkscreen text %q is drawn %.3fmm wide, minimum %vmm", t.Text, width, limit)
+		}
+	}
+}
+
+// layerSet returns the numbers of those named layers the board has
+func layerSet(board *eagle.Eagle, names []string) map[int]bool {
+	layers := map[int]bool{}
+	for _, name := range names {
+		if number, ok := board.LayerNumber(name); ok {
+			layers[number] = true
+		}
+	}
+	return layers
+}
@@ -0,0 +1,246 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package drc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// testPanel is a 50x128.5mm panel with two mounting holes and 5mm rails
+type testPanel struct{}
+
+func (testPanel) MountingHoles() []panel.Point {
+	return []panel.Point{{X: 7.5, Y: 3}, {X: 7.5, Y: 125.5}}
+}
+func (testPanel) MountingHoleDiameter() float64       { return 3.2 }
+func (testPanel) Height() float64                     { return 128.5 }
+func (testPanel) Width() float64                      { return 50 }
+func (testPanel) HorizontalFit() float64              { return 0 }
+func (testPanel) CornerRadius() float64               { return 0 }
+func (testPanel) RailHeightFromMountingHole() float64 { return 5 }
+func (testPanel) MountingHoleTopY() float64           { return 125.5 }
+func (testPanel) MountingHoleBottomY() float64        { return 3 }
+
+// testBoard returns a board with the test panel's outline and mounting
+// holes, plus the given Plain objects
+func testBoard(plain eagle.Plain) *eagle.Eagle {
+	board := &eagle.Eagle{
+		Layers: []eagle.Layer{
+			{Number: 20, Name: "Dimension"},
+			{Number: 21, Name: "tPlace"},
+			{Number: 29, Name: "tStop"},
+			{Number: 46, Name: "Milling"},
+		},
+		Board: eagle.NewBoard(),
+	}
+	corners := [][2]float64{{0, 0}, {50, 0}, {50, 128.5}, {0, 128.5}}
+	for index, c := range corners {
+		next := corners[(index+1)%len(corners)]
+		plain.Wires = append(plain.Wires, eagle.Wire{X1: c[0], Y1: c[1], X2: next[0], Y2: next[1], Layer: 20})
+	}
+	for _, mh := range (testPanel{}).MountingHoles() {
+		plain.Holes = append(plain.Holes, eagle.Hole{X: mh.X, Y: mh.Y, Drill: 3.2})
+	}
+	board.Board.Plain = plain
+	return board
+}
+
+// slot returns a rectangular slot or cutout on the given layer
+func slot(layer int, x1, y1, x2, y2 float64) eagle.Polygon {
+	return eagle.Polygon{
+		Layer:    layer,
+		Vertices: []eagle.Vertex{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		plain eagle.Plain
+		want  []string
+	}{
+		{
+			name: "bare panel",
+		},
+		{
+			name:  "hole too close to the edge",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 2, Y: 64, Drill: 3}}},
+			want:  []string{RuleHoleEdge},
+		},
+		{
+			name:  "hole just clear of the edge",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 2.5, Y: 64, Drill: 3}}},
+		},
+		{
+			name: "hole too close to a slot",
+			plain: eagle.Plain{
+				Holes:    []eagle.Hole{{X: 25, Y: 64, Drill: 3}},
+				Polygons: []eagle.Polygon{slot(20, 27, 60, 30, 68)},
+			},
+			want: []string{RuleHoleEdge},
+		},
+		{
+			name: "hole too close to a milled cutout",
+			plain: eagle.Plain{
+				Holes:    []eagle.Hole{{X: 25, Y: 64, Drill: 3}},
+				Polygons: []eagle.Polygon{slot(46, 27, 60, 30, 68)},
+			},
+			want: []string{RuleHoleEdge},
+		},
+		{
+			name: "hole clear of a milled cutout",
+			plain: eagle.Plain{
+				Holes:    []eagle.Hole{{X: 25, Y: 64, Drill: 3}},
+				Polygons: []eagle.Polygon{slot(46, 27.5, 60, 30, 68)},
+			},
+		},
+		{
+			name:  "hole overlapping the bottom rail",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 25, Y: 9, Drill: 3}}},
+			want:  []string{RuleHoleRail},
+		},
+		{
+			name:  "hole just clear of the bottom rail",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 25, Y: 9.6, Drill: 3}}},
+		},
+		{
+			name:  "hole between the bottom edge and the mounting holes",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 25, Y: 1.7, Drill: 1}}},
+			want:  []string{RuleHoleRail},
+		},
+		{
+			name:  "hole between the top edge and the mounting holes",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 25, Y: 126.8, Drill: 1}}},
+			want:  []string{RuleHoleRail},
+		},
+		{
+			name:  "holes too close together",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 20, Y: 60, Drill: 3}, {X: 23.5, Y: 60, Drill: 3}}},
+			want:  []string{RuleHoleWeb},
+		},
+		{
+			name:  "holes far enough apart",
+			plain: eagle.Plain{Holes: []eagle.Hole{{X: 20, Y: 60, Drill: 3}, {X: 24.5, Y: 60, Drill: 3}}},
+		},
+		{
+			name:  "slots too close together",
+			plain: eagle.Plain{Polygons: []eagle.Polygon{slot(20, 20, 60, 22, 68), slot(20, 22.5, 60, 24.5, 68)}},
+			want:  []string{RuleHoleWeb},
+		},
+		{
+			name:  "slot too close to a milled cutout",
+			plain: eagle.Plain{Polygons: []eagle.Polygon{slot(20, 20, 60, 22, 68), slot(46, 22.5, 60, 24.5, 68)}},
+			want:  []string{RuleHoleWeb},
+		},
+		{
+			name:  "slots far enough apart",
+			plain: eagle.Plain{Polygons: []eagle.Polygon{slot(20, 20, 60, 22, 68), slot(46, 23, 60, 25, 68)}},
+		},
+		{
+			name: "text over a hole",
+			plain: eagle.Plain{
+				Holes: []eagle.Hole{{X: 25, Y: 64, Drill: 3}},
+				Texts: []eagle.Text{{Text: "X", X: 25, Y: 64, Size: 2, Layer: 21, Align: "center"}},
+			},
+			want: []string{RuleTextHole},
+		},
+		{
+			name: "text over a stop ring",
+			plain: eagle.Plain{
+				Circles: []eagle.Circle{{X: 25, Y: 64, Radius: 3, Layer: 29}},
+				Texts:   []eagle.Text{{Text: "X", X: 25, Y: 61.5, Size: 2, Layer: 21, Align: "center"}},
+			},
+			want: []string{RuleTextHole},
+		},
+		{
+			name: "text clear of holes",
+			plain: eagle.Plain{
+				Holes: []eagle.Hole{{X: 25, Y: 64, Drill: 3}},
+				Texts: []eagle.Text{{Text: "X", X: 25, Y: 70, Size: 2, Layer: 21, Align: "center"}},
+			},
+		},
+		{
+			name:  "text running off the panel",
+			plain: eagle.Plain{Texts: []eagle.Text{{Text: "WIDE", X: 48, Y: 64, Size: 2, Layer: 21}}},
+			want:  []string{RuleTextOutline},
+		},
+		{
+			name:  "text within the panel",
+			plain: eagle.Plain{Texts: []eagle.Text{{Text: "WIDE", X: 38, Y: 64, Size: 2, Layer: 21}}},
+		},
+		{
+			name: "thin silkscreen",
+			plain: eagle.Plain{
+				Wires:   []eagle.Wire{{X1: 10, Y1: 20, X2: 40, Y2: 20, Width: 0.1, Layer: 21}},
+				Circles: []eagle.Circle{{X: 25, Y: 40, Radius: 5, Width: 0.1, Layer: 21}},
+				Texts:   []eagle.Text{{Text: "X", X: 25, Y: 100, Size: 1, Layer: 21}},
+			},
+			want: []string{RuleSilkWidth, RuleSilkWidth, RuleSilkWidth},
+		},
+		{
+			name: "wide enough silkscreen",
+			plain: eagle.Plain{
+				Wires: []eagle.Wire{{X1: 10, Y1: 20, X2: 40, Y2: 20, Width: 0.15, Layer: 21}},
+				// zero-width circles are drawn filled
+				Circles: []eagle.Circle{{X: 25, Y: 40, Radius: 5, Layer: 21}},
+				Texts:   []eagle.Text{{Text: "X", X: 25, Y: 100, Size: 2, Layer: 21}},
+			},
+		},
+	}
+	for _, test := range tests {
+		report, err := Check(testBoard(test.plain), testPanel{}, DefaultRules())
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := []string{}
+		for _, v := range report.Violations {
+			got = append(got, v.Rule)
+		}
+		want := test.want
+		if want == nil {
+			want = []string{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got violations %v, want %v", test.name, report.Violations, want)
+		}
+		if report.Passed() != (len(want) == 0) {
+			t.Errorf("%s: Passed() is %v with %d violations", test.name, report.Passed(), len(got))
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	board := testBoard(eagle.Plain{Texts: []eagle.Text{{Text: "X", Size: 2, Layer: 21, Rotate: "Rbogus"}}})
+	if _, err := Check(board, testPanel{}, DefaultRules()); err == nil || !strings.Contains(err.Error(), "Rbogus") {
+		t.Errorf("expected an invalid rotation error, got %v", err)
+	}
+	board = testBoard(eagle.Plain{})
+	board.Board.Plain.Wires = board.Board.Plain.Wires[:3]
+	if _, err := Check(board, testPanel{}, DefaultRules()); err == nil {
+		t.Errorf("expected an error for a panel without a closed outline")
+	}
+}
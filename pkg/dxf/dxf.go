@@ -0,0 +1,273 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package dxf reads the outlines from simple 2D ASCII DXF drawings, as
+// exported by most CAD and drawing programs. Only the LWPOLYLINE, LINE, ARC
+// and CIRCLE entities are understood; anything else is ignored.
+package dxf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+const (
+	// JoinTolerance is how close, in drawing units, the ends of two lines or
+	// arcs must be for them to be considered joined
+	JoinTolerance = 1e-3
+)
+
+// insunits maps DXF $INSUNITS codes to millimetres per drawing unit.
+// Unitless drawings are assumed to be in millimetres.
+var insunits = map[int]float64{
+	0: 1.0,    // unitless
+	1: 25.4,   // inches
+	2: 304.8,  // feet
+	4: 1.0,    // millimetres
+	5: 10.0,   // centimetres
+	6: 1000.0, // metres
+}
+
+// pair is a single DXF group code and value
+type pair struct {
+	code  int
+	value string
+}
+
+// Drawing holds the closed outlines found in a DXF file, in millimetres
+type Drawing struct {
+	// Loops are closed outlines, as Eagle-style polygon vertices
+	Loops [][]geometry.Vertex
+}
+
+// LoadFile reads a DXF file
+func LoadFile(filename string) (*Drawing, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a DXF drawing. Closed polylines and circles each become a
+// loop; lines, arcs and open polylines are joined end to end into loops. It
+// is an error for any of them to remain unjoined.
+func Parse(r io.Reader) (*Drawing, error) {
+	pairs, err := readPairs(r)
+	if err != nil {
+		return nil, err
+	}
+	scale := 1.0
+	d := &Drawing{}
+	loose := []geometry.Edge{}
+	section := ""
+	for index := 0; index < len(pairs); index++ {
+		p := pairs[index]
+		if p.code == 9 && p.value == "$INSUNITS" && index+1 < len(pairs) {
+			code, _ := strconv.Atoi(pairs[index+1].value)
+			s, ok := insunits[code]
+			if !ok {
+				return nil, fmt.Errorf("unsupported $INSUNITS value %d", code)
+			}
+			scale = s
+			continue
+		}
+		if p.code != 0 {
+			continue
+		}
+		if p.value == "SECTION" && index+1 < len(pairs) && pairs[index+1].code == 2 {
+			section = pairs[index+1].value
+			continue
+		}
+		// block definitions and the like aren't part of the drawing itself
+		if section != "ENTITIES" {
+			continue
+		}
+		// gather the entity's own group codes, up to the next entity
+		end := index + 1
+		for end < len(pairs) && pairs[end].code != 0 {
+			end++
+		}
+		body := pairs[index+1 : end]
+		switch p.value {
+		case "LWPOLYLINE":
+			vertices, closed, err := lwpolyline(body)
+			if err != nil {
+				return nil, err
+			}
+			if closed {
+				d.Loops = append(d.Loops, vertices)
+			} else {
+				for i := 0; i+1 < len(vertices); i++ {
+					v, next := vertices[i], vertices[i+1]
+					loose = append(loose, geometry.Edge{X1: v.X, Y1: v.Y, X2: next.X, Y2: next.Y, Curve: v.Curve})
+				}
+			}
+		case "CIRCLE":
+			v, err := values(body, 10, 20, 40)
+			if err != nil {
+				return nil, fmt.Errorf("CIRCLE: %v", err)
+			}
+			d.Loops = append(d.Loops, []geometry.Vertex{
+				{X: v[10] + v[40], Y: v[20], Curve: 180},
+				{X: v[10] - v[40], Y: v[20], Curve: 180},
+			})
+		case "LINE":
+			v, err := values(body, 10, 20, 11, 21)
+			if err != nil {
+				return nil, fmt.Errorf("LINE: %v", err)
+			}
+			loose = append(loose, geometry.Edge{X1: v[10], Y1: v[20], X2: v[11], Y2: v[21]})
+		case "ARC":
+			v, err := values(body, 10, 20, 40, 50, 51)
+			if err != nil {
+				return nil, fmt.Errorf("ARC: %v", err)
+			}
+			sweep := math.Mod(v[51]-v[50], 360)
+			if sweep <= 0 {
+				sweep += 360
+			}
+			start, end := v[50]*math.Pi/180, v[51]*math.Pi/180
+			loose = append(loose, geometry.Edge{
+				X1: v[10] + v[40]*math.Cos(start), Y1: v[20] + v[40]*math.Sin(start),
+				X2: v[10] + v[40]*math.Cos(end), Y2: v[20] + v[40]*math.Sin(end),
+				Curve: sweep,
+			})
+		}
+		index = end - 1
+	}
+	joined, err := geometry.JoinLoops(loose, JoinTolerance)
+	if err != nil {
+		return nil, err
+	}
+	d.Loops = append(d.Loops, joined...)
+	for _, loop := range d.Loops {
+		for index := range loop {
+			loop[index].X *= scale
+			loop[index].Y *= scale
+		}
+	}
+	return d, nil
+}
+
+// Largest returns the loop enclosing the greatest area, running
+// counter-clockwise, or nil if the drawing has no loops. This is normally the
+// outside of a part.
+func (d *Drawing) Largest() []geometry.Vertex {
+	largest, _ := geometry.SplitLoops(d.Loops)
+	return largest
+}
+
+// readPairs splits a DXF file into its group code and value pairs
+func readPairs(r io.Reader) ([]pair, error) {
+	pairs := []pair{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		codeText := strings.TrimSpace(scanner.Text())
+		if !scanner.Scan() {
+			break
+		}
+		line++
+		code, err := strconv.Atoi(codeText)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid group code %q (binary DXF files are not supported)", line-1, codeText)
+		}
+		pairs = append(pairs, pair{code: code, value: strings.TrimSpace(scanner.Text())})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// values extracts the numeric values of the given group codes from an
+// entity, all of which must be present
+func values(body []pair, codes ...int) (map[int]float64, error) {
+	v := map[int]float64{}
+	for _, p := range body {
+		for _, code := range codes {
+			if p.code != code {
+				continue
+			}
+			f, err := strconv.ParseFloat(p.value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("group code %d: %v", code, err)
+			}
+			v[code] = f
+		}
+	}
+	for _, code := range codes {
+		if _, ok := v[code]; !ok {
+			return nil, fmt.Errorf("missing group code %d", code)
+		}
+	}
+	return v, nil
+}
+
+// lwpolyline extracts the vertices of a lightweight polyline. Each vertex
+// has an X (10) and Y (20) coordinate and an optional bulge (42), the
+// tangent of a quarter of the angle swept by the edge to the next vertex.
+func lwpolyline(body []pair) ([]geometry.Vertex, bool, error) {
+	vertices := []geometry.Vertex{}
+	closed := false
+	for _, p := range body {
+		if p.code == 70 {
+			flags, err := strconv.Atoi(p.value)
+			if err != nil {
+				return nil, false, fmt.Errorf("LWPOLYLINE: invalid flags %q", p.value)
+			}
+			closed = flags&1 != 0
+			continue
+		}
+		if p.code != 10 && p.code != 20 && p.code != 42 {
+			continue
+		}
+		f, err := strconv.ParseFloat(p.value, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("LWPOLYLINE: group code %d: %v", p.code, err)
+		}
+		switch p.code {
+		case 10:
+			vertices = append(vertices, geometry.Vertex{X: f})
+		case 20:
+			if len(vertices) > 0 {
+				vertices[len(vertices)-1].Y = f
+			}
+		case 42:
+			if len(vertices) > 0 {
+				vertices[len(vertices)-1].Curve = 4 * math.Atan(f) * 180 / math.Pi
+			}
+		}
+	}
+	if len(vertices) < 2 {
+		return nil, false, fmt.Errorf("LWPOLYLINE: need at least two vertices")
+	}
+	return vertices, closed, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package dxf
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// drawing wraps entities in the sections of a minimal DXF file, with an
+// optional $INSUNITS header
+func drawing(insunits string, entities ...string) string {
+	header := ""
+	if insunits != "" {
+		header = "0\nSECTION\n2\nHEADER\n9\n$INSUNITS\n70\n" + insunits + "\n0\nENDSEC\n"
+	}
+	return header + "0\nSECTION\n2\nENTITIES\n" + strings.Join(entities, "") + "0\nENDSEC\n0\nEOF\n"
+}
+
+func line(x1, y1, x2, y2 string) string {
+	return "0\nLINE\n8\n0\n10\n" + x1 + "\n20\n" + y1 + "\n11\n" + x2 + "\n21\n" + y2 + "\n"
+}
+
+func arc(x, y, r, start, end string) string {
+	return "0\nARC\n8\n0\n10\n" + x + "\n20\n" + y + "\n40\n" + r + "\n50\n" + start + "\n51\n" + end + "\n"
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func sameLoop(t *testing.T, got, want []geometry.Vertex) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d vertices %v, want %d %v", len(got), got, len(want), want)
+	}
+	for index := range got {
+		g, w := got[index], want[index]
+		if !near(g.X, w.X) || !near(g.Y, w.Y) || !near(g.Curve, w.Curve) {
+			t.Errorf("vertex %d: got %+v, want %+v", index, g, w)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	d, err := LoadFile("../../enclosures/spec-test-angled.dxf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Loops) != 2 {
+		t.Fatalf("got %d loops, want the outline and a circle", len(d.Loops))
+	}
+	// a bulge of -0.05 sweeps 4*atan(0.05) clockwise
+	bulge := -4 * math.Atan(0.05) * 180 / math.Pi
+	sameLoop(t, d.Largest(), []geometry.Vertex{
+		{X: 0, Y: 0}, {X: 150, Y: 0}, {X: 150, Y: 60, Curve: bulge}, {X: 0, Y: 80},
+	})
+	sameLoop(t, d.Loops[1], []geometry.Vertex{
+		{X: 80, Y: 30, Curve: 180}, {X: 70, Y: 30, Curve: 180},
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		dxf  string
+		want []geometry.Vertex
+	}{
+		{
+			name: "lines in any order and direction",
+			dxf: drawing("",
+				line("10", "0", "10", "5"),
+				line("0", "0", "10", "0"),
+				line("0", "5", "0", "0"),
+				line("0", "5", "10", "5"),
+			),
+			want: []geometry.Vertex{{X: 10, Y: 0}, {X: 10, Y: 5}, {X: 0, Y: 5}, {X: 0, Y: 0}},
+		},
+		{
+			name: "clockwise loop",
+			dxf: drawing("4",
+				line("0", "0", "0", "5"),
+				line("0", "5", "10", "5"),
+				line("10", "5", "10", "0"),
+				line("10", "0", "0", "0"),
+			),
+			want: []geometry.Vertex{{X: 10, Y: 0}, {X: 10, Y: 5}, {X: 0, Y: 5}, {X: 0, Y: 0}},
+		},
+		{
+			name: "inches",
+			dxf: drawing("1",
+				line("0", "0", "1", "0"),
+				line("1", "0", "1", "1"),
+				line("1", "1", "0", "0"),
+			),
+			want: []geometry.Vertex{{X: 0, Y: 0}, {X: 25.4, Y: 0}, {X: 25.4, Y: 25.4}},
+		},
+		{
+			// the arc runs from 270 degrees through 0 to 90 degrees, so its
+			// sweep wraps around
+			name: "arc sweep wrapping past zero degrees",
+			dxf: drawing("",
+				arc("10", "5", "5", "270", "90"),
+				line("10", "10", "0", "10"),
+				line("0", "10", "0", "0"),
+				line("0", "0", "10", "0"),
+			),
+			want: []geometry.Vertex{{X: 10, Y: 0, Curve: 180}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}},
+		},
+		{
+			name: "open polylines joined end to end",
+			dxf: drawing("",
+				"0\nLWPOLYLINE\n90\n3\n70\n0\n10\n0\n20\n0\n10\n10\n20\n0\n42\n1\n10\n10\n20\n10\n",
+				"0\nLWPOLYLINE\n90\n2\n70\n0\n10\n10\n20\n10\n10\n0\n20\n0\n",
+			),
+			want: []geometry.Vertex{{X: 10, Y: 0, Curve: 180}, {X: 10, Y: 10}, {X: 0, Y: 0}},
+		},
+		{
+			name: "blocks are ignored",
+			dxf: "0\nSECTION\n2\nBLOCKS\n" + line("0", "0", "99", "99") + "0\nENDSEC\n" + drawing("",
+				"0\nLWPOLYLINE\n90\n3\n70\n1\n10\n0\n20\n0\n10\n4\n20\n0\n10\n0\n20\n3\n",
+				"0\nTEXT\n10\n1\n20\n1\n1\nhello\n",
+			),
+			want: []geometry.Vertex{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 3}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := Parse(strings.NewReader(test.dxf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Loops) != 1 {
+				t.Fatalf("got %d loops, want 1", len(d.Loops))
+			}
+			got := d.Largest()
+			// loops may start anywhere, so rotate to the wanted start
+			for index, v := range got {
+				if near(v.X, test.want[0].X) && near(v.Y, test.want[0].Y) {
+					got = append(got[index:], got[:index]...)
+					break
+				}
+			}
+			sameLoop(t, got, test.want)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		dxf  string
+	}{
+		{name: "binary", dxf: "AutoCAD Binary DXF\n\x1a\n"},
+		{name: "unknown units", dxf: drawing("3", line("0", "0", "1", "1"))},
+		{name: "unjoined line", dxf: drawing("", line("0", "0", "1", "1"))},
+		{name: "bad number", dxf: drawing("", line("0", "zero", "1", "1"))},
+		{name: "missing group code", dxf: drawing("", "0\nCIRCLE\n10\n1\n20\n1\n")},
+		{name: "one vertex", dxf: drawing("", "0\nLWPOLYLINE\n70\n1\n10\n0\n20\n0\n")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(test.dxf)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
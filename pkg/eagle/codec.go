@@ -0,0 +1,630 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Preserved holds whatever is read from an element that this package
+// doesn't otherwise model, along with the order the element's children
+// appeared in and which of its attributes were present but empty. It is
+// embedded in every element type so that a load, modify and write cycle
+// neither loses nor reorders anything.
+//
+// encoding/xml can't do that by itself, so Eagle files are read and written
+// by the codec in this file. It follows the usual xml struct tags, except
+// that:
+//
+//   - children are written back in the order they were read, with any new
+//     ones following the last of their kind
+//   - an omitempty element or container (eg. "classes>class,omitempty") is
+//     written if it was read, even if it is now empty
+//   - an omitempty attribute that was read with an empty or zero value is
+//     written back that way, as Eagle's default for some attributes (eg.
+//     text ratio) isn't zero
+//   - anything else found in a container element (eg. "classes" in
+//     "classes>class") is kept, and written back in the same place
+type Preserved struct {
+	UnknownAttrs []xml.Attr   `xml:",any,attr"`
+	Unknown      []RawElement `xml:",any"`
+	order        []string
+	emptyAttrs   []string
+	containers   map[string]*container
+}
+
+// container holds whatever was read from a container element besides the
+// elements it is modelled as holding
+type container struct {
+	attrs   []xml.Attr
+	unknown []RawElement
+	order   []string
+}
+
+// readEmpty reports whether a named attribute was read with an empty value
+func (p Preserved) readEmpty(name string) bool {
+	for _, attr := range p.emptyAttrs {
+		if attr == name {
+			return true
+		}
+	}
+	return false
+}
+
+type fieldKind int
+
+const (
+	attrField fieldKind = iota
+	anyAttrField
+	charDataField
+	elementField
+	anyElementField
+)
+
+// fieldInfo describes how one struct field maps to XML
+type fieldInfo struct {
+	index     []int
+	kind      fieldKind
+	name      string
+	container string
+	omitEmpty bool
+}
+
+// typeInfo describes how a struct type maps to XML
+type typeInfo struct {
+	fields    []fieldInfo
+	preserved []int
+}
+
+var (
+	typeInfos     sync.Map
+	preservedType = reflect.TypeOf(Preserved{})
+	rawType       = reflect.TypeOf(RawElement{})
+)
+
+func getTypeInfo(t reflect.Type) *typeInfo {
+	if ti, found := typeInfos.Load(t); found {
+		return ti.(*typeInfo)
+	}
+	ti := &typeInfo{}
+	ti.addFields(t, nil)
+	typeInfos.Store(t, ti)
+	return ti
+}
+
+func (ti *typeInfo) addFields(t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fi := fieldInfo{index: append(append([]int{}, index...), i), name: field.Name}
+		if field.Anonymous && field.Type == preservedType {
+			ti.preserved = fi.index
+			ti.addFields(field.Type, fi.index)
+			continue
+		}
+		tag := field.Tag.Get("xml")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+		flags := strings.Split(tag, ",")
+		if flags[0] != "" {
+			fi.name = flags[0]
+		}
+		var isAttr, isAny bool
+		fi.kind = elementField
+		for _, flag := range flags[1:] {
+			switch flag {
+			case "attr":
+				isAttr = true
+			case "any":
+				isAny = true
+			case "chardata":
+				fi.kind = charDataField
+			case "omitempty":
+				fi.omitEmpty = true
+			}
+		}
+		switch {
+		case isAttr && isAny:
+			fi.kind = anyAttrField
+		case isAttr:
+			fi.kind = attrField
+		case isAny:
+			fi.kind = anyElementField
+		}
+		if path := strings.Split(fi.name, ">"); len(path) == 2 {
+			fi.container, fi.name = path[0], path[1]
+		}
+		ti.fields = append(ti.fields, fi)
+	}
+}
+
+// field finds the first field of a kind with a given name, or of a kind
+// alone if name is empty
+func (ti *typeInfo) field(kind fieldKind, name string) *fieldInfo {
+	for index := range ti.fields {
+		fi := &ti.fields[index]
+		if fi.kind == kind && (name == "" || fi.name == name) {
+			return fi
+		}
+	}
+	return nil
+}
+
+// child finds the index of the field that holds a child element, which may
+// be a container of the field's elements, or -1 if there is none
+func (ti *typeInfo) child(name string) int {
+	for index, fi := range ti.fields {
+		if fi.kind != elementField {
+			continue
+		}
+		if fi.container == name || fi.container == "" && fi.name == name {
+			return index
+		}
+	}
+	if fi := ti.field(anyElementField, ""); fi != nil {
+		return ti.indexOf(fi)
+	}
+	return -1
+}
+
+func (ti *typeInfo) indexOf(fi *fieldInfo) int {
+	for index := range ti.fields {
+		if &ti.fields[index] == fi {
+			return index
+		}
+	}
+	return -1
+}
+
+// preservedIn returns the Preserved embedded in v, which must be
+// addressable, if its type has one
+func (ti *typeInfo) preservedIn(v reflect.Value) *Preserved {
+	if ti.preserved == nil {
+		return nil
+	}
+	return v.FieldByIndex(ti.preserved).Addr().Interface().(*Preserved)
+}
+
+// preservedOf returns a copy of the Preserved embedded in v, or an empty one
+// if its type has none
+func (ti *typeInfo) preservedOf(v reflect.Value) Preserved {
+	if ti.preserved == nil {
+		return Preserved{}
+	}
+	return v.FieldByIndex(ti.preserved).Interface().(Preserved)
+}
+
+// decodeElement decodes the element opened by start into v, which must be
+// an addressable struct
+func decodeElement(d *xml.Decoder, start xml.StartElement, v reflect.Value) error {
+	ti := getTypeInfo(v.Type())
+	var emptyAttrs []string
+	var containers map[string]*container
+	for _, attr := range start.Attr {
+		if fi := ti.field(attrField, attr.Name.Local); fi != nil {
+			fv := v.FieldByIndex(fi.index)
+			if err := setValue(fv, attr.Value); err != nil {
+				return err
+			}
+			if isEmpty(fv) {
+				emptyAttrs = append(emptyAttrs, fi.name)
+			}
+		} else if fi := ti.field(anyAttrField, ""); fi != nil {
+			attrs := v.FieldByIndex(fi.index)
+			attrs.Set(reflect.Append(attrs, reflect.ValueOf(attr)))
+		}
+	}
+	var order []string
+	var text []byte
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			order = append(order, token.Name.Local)
+			index := ti.child(token.Name.Local)
+			if index < 0 {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			fi := ti.fields[index]
+			fv := v.FieldByIndex(fi.index)
+			if fi.container != "" {
+				var c *container
+				if c, err = decodeContainer(d, token, fi.name, fv); c != nil {
+					if containers == nil {
+						containers = map[string]*container{}
+					}
+					containers[fi.container] = c
+				}
+			} else {
+				err = decodeValue(d, token, fv)
+			}
+			if err != nil {
+				return err
+			}
+		case xml.CharData:
+			text = append(text, token...)
+		case xml.EndElement:
+			if fi := ti.field(charDataField, ""); fi != nil {
+				if err := setValue(v.FieldByIndex(fi.index), string(text)); err != nil {
+					return err
+				}
+			}
+			if preserved := ti.preservedIn(v); preserved != nil {
+				preserved.order = order
+				preserved.emptyAttrs = emptyAttrs
+				preserved.containers = containers
+			}
+			return nil
+		}
+	}
+}
+
+// decodeContainer decodes the name elements within the container element
+// opened by start into v. Anything else found in the container is returned,
+// or nil if there is nothing else.
+func decodeContainer(d *xml.Decoder, start xml.StartElement, name string, v reflect.Value) (*container, error) {
+	c := &container{attrs: start.Attr}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			c.order = append(c.order, token.Name.Local)
+			if token.Name.Local == name {
+				err = decodeValue(d, token, v)
+			} else {
+				var raw RawElement
+				err = d.DecodeElement(&raw, &token)
+				c.unknown = append(c.unknown, raw)
+			}
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if len(c.attrs) == 0 && len(c.unknown) == 0 {
+				return nil, nil
+			}
+			return c, nil
+		}
+	}
+}
+
+// decodeValue decodes an element into v, appending to v if it is a slice
+func decodeValue(d *xml.Decoder, start xml.StartElement, v reflect.Value) error {
+	switch {
+	case v.Type() == rawType:
+		return d.DecodeElement(v.Addr().Interface(), &start)
+	case v.Kind() == reflect.Slice:
+		item := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(d, start, item); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, item))
+		return nil
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(d, start, v.Elem())
+	case v.Kind() == reflect.Struct:
+		return decodeElement(d, start, v)
+	}
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	return setValue(v, text)
+}
+
+// setValue parses an attribute or character data value into v
+func setValue(v reflect.Value, text string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if text == "" {
+			v.SetInt(0)
+			return nil
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(text), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(value)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if text == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(value)
+		return nil
+	case reflect.Bool:
+		if text == "" {
+			v.SetBool(false)
+			return nil
+		}
+		value, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return err
+		}
+		v.SetBool(value)
+		return nil
+	}
+	return fmt.Errorf("eagle: cannot decode %q into %s", text, v.Type())
+}
+
+// formatValue formats v as an attribute or character data value, in the
+// same way as encoding/xml
+func formatValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("eagle: cannot encode %s", v.Type())
+}
+
+// isEmpty reports whether v has nothing in it that would be written out
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isEmpty(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// encodeElement writes v, a struct, as an element with the given name
+func encodeElement(enc *xml.Encoder, name string, v reflect.Value) error {
+	ti := getTypeInfo(v.Type())
+	preserved := ti.preservedOf(v)
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	var text string
+	for _, fi := range ti.fields {
+		fv := v.FieldByIndex(fi.index)
+		switch fi.kind {
+		case attrField:
+			if fi.omitEmpty && isEmpty(fv) && !preserved.readEmpty(fi.name) {
+				continue
+			}
+			value, err := formatValue(fv)
+			if err != nil {
+				return err
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: fi.name}, Value: value})
+		case anyAttrField:
+			start.Attr = append(start.Attr, fv.Interface().([]xml.Attr)...)
+		case charDataField:
+			value, err := formatValue(fv)
+			if err != nil {
+				return err
+			}
+			text = value
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	if err := encodeChildren(enc, ti, v, preserved); err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// childQueue holds the children still to be written for one field
+type childQueue struct {
+	field     *fieldInfo
+	value     reflect.Value
+	items     []reflect.Value
+	container *container
+}
+
+func newChildQueue(fi *fieldInfo, v reflect.Value, preserved Preserved) *childQueue {
+	q := &childQueue{field: fi, value: v}
+	switch {
+	case fi.container != "":
+		q.items = []reflect.Value{v}
+		q.container = preserved.containers[fi.container]
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			q.items = append(q.items, v.Index(i))
+		}
+	case v.Kind() == reflect.Ptr:
+		if !v.IsNil() {
+			q.items = []reflect.Value{v.Elem()}
+		}
+	default:
+		q.items = []reflect.Value{v}
+	}
+	return q
+}
+
+// next writes the next child in the queue, if there is one
+func (q *childQueue) next(enc *xml.Encoder) error {
+	if len(q.items) == 0 {
+		return nil
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	if q.field.container == "" {
+		return encodeValue(enc, q.field.name, item)
+	}
+	c := q.container
+	if c == nil {
+		c = &container{}
+	}
+	start := xml.StartElement{Name: xml.Name{Local: q.field.container}, Attr: c.attrs}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	// as for any other element, new children follow the last of their kind
+	// and anything else stays where it was
+	last := -1
+	for position, name := range c.order {
+		if name == q.field.name {
+			last = position
+		}
+	}
+	written, unknown := 0, 0
+	for position, name := range c.order {
+		if name != q.field.name {
+			if err := encodeValue(enc, name, reflect.ValueOf(c.unknown[unknown])); err != nil {
+				return err
+			}
+			unknown++
+			continue
+		}
+		end := written + 1
+		if position == last {
+			end = item.Len()
+		}
+		for ; written < end && written < item.Len(); written++ {
+			if err := encodeValue(enc, q.field.name, item.Index(written)); err != nil {
+				return err
+			}
+		}
+	}
+	for ; written < item.Len(); written++ {
+		if err := encodeValue(enc, q.field.name, item.Index(written)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// rest writes all the remaining children in the queue. Children that were
+// not read from a file are left out if omitempty and empty.
+func (q *childQueue) rest(enc *xml.Encoder, read bool) error {
+	if !read && q.field.omitEmpty && isEmpty(q.value) {
+		return nil
+	}
+	for len(q.items) > 0 {
+		if err := q.next(enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeChildren writes the child elements of v in the order they were
+// read, followed by any that weren't read
+func encodeChildren(enc *xml.Encoder, ti *typeInfo, v reflect.Value, preserved Preserved) error {
+	queues := make([]*childQueue, len(ti.fields))
+	for index := range ti.fields {
+		fi := &ti.fields[index]
+		if fi.kind == elementField || fi.kind == anyElementField {
+			queues[index] = newChildQueue(fi, v.FieldByIndex(fi.index), preserved)
+		}
+	}
+	var order []int
+	last := map[int]int{}
+	for _, name := range preserved.order {
+		index := ti.child(name)
+		if index >= 0 {
+			last[index] = len(order)
+		}
+		order = append(order, index)
+	}
+	for position, index := range order {
+		if index < 0 {
+			continue
+		}
+		if err := queues[index].next(enc); err != nil {
+			return err
+		}
+		if last[index] == position {
+			if err := queues[index].rest(enc, true); err != nil {
+				return err
+			}
+		}
+	}
+	for index, q := range queues {
+		if q == nil {
+			continue
+		}
+		if _, read := last[index]; read {
+			continue
+		}
+		if err := q.rest(enc, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeValue writes a single child element
+func encodeValue(enc *xml.Encoder, name string, v reflect.Value) error {
+	switch {
+	case v.Type() == rawType:
+		raw := v.Interface().(RawElement)
+		return enc.EncodeElement(raw, xml.StartElement{Name: raw.XMLName})
+	case v.Kind() == reflect.Struct:
+		return encodeElement(enc, name, v)
+	}
+	text, err := formatValue(v)
+	if err != nil {
+		return err
+	}
+	return enc.EncodeElement(text, xml.StartElement{Name: xml.Name{Local: name}})
+}
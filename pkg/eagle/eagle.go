@@ -43,11 +43,7 @@ func LoadEagleFile(filename string) (*Eagle, error) {
 // WriteFile attempts to generate a valid Eagle XML board file from an
 // Eagle data structure.
 func (e *Eagle) WriteFile(filename string) error {
-	wrapped := &struct {
-		Eagle
-		XMLName struct{} `xml:"eagle"`
-	}{Eagle: *e}
-	xml, err := xml.MarshalIndent(wrapped, "", "  ")
+	xml, err := xml.MarshalIndent(e, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -80,6 +76,18 @@ func (e *Eagle) CloneEmpty() *Eagle {
 	return clone
 }
 
+// LayerNumber looks up the number for a named layer, reporting whether the
+// layer exists. Use this rather than LayerByName where a missing layer is not
+// fatal.
+func (e *Eagle) LayerNumber(name string) (int, bool) {
+	for _, layer := range e.Layers {
+		if layer.Name == name {
+			return layer.Number, true
+		}
+	}
+	return 0, false
+}
+
 // LayerByName attempts to find the layer number for a named layer. Eagle does
 // appear to standardise these but it's easy to do a lookup, so let's be
 // tolerant of future surprises. Aborts if the desired layer is not present,
@@ -0,0 +1,287 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// node is a generic XML element, for comparing documents
+type node struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+func parseTree(t *testing.T, filename string) *node {
+	t.Helper()
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	d := xml.NewDecoder(file)
+	root := &node{}
+	stack := []*node{root}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			break
+		}
+		top := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			child := &node{name: token.Name.Local, attrs: map[string]string{}}
+			for _, attr := range token.Attr {
+				child.attrs[attr.Name.Local] = attr.Value
+			}
+			top.children = append(top.children, child)
+			stack = append(stack, child)
+		case xml.CharData:
+			top.text += string(token)
+		case xml.EndElement:
+			top.text = strings.TrimSpace(top.text)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(root.children) != 1 {
+		t.Fatalf("%s: expected a single root element", filename)
+	}
+	return root.children[0]
+}
+
+// sameValue compares attribute values, allowing for numbers being written
+// differently, eg. "2.50" and "2.5"
+func sameValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	af, aerr := strconv.ParseFloat(a, 64)
+	bf, berr := strconv.ParseFloat(b, 64)
+	return aerr == nil && berr == nil && af == bf
+}
+
+// compareTrees describes the first difference between two XML trees, or
+// returns an empty string if they are the same
+func compareTrees(want, got *node, path string) string {
+	path += "/" + want.name
+	if want.name != got.name {
+		return fmt.Sprintf("%s: got element %q", path, got.name)
+	}
+	for name, value := range want.attrs {
+		if gotValue, found := got.attrs[name]; !found {
+			return fmt.Sprintf("%s: attribute %q missing", path, name)
+		} else if !sameValue(value, gotValue) {
+			return fmt.Sprintf("%s: attribute %q is %q, want %q", path, name, gotValue, value)
+		}
+	}
+	for name := range got.attrs {
+		if _, found := want.attrs[name]; !found {
+			return fmt.Sprintf("%s: unexpected attribute %q", path, name)
+		}
+	}
+	if want.text != got.text {
+		return fmt.Sprintf("%s: text is %q, want %q", path, got.text, want.text)
+	}
+	for index := range want.children {
+		if index >= len(got.children) {
+			return fmt.Sprintf("%s: child %d <%s> missing", path, index, want.children[index].name)
+		}
+		if diff := compareTrees(want.children[index], got.children[index], path); diff != "" {
+			return diff
+		}
+	}
+	if len(got.children) > len(want.children) {
+		return fmt.Sprintf("%s: unexpected child <%s>", path, got.children[len(want.children)].name)
+	}
+	return ""
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filenames, err := filepath.Glob("testdata/*.[bls][rbc][dlh]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		t.Fatal("no test files found")
+	}
+	for _, input := range filenames {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			e, err := LoadEagleFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output := filepath.Join(dir, filepath.Base(input))
+			if err := e.WriteFile(output); err != nil {
+				t.Fatal(err)
+			}
+			if diff := compareTrees(parseTree(t, input), parseTree(t, output), ""); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+const orderTestBoard = `<?xml version="1.0" encoding="utf-8"?>
+<eagle version="9.6.2">
+<drawing>
+<layers>
+<layer number="20" name="Dimension" color="24" fill="1" visible="yes" active="yes"/>
+</layers>
+<board>
+<plain>
+<wire x1="0" y1="0" x2="10" y2="0" width="0" layer="20" curve="0"/>
+<hole x="5" y="5" drill="3.2"/>
+<text x="1" y="1" size="2" layer="21" ratio="0">A</text>
+<wire x1="10" y1="0" x2="10" y2="10" width="0" layer="20"/>
+<circle x="5" y="5" radius="2" width="0" layer="41"/>
+</plain>
+<classes>
+</classes>
+<fusionteam huburn="a.b.c"/>
+<elements>
+</elements>
+</board>
+</drawing>
+</eagle>`
+
+// childNames lists the names of an element's children in order
+func childNames(n *node) []string {
+	names := []string{}
+	for _, child := range n.children {
+		names = append(names, child.name)
+	}
+	return names
+}
+
+func TestWriteOrder(t *testing.T) {
+	var e Eagle
+	if err := xml.Unmarshal([]byte(orderTestBoard), &e); err != nil {
+		t.Fatal(err)
+	}
+	e.Board.Plain.Wires = append(e.Board.Plain.Wires, Wire{X1: 10, Y1: 10, X2: 0, Y2: 10, Layer: 20})
+	e.Board.Plain.Rectangles = append(e.Board.Plain.Rectangles, Rectangle{X2: 1, Y2: 1, Layer: 39})
+	e.Board.Elements = append(e.Board.Elements, Element{Name: "J1", Library: "panel", Package: "JACK"})
+	e.Board.Attributes = append(e.Board.Attributes, Attribute{Name: "AUTHOR", Value: "panelgen"})
+	dir, err := ioutil.TempDir("", "eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "order.brd")
+	if err := e.WriteFile(output); err != nil {
+		t.Fatal(err)
+	}
+	board := parseTree(t, output).children[0].children[1]
+	tests := []struct {
+		node *node
+		want []string
+	}{
+		// the new wire follows the last one read, and the new rectangle follows
+		// everything read
+		{board.children[0], []string{"wire", "hole", "text", "wire", "wire", "circle", "rectangle"}},
+		// empty containers that were read are kept, unknown elements stay
+		// where they were and new containers follow everything read
+		{board, []string{"plain", "classes", "fusionteam", "elements", "attributes"}},
+	}
+	for _, test := range tests {
+		if got := childNames(test.node); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("<%s> children: got %v, want %v", test.node.name, got, test.want)
+		}
+	}
+	// zero attributes that were read are kept, as Eagle's default for text
+	// ratio is 8
+	if got := board.children[0].children[0].attrs["curve"]; got != "0" {
+		t.Errorf("wire curve: got %q, want \"0\"", got)
+	}
+	if got := board.children[0].children[2].attrs["ratio"]; got != "0" {
+		t.Errorf("text ratio: got %q, want \"0\"", got)
+	}
+	if _, found := board.children[0].children[4].attrs["curve"]; found {
+		t.Error("new wire: unexpected curve attribute")
+	}
+}
+
+const unknownTestBoard = `<?xml version="1.0" encoding="utf-8"?>
+<eagle version="9.6.2">
+<drawing units="mm">
+<layers>
+<layer number="1" name="Top" color="4" fill="1" visible="yes" active="yes"/>
+<layerset name="copper" layers="1 16"/>
+<layer number="20" name="Dimension" color="24" fill="1" visible="yes" active="yes"/>
+</layers>
+<board>
+<elements locked="no">
+<element name="J1" library="panel" package="JACK" value="" x="10" y="20"/>
+<fusionref id="abc"><note>kept</note></fusionref>
+<element name="J2" library="panel" package="JACK" value="" x="30" y="20"/>
+</elements>
+</board>
+</drawing>
+</eagle>`
+
+func TestPreserveUnknown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "input.brd")
+	if err := ioutil.WriteFile(input, []byte(unknownTestBoard), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := LoadEagleFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "output.brd")
+	if err := e.WriteFile(output); err != nil {
+		t.Fatal(err)
+	}
+	if diff := compareTrees(parseTree(t, input), parseTree(t, output), ""); diff != "" {
+		t.Error(diff)
+	}
+	// new layers follow the last one read, with unknown elements staying
+	// where they were
+	e.Layers = append(e.Layers, Layer{Number: 21, Name: "tPlace"})
+	if err := e.WriteFile(output); err != nil {
+		t.Fatal(err)
+	}
+	layers := parseTree(t, output).children[0].children[0]
+	want := []string{"layer", "layerset", "layer", "layer"}
+	if got := childNames(layers); !reflect.DeepEqual(got, want) {
+		t.Errorf("<layers> children: got %v, want %v", got, want)
+	}
+}
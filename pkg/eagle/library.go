@@ -0,0 +1,129 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import "fmt"
+
+// LoadLibraryFile attempts to read and unmarshal an Eagle XML library file.
+// The library itself is in the Library field of the returned object.
+func LoadLibraryFile(filename string) (*Eagle, error) {
+	e, err := LoadEagleFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if e.Library == nil {
+		return nil, fmt.Errorf("%s: not an Eagle library file", filename)
+	}
+	return e, nil
+}
+
+// WriteLibraryFile attempts to generate a valid Eagle XML library file from
+// an Eagle data structure. The Library field must be set.
+func (e *Eagle) WriteLibraryFile(filename string) error {
+	if e.Library == nil {
+		return fmt.Errorf("%s: no library to write", filename)
+	}
+	return e.WriteFile(filename)
+}
+
+// NewLibrary constructs a new, empty Library object
+func NewLibrary(name string) *Library {
+	return &Library{
+		Name:       name,
+		Packages:   []Package{},
+		Symbols:    []Symbol{},
+		DeviceSets: []DeviceSet{},
+	}
+}
+
+// PackageByName looks up a package by name. The returned pointer refers to
+// the package within the library, so it may be used to modify it.
+func (l *Library) PackageByName(name string) (*Package, bool) {
+	for index := range l.Packages {
+		if l.Packages[index].Name == name {
+			return &l.Packages[index], true
+		}
+	}
+	return nil, false
+}
+
+// PackageForElement looks up the package an element was placed from, in the
+// libraries embedded in the board
+func (b *Board) PackageForElement(e Element) (*Package, bool) {
+	for index := range b.Libraries {
+		library := &b.Libraries[index]
+		if library.Name != e.Library || library.Urn != e.LibraryUrn {
+			continue
+		}
+		if pkg, found := library.PackageByName(e.Package); found {
+			return pkg, true
+		}
+	}
+	return nil, false
+}
+
+// SymbolByName looks up a symbol by name. The returned pointer refers to the
+// symbol within the library, so it may be used to modify it.
+func (l *Library) SymbolByName(name string) (*Symbol, bool) {
+	for index := range l.Symbols {
+		if l.Symbols[index].Name == name {
+			return &l.Symbols[index], true
+		}
+	}
+	return nil, false
+}
+
+// DeviceSetByName looks up a deviceset by name. The returned pointer refers
+// to the deviceset within the library, so it may be used to modify it.
+func (l *Library) DeviceSetByName(name string) (*DeviceSet, bool) {
+	for index := range l.DeviceSets {
+		if l.DeviceSets[index].Name == name {
+			return &l.DeviceSets[index], true
+		}
+	}
+	return nil, false
+}
+
+// SetAttribute sets a named attribute on a technology, replacing any
+// existing value
+func (t *Technology) SetAttribute(name, value string) {
+	if index, found := attributeIndex(t.Attributes, name); found {
+		t.Attributes[index].Value = value
+		return
+	}
+	t.Attributes = append(t.Attributes, Attribute{Name: name, Value: value})
+}
+
+// SetTechnologyAttribute sets a named attribute on every technology of every
+// device in a deviceset, so that every part placed from it inherits the
+// attribute. Devices without any technologies get Eagle's default (unnamed)
+// technology added.
+func (d *DeviceSet) SetTechnologyAttribute(name, value string) {
+	for di := range d.Devices {
+		device := &d.Devices[di]
+		if len(device.Technologies) == 0 {
+			device.Technologies = []Technology{{Name: ""}}
+		}
+		for ti := range device.Technologies {
+			device.Technologies[ti].SetAttribute(name, value)
+		}
+	}
+}
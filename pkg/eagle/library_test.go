@@ -0,0 +1,104 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetTechnologyAttribute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lbr, err := LoadEagleFile("testdata/panel.lbr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resistor, found := lbr.Library.DeviceSetByName("R-US_")
+	if !found {
+		t.Fatal("no R-US_ deviceset in testdata/panel.lbr")
+	}
+	resistor.SetTechnologyAttribute("PANEL_DRILL_MM", "3.2")
+	jack, found := lbr.Library.DeviceSetByName("PJ301M-12")
+	if !found {
+		t.Fatal("no PJ301M-12 deviceset in testdata/panel.lbr")
+	}
+	jack.SetTechnologyAttribute("PANEL_HOLE_DIAMETER", "6.2")
+	filename := filepath.Join(dir, "panel.lbr")
+	if err := lbr.WriteLibraryFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadEagleFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		deviceSet  string
+		technology string
+		want       map[string]string
+	}{
+		// every technology gets the attribute, alongside any others
+		{deviceSet: "R-US_", technology: "", want: map[string]string{"PANEL_DRILL_MM": "3.2"}},
+		{deviceSet: "R-US_", technology: "_1%", want: map[string]string{"TOLERANCE": "1%", "PANEL_DRILL_MM": "3.2"}},
+		// existing attributes are replaced rather than duplicated
+		{deviceSet: "PJ301M-12", technology: "", want: map[string]string{"MPN": "PJ301M-12", "PANEL_HOLE_DIAMETER": "6.2", "PANEL_NUT": "jack"}},
+	}
+	for _, test := range tests {
+		deviceSet, found := reloaded.Library.DeviceSetByName(test.deviceSet)
+		if !found {
+			t.Fatalf("no %s deviceset after reloading", test.deviceSet)
+		}
+		var technology *Technology
+		for ti := range deviceSet.Devices[0].Technologies {
+			if deviceSet.Devices[0].Technologies[ti].Name == test.technology {
+				technology = &deviceSet.Devices[0].Technologies[ti]
+			}
+		}
+		if technology == nil {
+			t.Fatalf("%s: no technology %q after reloading", test.deviceSet, test.technology)
+		}
+		got := map[string]string{}
+		for _, attribute := range technology.Attributes {
+			if _, duplicate := got[attribute.Name]; duplicate {
+				t.Errorf("%s technology %q: duplicate attribute %s", test.deviceSet, test.technology, attribute.Name)
+			}
+			got[attribute.Name] = attribute.Value
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s technology %q: got attributes %v, want %v", test.deviceSet, test.technology, got, test.want)
+		}
+	}
+}
+
+func TestSetTechnologyAttributeWithoutTechnologies(t *testing.T) {
+	deviceSet := DeviceSet{Name: "A4", Devices: []Device{{Name: ""}}}
+	deviceSet.SetTechnologyAttribute("PANEL_DRILL_MM", "3.2")
+	want := []Technology{{Name: "", Attributes: []Attribute{{Name: "PANEL_DRILL_MM", Value: "3.2"}}}}
+	if got := deviceSet.Devices[0].Technologies; !reflect.DeepEqual(got, want) {
+		t.Errorf("got technologies %+v, want %+v", got, want)
+	}
+}
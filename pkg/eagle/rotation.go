@@ -0,0 +1,107 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rotation holds the parsed form of an Eagle "rot" attribute value, eg.
+// "R90", "MR180" or "SMR270". Eagle mirrors an object (about its own Y axis)
+// before rotating it counter-clockwise by Angle degrees. The spin flag only
+// affects how text is drawn and has no effect on coordinates.
+type Rotation struct {
+	Angle  float64
+	Mirror bool
+	Spin   bool
+}
+
+// ParseRotation parses an Eagle "rot" attribute value. An empty string is
+// valid and is equivalent to "R0".
+func ParseRotation(s string) (Rotation, error) {
+	r := Rotation{}
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return r, nil
+	}
+	for len(rest) > 0 && (rest[0] == 'S' || rest[0] == 'M') {
+		if rest[0] == 'S' {
+			r.Spin = true
+		} else {
+			r.Mirror = true
+		}
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "R") {
+		return Rotation{}, fmt.Errorf("invalid rotation %q: missing 'R' prefix", s)
+	}
+	angle, err := strconv.ParseFloat(rest[1:], 64)
+	if err != nil {
+		return Rotation{}, fmt.Errorf("invalid rotation %q: %v", s, err)
+	}
+	r.Angle = math.Mod(angle, 360.0)
+	if r.Angle < 0 {
+		r.Angle += 360.0
+	}
+	return r, nil
+}
+
+// String formats a Rotation the way Eagle expects to find it in a "rot"
+// attribute
+func (r Rotation) String() string {
+	s := ""
+	if r.Spin {
+		s += "S"
+	}
+	if r.Mirror {
+		s += "M"
+	}
+	return s + "R" + strconv.FormatFloat(r.Angle, 'f', -1, 64)
+}
+
+// Apply transforms a point (or offset) given relative to an object's origin
+// into the coordinate space the object is placed in, less the translation
+func (r Rotation) Apply(x, y float64) (float64, float64) {
+	if r.Mirror {
+		x = -x
+	}
+	radians := r.Angle * math.Pi / 180.0
+	sin, cos := math.Sincos(radians)
+	return x*cos - y*sin, x*sin + y*cos
+}
+
+// ApplyAngle transforms a polar angle, in degrees counter-clockwise from the
+// positive X axis, given relative to an object into the coordinate space the
+// object is placed in
+func (r Rotation) ApplyAngle(angle float64) float64 {
+	if r.Mirror {
+		angle = 180.0 - angle
+	}
+	return angle + r.Angle
+}
+
+// Rotation returns the parsed form of the element's rot attribute
+func (e Element) Rotation() (Rotation, error) {
+	return ParseRotation(e.Rotate)
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRotation(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Rotation
+		err  bool
+	}{
+		{s: "", want: Rotation{}},
+		{s: "R0", want: Rotation{}},
+		{s: "R90", want: Rotation{Angle: 90}},
+		{s: "MR270", want: Rotation{Angle: 270, Mirror: true}},
+		{s: "SR45", want: Rotation{Angle: 45, Spin: true}},
+		{s: "SMR180", want: Rotation{Angle: 180, Mirror: true, Spin: true}},
+		{s: "MSR180", want: Rotation{Angle: 180, Mirror: true, Spin: true}},
+		{s: " R30 ", want: Rotation{Angle: 30}},
+		{s: "R450", want: Rotation{Angle: 90}},
+		{s: "R-90", want: Rotation{Angle: 270}},
+		{s: "R12.5", want: Rotation{Angle: 12.5}},
+		{s: "90", err: true},
+		{s: "M", err: true},
+		{s: "R", err: true},
+		{s: "R90x", err: true},
+		{s: "X90", err: true},
+		{s: "RM90", err: true},
+	}
+	for _, test := range tests {
+		got, err := ParseRotation(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %+v", test.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.s, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: got %+v, want %+v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestRotationString(t *testing.T) {
+	for _, s := range []string{"R0", "R90", "MR270", "SR45", "SMR12.5"} {
+		r, err := ParseRotation(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if got := r.String(); got != s {
+			t.Errorf("%q: formatted as %q", s, got)
+		}
+	}
+}
+
+func TestRotationApply(t *testing.T) {
+	tests := []struct {
+		rot          string
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{rot: "R0", x: 1, y: 2, wantX: 1, wantY: 2},
+		{rot: "R90", x: 1, y: 2, wantX: -2, wantY: 1},
+		{rot: "R180", x: 1, y: 2, wantX: -1, wantY: -2},
+		{rot: "MR0", x: 1, y: 2, wantX: -1, wantY: 2},
+		// mirrored first, then rotated
+		{rot: "MR270", x: 1, y: 2, wantX: 2, wantY: 1},
+		// spin doesn't affect coordinates
+		{rot: "SR45", x: 1, y: 0, wantX: math.Sqrt2 / 2, wantY: math.Sqrt2 / 2},
+	}
+	for _, test := range tests {
+		r, err := ParseRotation(test.rot)
+		if err != nil {
+			t.Fatalf("%q: %v", test.rot, err)
+		}
+		x, y := r.Apply(test.x, test.y)
+		if math.Abs(x-test.wantX) > 1e-9 || math.Abs(y-test.wantY) > 1e-9 {
+			t.Errorf("%s: got (%v,%v), want (%v,%v)", test.rot, x, y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestRotationApplyAngle(t *testing.T) {
+	tests := []struct {
+		rot   string
+		angle float64
+		want  float64
+	}{
+		{rot: "R0", angle: 30, want: 30},
+		{rot: "R90", angle: 30, want: 120},
+		{rot: "MR0", angle: 30, want: 150},
+		{rot: "MR270", angle: 30, want: 420},
+		{rot: "SR45", angle: 30, want: 75},
+	}
+	for _, test := range tests {
+		r, err := ParseRotation(test.rot)
+		if err != nil {
+			t.Fatalf("%q: %v", test.rot, err)
+		}
+		got := r.ApplyAngle(test.angle)
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got angle %v, want %v", test.rot, got, test.want)
+		}
+		// the transformed angle must point the same way as a transformed
+		// point at that angle
+		radians := test.angle * math.Pi / 180
+		x, y := r.Apply(math.Cos(radians), math.Sin(radians))
+		wantX, wantY := math.Cos(got*math.Pi/180), math.Sin(got*math.Pi/180)
+		if math.Abs(x-wantX) > 1e-9 || math.Abs(y-wantY) > 1e-9 {
+			t.Errorf("%s: angle %v doesn't match the point (%v,%v)", test.rot, got, x, y)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import "fmt"
+
+// PinRef object, connecting a net or bus segment to a part pin
+type PinRef struct {
+	Part string `xml:"part,attr"`
+	Gate string `xml:"gate,attr"`
+	Pin  string `xml:"pin,attr"`
+	Preserved
+}
+
+// Junction object
+type Junction struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+	Preserved
+}
+
+// Label object, ie. a net or bus name label
+type Label struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Size   float64 `xml:"size,attr"`
+	Layer  int     `xml:"layer,attr"`
+	Font   string  `xml:"font,attr,omitempty"`
+	Ratio  int     `xml:"ratio,attr,omitempty"`
+	Rotate string  `xml:"rot,attr,omitempty"`
+	XRef   string  `xml:"xref,attr,omitempty"`
+	Align  string  `xml:"align,attr,omitempty"`
+	Preserved
+}
+
+// Segment object, ie. one connected piece of a net or bus on a sheet
+type Segment struct {
+	PinRefs   []PinRef   `xml:"pinref"`
+	Wires     []Wire     `xml:"wire"`
+	Junctions []Junction `xml:"junction"`
+	Labels    []Label    `xml:"label"`
+	Preserved
+}
+
+// Net object
+type Net struct {
+	Name     string    `xml:"name,attr"`
+	Class    string    `xml:"class,attr,omitempty"`
+	Segments []Segment `xml:"segment"`
+	Preserved
+}
+
+// Bus object
+type Bus struct {
+	Name     string    `xml:"name,attr"`
+	Segments []Segment `xml:"segment"`
+	Preserved
+}
+
+// Instance object, ie. one gate of a part placed on a sheet
+type Instance struct {
+	Part       string      `xml:"part,attr"`
+	Gate       string      `xml:"gate,attr"`
+	X          float64     `xml:"x,attr"`
+	Y          float64     `xml:"y,attr"`
+	Smashed    string      `xml:"smashed,attr,omitempty"`
+	Rotate     string      `xml:"rot,attr,omitempty"`
+	Attributes []Attribute `xml:"attribute"`
+	Preserved
+}
+
+// Sheet object
+type Sheet struct {
+	Description *Description `xml:"description,omitempty"`
+	Plain       *Plain       `xml:"plain,omitempty"`
+	Instances   []Instance   `xml:"instances>instance,omitempty"`
+	Busses      []Bus        `xml:"busses>bus,omitempty"`
+	Nets        []Net        `xml:"nets>net,omitempty"`
+	Preserved
+}
+
+// Part object. Only attributes that differ from those of the part's
+// technology are stored here; see Schematic.ResolvePart
+type Part struct {
+	Name         string      `xml:"name,attr"`
+	Library      string      `xml:"library,attr"`
+	LibraryUrn   string      `xml:"library_urn,attr,omitempty"`
+	DeviceSet    string      `xml:"deviceset,attr"`
+	Device       string      `xml:"device,attr"`
+	Package3dUrn string      `xml:"package3d_urn,attr,omitempty"`
+	Technology   string      `xml:"technology,attr,omitempty"`
+	Value        string      `xml:"value,attr,omitempty"`
+	Attributes   []Attribute `xml:"attribute"`
+	Variants     []Variant   `xml:"variant"`
+	Preserved
+}
+
+func (p Part) GetAttributes() []Attribute {
+	return p.Attributes
+}
+
+// Schematic object
+type Schematic struct {
+	XRefLabel   string       `xml:"xreflabel,attr,omitempty"`
+	XRefPart    string       `xml:"xrefpart,attr,omitempty"`
+	Description *Description `xml:"description,omitempty"`
+	Libraries   []Library    `xml:"libraries>library,omitempty"`
+	Attributes  []Attribute  `xml:"attributes>attribute,omitempty"`
+	VariantDefs []VariantDef `xml:"variantdefs>variantdef,omitempty"`
+	Classes     []Class      `xml:"classes>class,omitempty"`
+	Parts       []Part       `xml:"parts>part,omitempty"`
+	Sheets      []Sheet      `xml:"sheets>sheet,omitempty"`
+	Errors      []Approved   `xml:"errors>approved,omitempty"`
+	Preserved
+}
+
+func (s Schematic) GetAttributes() []Attribute {
+	return s.Attributes
+}
+
+// PartByName looks up a part by name, reporting whether it was found
+func (s Schematic) PartByName(name string) (Part, bool) {
+	for _, part := range s.Parts {
+		if part.Name == name {
+			return part, true
+		}
+	}
+	return Part{}, false
+}
+
+// ResolvePart returns a copy of a part with its attributes merged with
+// those of the library technology it uses, as a board element would carry
+// them. Attributes set on the part itself take precedence.
+func (s Schematic) ResolvePart(p Part) (Part, error) {
+	technology, err := s.technologyForPart(p)
+	if err != nil {
+		return Part{}, err
+	}
+	resolved := p
+	resolved.Attributes = []Attribute{}
+	for _, attribute := range technology.Attributes {
+		if _, found := attributeIndex(p.Attributes, attribute.Name); !found {
+			resolved.Attributes = append(resolved.Attributes, attribute)
+		}
+	}
+	resolved.Attributes = append(resolved.Attributes, p.Attributes...)
+	return resolved, nil
+}
+
+func (s Schematic) technologyForPart(p Part) (Technology, error) {
+	for _, library := range s.Libraries {
+		if library.Name != p.Library || library.Urn != p.LibraryUrn {
+			continue
+		}
+		for _, deviceSet := range library.DeviceSets {
+			if deviceSet.Name != p.DeviceSet {
+				continue
+			}
+			for _, device := range deviceSet.Devices {
+				if device.Name != p.Device {
+					continue
+				}
+				for _, technology := range device.Technologies {
+					if technology.Name == p.Technology {
+						return technology, nil
+					}
+				}
+				// devices without any technologies are valid
+				if len(device.Technologies) == 0 && p.Technology == "" {
+					return Technology{}, nil
+				}
+			}
+		}
+	}
+	return Technology{}, fmt.Errorf("part %q: no technology %q for device %q in deviceset %q of library %q",
+		p.Name, p.Technology, p.Device, p.DeviceSet, p.Library)
+}
+
+func attributeIndex(attributes []Attribute, name string) (int, bool) {
+	for index, attribute := range attributes {
+		if attribute.Name == name {
+			return index, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadTestSchematic(t *testing.T) Schematic {
+	t.Helper()
+	e, err := LoadEagleFile("testdata/panel.sch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Schematic == nil {
+		t.Fatal("testdata/panel.sch has no schematic")
+	}
+	return *e.Schematic
+}
+
+func TestResolvePart(t *testing.T) {
+	schematic := loadTestSchematic(t)
+	j1, _ := schematic.PartByName("J1")
+	overridden := j1
+	overridden.Attributes = []Attribute{{Name: "PANEL_HOLE_DIAMETER", Value: "8"}}
+	tests := []struct {
+		name string
+		part string
+		// for parts not taken from the schematic as-is
+		override *Part
+		want     []Attribute
+	}{
+		{
+			name: "technology attributes only",
+			part: "J1",
+			want: []Attribute{{Name: "MPN", Value: "PJ301M-12"}, {Name: "PANEL_HOLE_DIAMETER", Value: "6"}},
+		},
+		{
+			name: "part attributes added",
+			part: "J2",
+			want: []Attribute{{Name: "MPN", Value: "PJ301M-12"}, {Name: "PANEL_HOLE_DIAMETER", Value: "6"}, {Name: "PANEL_LABEL", Value: "OUT"}},
+		},
+		{
+			name:     "part attributes take precedence",
+			override: &overridden,
+			want:     []Attribute{{Name: "MPN", Value: "PJ301M-12"}, {Name: "PANEL_HOLE_DIAMETER", Value: "8"}},
+		},
+		{
+			name: "named technology",
+			part: "R1",
+			want: []Attribute{{Name: "TOLERANCE", Value: "1%"}},
+		},
+		{
+			name: "no attributes",
+			part: "GND1",
+			want: []Attribute{},
+		},
+	}
+	for _, test := range tests {
+		part, found := schematic.PartByName(test.part)
+		if test.override != nil {
+			part, found = *test.override, true
+		}
+		if !found {
+			t.Fatalf("%s: no part %s in testdata/panel.sch", test.name, test.part)
+		}
+		resolved, err := schematic.ResolvePart(part)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(resolved.Attributes, test.want) {
+			t.Errorf("%s: got attributes %v, want %v", test.name, resolved.Attributes, test.want)
+		}
+		if resolved.Name != part.Name || resolved.Value != part.Value {
+			t.Errorf("%s: resolved part is %s (%s), want %s (%s)", test.name, resolved.Name, resolved.Value, part.Name, part.Value)
+		}
+	}
+	// resolving must not modify the schematic's own part
+	if j2, _ := schematic.PartByName("J2"); len(j2.Attributes) != 1 {
+		t.Errorf("ResolvePart modified the schematic's J2 attributes: %v", j2.Attributes)
+	}
+}
+
+func TestTechnologyForPartErrors(t *testing.T) {
+	schematic := loadTestSchematic(t)
+	r1, _ := schematic.PartByName("R1")
+	tests := []struct {
+		name   string
+		modify func(p *Part)
+	}{
+		{name: "unknown technology", modify: func(p *Part) { p.Technology = "_5%" }},
+		{name: "unknown device", modify: func(p *Part) { p.Device = "R0603" }},
+		{name: "unknown deviceset", modify: func(p *Part) { p.DeviceSet = "C-EU" }},
+		{name: "unknown library", modify: func(p *Part) { p.Library = "rcl" }},
+		{name: "library urn mismatch", modify: func(p *Part) { p.LibraryUrn = "urn:adsk.eagle:library:1" }},
+	}
+	for _, test := range tests {
+		part := r1
+		test.modify(&part)
+		_, err := schematic.technologyForPart(part)
+		if err == nil || !strings.Contains(err.Error(), `part "R1"`) {
+			t.Errorf("%s: got error %v, want one naming part R1", test.name, err)
+		}
+	}
+}
+
+func TestTechnologyForPartWithoutTechnologies(t *testing.T) {
+	schematic := Schematic{Libraries: []Library{{
+		Name: "frames",
+		DeviceSets: []DeviceSet{{
+			Name:    "A4",
+			Devices: []Device{{Name: ""}},
+		}},
+	}}}
+	part := Part{Name: "FRAME1", Library: "frames", DeviceSet: "A4"}
+	if _, err := schematic.technologyForPart(part); err != nil {
+		t.Errorf("device without technologies: %v", err)
+	}
+	part.Technology = "X"
+	if _, err := schematic.technologyForPart(part); err == nil {
+		t.Errorf("device without technologies: expected an error for technology X")
+	}
+}
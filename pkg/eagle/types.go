@@ -20,17 +20,64 @@
 
 package eagle
 
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// RawElement holds an XML element that isn't otherwise modelled by this
+// package, exactly as it was read, so that it can be written back out
+// unchanged. See Preserved.
+type RawElement struct {
+	XMLName    xml.Name
+	Attributes []xml.Attr `xml:",any,attr"`
+	Content    string     `xml:",innerxml"`
+}
+
+// Description object. Eagle descriptions may contain HTML markup, which is
+// kept as (escaped) text
+type Description struct {
+	Language string `xml:"language,attr,omitempty"`
+	Text     string `xml:",chardata"`
+	Preserved
+}
+
+// Note object, used only in the compatibility section
+type Note struct {
+	Version    string `xml:"version,attr"`
+	MinVersion string `xml:"minversion,attr,omitempty"`
+	Severity   string `xml:"severity,attr,omitempty"`
+	Text       string `xml:",chardata"`
+	Preserved
+}
+
+// Setting object
+type Setting struct {
+	AlwaysVectorFont  string `xml:"alwaysvectorfont,attr,omitempty"`
+	VerticalText      string `xml:"verticaltext,attr,omitempty"`
+	KeepOldVectorFont string `xml:"keepoldvectorfont,attr,omitempty"`
+	Preserved
+}
+
 // Grid object
 type Grid struct {
-	Distance        float64 `xml:"distance,attr"`
-	UnitDistance    string  `xml:"unitdist,attr"`
-	Unit            string  `xml:"unit,attr"`
-	Style           string  `xml:"style,attr"`
-	Multiple        float64 `xml:"multiple,attr"`
-	Display         string  `xml:"display,attr"`
-	AltDistance     float64 `xml:"altdistance,attr"`
-	AltUnitDistance string  `xml:"altunitdist,attr"`
-	AltUnit         string  `xml:"altunit,attr"`
+	Distance        float64 `xml:"distance,attr,omitempty"`
+	UnitDistance    string  `xml:"unitdist,attr,omitempty"`
+	Unit            string  `xml:"unit,attr,omitempty"`
+	Style           string  `xml:"style,attr,omitempty"`
+	Multiple        float64 `xml:"multiple,attr,omitempty"`
+	Display         string  `xml:"display,attr,omitempty"`
+	AltDistance     float64 `xml:"altdistance,attr,omitempty"`
+	AltUnitDistance string  `xml:"altunitdist,attr,omitempty"`
+	AltUnit         string  `xml:"altunit,attr,omitempty"`
+	Preserved
+}
+
+// Filter object
+type Filter struct {
+	Name       string `xml:"name,attr"`
+	Expression string `xml:"expression,attr"`
+	Preserved
 }
 
 // Layer object
@@ -39,21 +86,46 @@ type Layer struct {
 	Name    string `xml:"name,attr"`
 	Color   int    `xml:"color,attr"`
 	Fill    int    `xml:"fill,attr"`
-	Visible string `xml:"visible,attr"`
-	Active  string `xml:"active,attr"`
+	Visible string `xml:"visible,attr,omitempty"`
+	Active  string `xml:"active,attr,omitempty"`
+	Preserved
 }
 
 // Wire object
 type Wire struct {
-	X1    float64 `xml:"x1,attr"`
-	Y1    float64 `xml:"y1,attr"`
-	X2    float64 `xml:"x2,attr"`
-	Y2    float64 `xml:"y2,attr"`
-	Width float64 `xml:"width,attr"`
-	Layer int     `xml:"layer,attr"`
-	Style string  `xml:"style,attr,omitempty"`
-	Cap   string  `xml:"cap,attr,omitempty"`
-	Curve float64 `xml:"curve,attr,omitempty"`
+	X1     float64 `xml:"x1,attr"`
+	Y1     float64 `xml:"y1,attr"`
+	X2     float64 `xml:"x2,attr"`
+	Y2     float64 `xml:"y2,attr"`
+	Width  float64 `xml:"width,attr"`
+	Layer  int     `xml:"layer,attr"`
+	Extent string  `xml:"extent,attr,omitempty"`
+	Style  string  `xml:"style,attr,omitempty"`
+	Cap    string  `xml:"cap,attr,omitempty"`
+	Curve  float64 `xml:"curve,attr,omitempty"`
+	Preserved
+}
+
+// Dimension object, ie. a measurement annotation, not the Dimension layer
+type Dimension struct {
+	X1        float64 `xml:"x1,attr"`
+	Y1        float64 `xml:"y1,attr"`
+	X2        float64 `xml:"x2,attr"`
+	Y2        float64 `xml:"y2,attr"`
+	X3        float64 `xml:"x3,attr"`
+	Y3        float64 `xml:"y3,attr"`
+	Layer     int     `xml:"layer,attr"`
+	Type      string  `xml:"dtype,attr,omitempty"`
+	Width     float64 `xml:"width,attr,omitempty"`
+	ExtWidth  float64 `xml:"extwidth,attr,omitempty"`
+	ExtLength float64 `xml:"extlength,attr,omitempty"`
+	ExtOffset float64 `xml:"extoffset,attr,omitempty"`
+	TextSize  float64 `xml:"textsize,attr"`
+	TextRatio int     `xml:"textratio,attr,omitempty"`
+	Unit      string  `xml:"unit,attr,omitempty"`
+	Precision int     `xml:"precision,attr,omitempty"`
+	Visible   string  `xml:"visible,attr,omitempty"`
+	Preserved
 }
 
 // Rectangle object
@@ -64,6 +136,23 @@ type Rectangle struct {
 	Y2     float64 `xml:"y2,attr"`
 	Layer  int     `xml:"layer,attr"`
 	Rotate string  `xml:"rot,attr,omitempty"`
+	Preserved
+}
+
+// Frame object, ie. a drawing frame
+type Frame struct {
+	X1           float64 `xml:"x1,attr"`
+	Y1           float64 `xml:"y1,attr"`
+	X2           float64 `xml:"x2,attr"`
+	Y2           float64 `xml:"y2,attr"`
+	Columns      int     `xml:"columns,attr"`
+	Rows         int     `xml:"rows,attr"`
+	Layer        int     `xml:"layer,attr"`
+	BorderLeft   string  `xml:"border-left,attr,omitempty"`
+	BorderTop    string  `xml:"border-top,attr,omitempty"`
+	BorderRight  string  `xml:"border-right,attr,omitempty"`
+	BorderBottom string  `xml:"border-bottom,attr,omitempty"`
+	Preserved
 }
 
 // Vertex object, used only in Polygon
@@ -71,19 +160,21 @@ type Vertex struct {
 	X     float64 `xml:"x,attr"`
 	Y     float64 `xml:"y,attr"`
 	Curve float64 `xml:"curve,attr,omitempty"`
+	Preserved
 }
 
 // Polygon object
 type Polygon struct {
 	Vertices []Vertex `xml:"vertex"`
-	Isolate  string   `xml:"isolate,omitempty"`
-	Pour     string   `xml:"pour,omitempty"`
-	Orphans  string   `xml:"orphans,omitempty"`
+	Width    float64  `xml:"width,attr"`
 	Layer    int      `xml:"layer,attr"`
-	Rank     int      `xml:"rank,attr,omitempty"`
 	Spacing  string   `xml:"spacing,attr,omitempty"`
+	Pour     string   `xml:"pour,attr,omitempty"`
+	Isolate  string   `xml:"isolate,attr,omitempty"`
+	Orphans  string   `xml:"orphans,attr,omitempty"`
 	Thermals string   `xml:"thermals,attr,omitempty"`
-	Width    float64  `xml:"width,attr"`
+	Rank     int      `xml:"rank,attr,omitempty"`
+	Preserved
 }
 
 // Hole object
@@ -91,6 +182,7 @@ type Hole struct {
 	X     float64 `xml:"x,attr"`
 	Y     float64 `xml:"y,attr"`
 	Drill float64 `xml:"drill,attr"`
+	Preserved
 }
 
 // Circle object
@@ -100,16 +192,38 @@ type Circle struct {
 	Radius float64 `xml:"radius,attr"`
 	Width  float64 `xml:"width,attr"`
 	Layer  int     `xml:"layer,attr"`
+	Preserved
 }
 
 // Pad object
 type Pad struct {
-	Name   string  `xml:"name,attr"`
-	X      float64 `xml:"x,attr"`
-	Y      float64 `xml:"y,attr"`
-	Drill  float64 `xml:"drill,attr"`
-	Shape  string  `xml:"shape,attr,omitempty"`
-	Rotate string  `xml:"rot,attr,omitempty"`
+	Name     string  `xml:"name,attr"`
+	X        float64 `xml:"x,attr"`
+	Y        float64 `xml:"y,attr"`
+	Drill    float64 `xml:"drill,attr"`
+	Diameter float64 `xml:"diameter,attr,omitempty"`
+	Shape    string  `xml:"shape,attr,omitempty"`
+	Rotate   string  `xml:"rot,attr,omitempty"`
+	Stop     string  `xml:"stop,attr,omitempty"`
+	Thermals string  `xml:"thermals,attr,omitempty"`
+	First    string  `xml:"first,attr,omitempty"`
+	Preserved
+}
+
+// Smd object
+type Smd struct {
+	Name      string  `xml:"name,attr"`
+	X         float64 `xml:"x,attr"`
+	Y         float64 `xml:"y,attr"`
+	DX        float64 `xml:"dx,attr"`
+	DY        float64 `xml:"dy,attr"`
+	Layer     int     `xml:"layer,attr"`
+	Roundness int     `xml:"roundness,attr,omitempty"`
+	Rotate    string  `xml:"rot,attr,omitempty"`
+	Stop      string  `xml:"stop,attr,omitempty"`
+	Thermals  string  `xml:"thermals,attr,omitempty"`
+	Cream     string  `xml:"cream,attr,omitempty"`
+	Preserved
 }
 
 // Text object
@@ -124,19 +238,26 @@ type Text struct {
 	Align    string  `xml:"align,attr,omitempty"`
 	Distance float64 `xml:"distance,attr,omitempty"`
 	Rotate   string  `xml:"rot,attr,omitempty"`
+	Preserved
 }
 
 // Package object
 type Package struct {
-	Name        string      `xml:"name,attr"`
-	Urn         string      `xml:"urn,attr,omitempty"`
-	Version     string      `xml:"library_version,attr,omitempty"`
-	Description string      `xml:"description"`
-	Pads        []Pad       `xml:"pad"`
-	Rectangles  []Rectangle `xml:"rectangle"`
-	Circles     []Circle    `xml:"circle"`
-	Texts       []Text      `xml:"text"`
-	Wires       []Wire      `xml:"wire"`
+	Name        string       `xml:"name,attr"`
+	Urn         string       `xml:"urn,attr,omitempty"`
+	Version     string       `xml:"library_version,attr,omitempty"`
+	Description *Description `xml:"description,omitempty"`
+	Polygons    []Polygon    `xml:"polygon"`
+	Wires       []Wire       `xml:"wire"`
+	Texts       []Text       `xml:"text"`
+	Dimensions  []Dimension  `xml:"dimension"`
+	Circles     []Circle     `xml:"circle"`
+	Rectangles  []Rectangle  `xml:"rectangle"`
+	Frames      []Frame      `xml:"frame"`
+	Holes       []Hole       `xml:"hole"`
+	Pads        []Pad        `xml:"pad"`
+	Smds        []Smd        `xml:"smd"`
+	Preserved
 }
 
 // PackageInstance object
@@ -144,38 +265,155 @@ type Package struct {
 // Package3D object via a slice
 type PackageInstance struct {
 	Name string `xml:"name,attr"`
+	Preserved
 }
 
 // Package3D object
 type Package3D struct {
-	Name        string            `xml:"name,attr"`
+	Name        string            `xml:"name,attr,omitempty"`
 	Urn         string            `xml:"urn,attr,omitempty"`
 	Type        string            `xml:"type,attr"`
 	Version     string            `xml:"library_version,attr,omitempty"`
-	Description string            `xml:"description"`
-	Instances   []PackageInstance `xml:"packageinstances>packageinstance"`
+	Description *Description      `xml:"description,omitempty"`
+	Instances   []PackageInstance `xml:"packageinstances>packageinstance,omitempty"`
+	Preserved
+}
+
+// Pin object, used only in Symbol
+type Pin struct {
+	Name      string  `xml:"name,attr"`
+	X         float64 `xml:"x,attr"`
+	Y         float64 `xml:"y,attr"`
+	Visible   string  `xml:"visible,attr,omitempty"`
+	Length    string  `xml:"length,attr,omitempty"`
+	Direction string  `xml:"direction,attr,omitempty"`
+	Function  string  `xml:"function,attr,omitempty"`
+	SwapLevel int     `xml:"swaplevel,attr,omitempty"`
+	Rotate    string  `xml:"rot,attr,omitempty"`
+	Preserved
+}
+
+// Symbol object
+type Symbol struct {
+	Name        string       `xml:"name,attr"`
+	Urn         string       `xml:"urn,attr,omitempty"`
+	Version     string       `xml:"library_version,attr,omitempty"`
+	Description *Description `xml:"description,omitempty"`
+	Polygons    []Polygon    `xml:"polygon"`
+	Wires       []Wire       `xml:"wire"`
+	Texts       []Text       `xml:"text"`
+	Dimensions  []Dimension  `xml:"dimension"`
+	Pins        []Pin        `xml:"pin"`
+	Circles     []Circle     `xml:"circle"`
+	Rectangles  []Rectangle  `xml:"rectangle"`
+	Frames      []Frame      `xml:"frame"`
+	Preserved
+}
+
+// Gate object
+type Gate struct {
+	Name      string  `xml:"name,attr"`
+	Symbol    string  `xml:"symbol,attr"`
+	X         float64 `xml:"x,attr"`
+	Y         float64 `xml:"y,attr"`
+	AddLevel  string  `xml:"addlevel,attr,omitempty"`
+	SwapLevel int     `xml:"swaplevel,attr,omitempty"`
+	Preserved
+}
+
+// Connect object, mapping a gate pin to a package pad
+type Connect struct {
+	Gate  string `xml:"gate,attr"`
+	Pin   string `xml:"pin,attr"`
+	Pad   string `xml:"pad,attr"`
+	Route string `xml:"route,attr,omitempty"`
+	Preserved
+}
+
+// Package3DInstance object
+type Package3DInstance struct {
+	Package3dUrn string `xml:"package3d_urn,attr"`
+	Preserved
+}
+
+// Technology object
+type Technology struct {
+	Name       string      `xml:"name,attr"`
+	Attributes []Attribute `xml:"attribute"`
+	Preserved
+}
+
+func (t Technology) GetAttributes() []Attribute {
+	return t.Attributes
+}
+
+// Device object. Eagle permits (and frequently uses) an empty device name,
+// so it is always written out
+type Device struct {
+	Name               string              `xml:"name,attr"`
+	Package            string              `xml:"package,attr,omitempty"`
+	Connects           []Connect           `xml:"connects>connect,omitempty"`
+	Package3DInstances []Package3DInstance `xml:"package3dinstances>package3dinstance,omitempty"`
+	Technologies       []Technology        `xml:"technologies>technology,omitempty"`
+	Preserved
+}
+
+// DeviceSet object
+type DeviceSet struct {
+	Name        string       `xml:"name,attr"`
+	Urn         string       `xml:"urn,attr,omitempty"`
+	Version     string       `xml:"library_version,attr,omitempty"`
+	Prefix      string       `xml:"prefix,attr,omitempty"`
+	UserValue   string       `xml:"uservalue,attr,omitempty"`
+	Description *Description `xml:"description,omitempty"`
+	Gates       []Gate       `xml:"gates>gate"`
+	Devices     []Device     `xml:"devices>device"`
+	Preserved
 }
 
 // Library object
 type Library struct {
-	Name        string    `xml:"name,attr"`
-	Urn         string    `xml:"urn,attr,omitempty"`
-	Description string    `xml:"description"`
-	Packages    []Package `xml:"packages>package"`
+	Name        string       `xml:"name,attr,omitempty"`
+	Urn         string       `xml:"urn,attr,omitempty"`
+	Description *Description `xml:"description,omitempty"`
+	Packages    []Package    `xml:"packages>package,omitempty"`
+	Packages3D  []Package3D  `xml:"packages3d>package3d,omitempty"`
+	Symbols     []Symbol     `xml:"symbols>symbol,omitempty"`
+	DeviceSets  []DeviceSet  `xml:"devicesets>deviceset,omitempty"`
+	Preserved
 }
 
 // Attribute object
 type Attribute struct {
 	Name     string  `xml:"name,attr"`
-	Value    string  `xml:"value,attr"`
+	Value    string  `xml:"value,attr,omitempty"`
 	X        float64 `xml:"x,attr,omitempty"`
 	Y        float64 `xml:"y,attr,omitempty"`
 	Size     float64 `xml:"size,attr,omitempty"`
+	Layer    int     `xml:"layer,attr,omitempty"`
 	Display  string  `xml:"display,attr,omitempty"`
 	Constant string  `xml:"constant,attr,omitempty"`
 	Font     string  `xml:"font,attr,omitempty"`
 	Ratio    int     `xml:"ratio,attr,omitempty"`
 	Rotate   string  `xml:"rot,attr,omitempty"`
+	Align    string  `xml:"align,attr,omitempty"`
+	Preserved
+}
+
+// Variant object, used in Element and Part
+type Variant struct {
+	Name       string `xml:"name,attr"`
+	Populate   string `xml:"populate,attr,omitempty"`
+	Value      string `xml:"value,attr,omitempty"`
+	Technology string `xml:"technology,attr,omitempty"`
+	Preserved
+}
+
+// VariantDef object
+type VariantDef struct {
+	Name    string `xml:"name,attr"`
+	Current string `xml:"current,attr,omitempty"`
+	Preserved
 }
 
 // Element object
@@ -188,9 +426,13 @@ type Element struct {
 	LibraryUrn   string      `xml:"library_urn,attr,omitempty"`
 	Package      string      `xml:"package,attr"`
 	Package3dUrn string      `xml:"package3d_urn,attr,omitempty"`
-	Smashed      string      `xml:"smashed,attr"`
+	Locked       string      `xml:"locked,attr,omitempty"`
+	Populate     string      `xml:"populate,attr,omitempty"`
+	Smashed      string      `xml:"smashed,attr,omitempty"`
 	Rotate       string      `xml:"rot,attr,omitempty"`
 	Attributes   []Attribute `xml:"attribute"`
+	Variants     []Variant   `xml:"variant"`
+	Preserved
 }
 
 func (e Element) GetAttributes() []Attribute {
@@ -205,6 +447,9 @@ type Plain struct {
 	Polygons   []Polygon   `xml:"polygon"`
 	Texts      []Text      `xml:"text"`
 	Wires      []Wire      `xml:"wire"`
+	Dimensions []Dimension `xml:"dimension"`
+	Frames     []Frame     `xml:"frame"`
+	Preserved
 }
 
 // NewPlain constructs a new Plain object with empty slices; this
@@ -217,15 +462,122 @@ func NewPlain() Plain {
 		Polygons:   []Polygon{},
 		Texts:      []Text{},
 		Wires:      []Wire{},
+		Dimensions: []Dimension{},
+		Frames:     []Frame{},
+	}
+}
+
+// Clearance object, used only in Class
+type Clearance struct {
+	Class int     `xml:"class,attr"`
+	Value float64 `xml:"value,attr,omitempty"`
+	Preserved
+}
+
+// Class object, ie. a net class
+type Class struct {
+	Number     int         `xml:"number,attr"`
+	Name       string      `xml:"name,attr"`
+	Width      float64     `xml:"width,attr,omitempty"`
+	Drill      float64     `xml:"drill,attr,omitempty"`
+	Clearances []Clearance `xml:"clearance"`
+	Preserved
+}
+
+// Param object, used in DesignRules and Pass
+type Param struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Preserved
+}
+
+// DesignRules object
+type DesignRules struct {
+	Name         string        `xml:"name,attr"`
+	Descriptions []Description `xml:"description"`
+	Params       []Param       `xml:"param"`
+	Preserved
+}
+
+// Param looks up a design rule parameter by name, returning a default
+// value if it isn't present
+func (d DesignRules) Param(name, def string) string {
+	for _, param := range d.Params {
+		if param.Name == name {
+			return param.Value
+		}
 	}
+	return def
+}
+
+// Pass object, ie. an autorouter pass
+type Pass struct {
+	Name   string  `xml:"name,attr"`
+	Refer  string  `xml:"refer,attr,omitempty"`
+	Active string  `xml:"active,attr,omitempty"`
+	Params []Param `xml:"param"`
+	Preserved
+}
+
+// Autorouter object
+type Autorouter struct {
+	Passes []Pass `xml:"pass"`
+	Preserved
+}
+
+// ContactRef object, used only in Signal
+type ContactRef struct {
+	Element  string `xml:"element,attr"`
+	Pad      string `xml:"pad,attr"`
+	Route    string `xml:"route,attr,omitempty"`
+	RouteTag string `xml:"routetag,attr,omitempty"`
+	Preserved
+}
+
+// Via object
+type Via struct {
+	X          float64 `xml:"x,attr"`
+	Y          float64 `xml:"y,attr"`
+	Extent     string  `xml:"extent,attr"`
+	Drill      float64 `xml:"drill,attr"`
+	Diameter   float64 `xml:"diameter,attr,omitempty"`
+	Shape      string  `xml:"shape,attr,omitempty"`
+	AlwaysStop string  `xml:"alwaysstop,attr,omitempty"`
+	Preserved
+}
+
+// Signal object
+type Signal struct {
+	Name           string       `xml:"name,attr"`
+	Class          string       `xml:"class,attr,omitempty"`
+	AirwiresHidden string       `xml:"airwireshidden,attr,omitempty"`
+	ContactRefs    []ContactRef `xml:"contactref"`
+	Polygons       []Polygon    `xml:"polygon"`
+	Wires          []Wire       `xml:"wire"`
+	Vias           []Via        `xml:"via"`
+	Preserved
+}
+
+// Approved object, used only in the errors section
+type Approved struct {
+	Hash string `xml:"hash,attr"`
+	Preserved
 }
 
 // Board object
 type Board struct {
-	Libraries  []Library   `xml:"libraries>library"`
-	Elements   []Element   `xml:"elements>element"`
-	Plain      Plain       `xml:"plain"`
-	Attributes []Attribute `xml:"attributes>attribute"`
+	Description *Description `xml:"description,omitempty"`
+	Plain       Plain        `xml:"plain,omitempty"`
+	Libraries   []Library    `xml:"libraries>library,omitempty"`
+	Attributes  []Attribute  `xml:"attributes>attribute,omitempty"`
+	VariantDefs []VariantDef `xml:"variantdefs>variantdef,omitempty"`
+	Classes     []Class      `xml:"classes>class,omitempty"`
+	DesignRules *DesignRules `xml:"designrules,omitempty"`
+	Autorouter  *Autorouter  `xml:"autorouter,omitempty"`
+	Elements    []Element    `xml:"elements>element,omitempty"`
+	Signals     []Signal     `xml:"signals>signal,omitempty"`
+	Errors      []Approved   `xml:"errors>approved,omitempty"`
+	Preserved
 }
 
 func (b Board) GetAttributes() []Attribute {
@@ -242,10 +594,90 @@ func NewBoard() Board {
 	}
 }
 
-// Eagle object
+// Eagle object. The drawing element that wraps most of the content of an
+// Eagle file is flattened away; see eagleDocument for the XML structure.
+// Board files populate Board; schematic and library files populate Schematic
+// or Library instead, and Board is then neither read nor written.
 type Eagle struct {
-	Version string  `xml:"version,attr"`
-	Grid    Grid    `xml:"drawing>grid"`
-	Layers  []Layer `xml:"drawing>layers>layer"`
-	Board   Board   `xml:"drawing>board"`
+	Version       string
+	Settings      []Setting
+	Grid          Grid
+	Filters       []Filter
+	Layers        []Layer
+	Board         Board
+	Schematic     *Schematic
+	Library       *Library
+	Compatibility []Note
+	// Preserved holds anything not otherwise modelled on the top-level eagle
+	// element
+	Preserved
+	// DrawingPreserved holds anything not otherwise modelled on the drawing
+	// element
+	DrawingPreserved Preserved
+}
+
+// eagleDocument mirrors the XML structure of an Eagle file
+type eagleDocument struct {
+	Version       string  `xml:"version,attr"`
+	Drawing       drawing `xml:"drawing"`
+	Compatibility []Note  `xml:"compatibility>note,omitempty"`
+	Preserved
+}
+
+// drawing is the Eagle XML drawing element
+type drawing struct {
+	Settings  []Setting  `xml:"settings>setting,omitempty"`
+	Grid      Grid       `xml:"grid,omitempty"`
+	Filters   []Filter   `xml:"filters>filter,omitempty"`
+	Layers    []Layer    `xml:"layers>layer"`
+	Library   *Library   `xml:"library,omitempty"`
+	Schematic *Schematic `xml:"schematic,omitempty"`
+	Board     *Board     `xml:"board,omitempty"`
+	Preserved
+}
+
+// UnmarshalXML implements xml.Unmarshaler for Eagle
+func (e *Eagle) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var doc eagleDocument
+	if err := decodeElement(d, start, reflect.ValueOf(&doc).Elem()); err != nil {
+		return err
+	}
+	*e = Eagle{
+		Version:          doc.Version,
+		Settings:         doc.Drawing.Settings,
+		Grid:             doc.Drawing.Grid,
+		Filters:          doc.Drawing.Filters,
+		Layers:           doc.Drawing.Layers,
+		Schematic:        doc.Drawing.Schematic,
+		Library:          doc.Drawing.Library,
+		Compatibility:    doc.Compatibility,
+		Preserved:        doc.Preserved,
+		DrawingPreserved: doc.Drawing.Preserved,
+	}
+	if doc.Drawing.Board != nil {
+		e.Board = *doc.Drawing.Board
+	}
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler for Eagle
+func (e Eagle) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	doc := eagleDocument{
+		Version:       e.Version,
+		Compatibility: e.Compatibility,
+		Preserved:     e.Preserved,
+		Drawing: drawing{
+			Settings:  e.Settings,
+			Grid:      e.Grid,
+			Filters:   e.Filters,
+			Layers:    e.Layers,
+			Schematic: e.Schematic,
+			Library:   e.Library,
+			Preserved: e.DrawingPreserved,
+		},
+	}
+	if e.Schematic == nil && e.Library == nil {
+		doc.Drawing.Board = &e.Board
+	}
+	return encodeElement(enc, "eagle", reflect.ValueOf(doc))
 }
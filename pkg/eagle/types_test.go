@@ -0,0 +1,257 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package eagle
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clearPreserved zeroes every Preserved within v, so that decoded values
+// can be compared with literals
+func clearPreserved(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			clearPreserved(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			clearPreserved(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == preservedType {
+			v.Set(reflect.Zero(preservedType))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				clearPreserved(v.Field(i))
+			}
+		}
+	}
+}
+
+func loadTestFile(t *testing.T, filename string) *Eagle {
+	t.Helper()
+	e, err := LoadEagleFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clearPreserved(reflect.ValueOf(e))
+	return e
+}
+
+type decodeTest struct {
+	name      string
+	got, want interface{}
+}
+
+func runDecodeTests(t *testing.T, tests []decodeTest) {
+	t.Helper()
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestDecodeBoard(t *testing.T) {
+	e := loadTestFile(t, "testdata/dtd.brd")
+	b := e.Board
+	if len(b.Plain.Polygons) != 1 || len(b.Libraries) != 1 || len(b.Elements) != 1 || len(b.Signals) != 1 {
+		t.Fatalf("board decoded with missing content: %+v", b)
+	}
+	library := b.Libraries[0]
+	runDecodeTests(t, []decodeTest{
+		{"settings", e.Settings, []Setting{
+			{AlwaysVectorFont: "yes"},
+			{VerticalText: "down"},
+			{KeepOldVectorFont: "yes"},
+		}},
+		{"grid", e.Grid, Grid{
+			Distance: 0.5, UnitDistance: "mm", Unit: "mil", Style: "dots", Multiple: 2, Display: "yes",
+			AltDistance: 0.1, AltUnitDistance: "mil", AltUnit: "mm",
+		}},
+		{"filters", e.Filters, []Filter{{Name: "copper", Expression: "1 16"}}},
+		{"layer", e.Layers[1], Layer{Number: 16, Name: "Bottom", Color: 1, Fill: 1, Visible: "no", Active: "no"}},
+		{"description", b.Description, &Description{Language: "en", Text: "Every board element in the Eagle DTD"}},
+		{"polygon", b.Plain.Polygons[0], Polygon{
+			Vertices: []Vertex{{X: 0, Y: 0}, {X: 10, Y: 0, Curve: 90}, {X: 10, Y: 10}},
+			Width:    0.3, Layer: 39, Spacing: "1.5", Pour: "cutout", Isolate: "0.2", Orphans: "yes", Thermals: "no", Rank: 3,
+		}},
+		{"wire", b.Plain.Wires, []Wire{{
+			X1: 0, Y1: 0, X2: 20, Y2: 0, Width: 0.1, Layer: 20, Extent: "1-16", Style: "dashdot", Cap: "flat", Curve: -30,
+		}}},
+		{"text", b.Plain.Texts, []Text{{
+			Text: "DTD", X: 1, Y: 2, Size: 1.5, Layer: 21, Ratio: 15, Font: "fixed", Align: "top-right", Distance: 60, Rotate: "SR90",
+		}}},
+		{"dimension", b.Plain.Dimensions, []Dimension{{
+			X1: 0, Y1: -5, X2: 20, Y2: -5, X3: 10, Y3: -8, Layer: 47, Type: "horizontal", Width: 0.13, ExtWidth: 0.1,
+			ExtLength: 1, ExtOffset: 0.5, TextSize: 1.27, TextRatio: 10, Unit: "inch", Precision: 3, Visible: "yes",
+		}}},
+		{"circle", b.Plain.Circles, []Circle{{X: 5, Y: 5, Radius: 2.5, Width: 0.2, Layer: 21}}},
+		{"rectangle", b.Plain.Rectangles, []Rectangle{{X1: 1, Y1: 1, X2: 3, Y2: 4, Layer: 21, Rotate: "R45"}}},
+		{"frame", b.Plain.Frames, []Frame{{
+			X1: -10, Y1: -20, X2: 40, Y2: 30, Columns: 6, Rows: 4, Layer: 48,
+			BorderLeft: "no", BorderTop: "no", BorderRight: "no", BorderBottom: "no",
+		}}},
+		{"hole", b.Plain.Holes, []Hole{{X: 15, Y: 5, Drill: 3.2}}},
+		{"library", []string{library.Name, library.Urn, library.Description.Language}, []string{"dtd", "urn:adsk.eagle:library:1", "de"}},
+		{"smd", library.Packages[0].Smds, []Smd{{
+			Name: "1", X: -0.95, Y: -1.1, DX: 0.6, DY: 0.7, Layer: 16, Roundness: 50, Rotate: "R90",
+			Stop: "no", Thermals: "no", Cream: "no",
+		}}},
+		{"pad", library.Packages[0].Pads, []Pad{{
+			Name: "2", X: 0, Y: 1.1, Drill: 0.8, Diameter: 1.6, Shape: "offset", Rotate: "R270",
+			Stop: "no", Thermals: "no", First: "yes",
+		}}},
+		{"package3d", library.Packages3D, []Package3D{{
+			Name: "SOT23", Urn: "urn:adsk.eagle:package:3/2", Type: "model", Version: "4",
+			Description: &Description{Text: "SOT-23 model"},
+			Instances:   []PackageInstance{{Name: "SOT23"}},
+		}}},
+		{"attributes", b.Attributes, []Attribute{{
+			Name: "REVISION", Value: "C", X: 2, Y: 3, Size: 1.5, Layer: 21, Display: "both", Constant: "yes",
+			Font: "vector", Ratio: 10, Rotate: "R90", Align: "center",
+		}}},
+		{"variantdefs", b.VariantDefs, []VariantDef{{Name: "full"}, {Name: "lite", Current: "yes"}}},
+		{"classes", b.Classes, []Class{
+			{Number: 0, Name: "default"},
+			{Number: 2, Name: "power", Width: 0.5, Drill: 0.4, Clearances: []Clearance{{Class: 0, Value: 0.3}, {Class: 2, Value: 0.4}}},
+		}},
+		{"designrules", b.DesignRules, &DesignRules{
+			Name:         "fab",
+			Descriptions: []Description{{Language: "en", Text: "Fab house rules"}},
+			Params:       []Param{{Name: "layerSetup", Value: "(1*16)"}, {Name: "mdWireWire", Value: "6mil"}},
+		}},
+		{"designrules param", b.DesignRules.Param("mdWireWire", "8mil"), "6mil"},
+		{"designrules default", b.DesignRules.Param("mdPadPad", "8mil"), "8mil"},
+		{"autorouter", b.Autorouter, &Autorouter{Passes: []Pass{
+			{Name: "Default", Params: []Param{{Name: "RoutingGrid", Value: "25mil"}}},
+			{Name: "Optimize1", Refer: "Default", Active: "no", Params: []Param{{Name: "cfVia", Value: "99"}}},
+		}}},
+		{"element", b.Elements[0], Element{
+			Name: "Q1", Value: "2N3904", X: 12, Y: 8, Library: "dtd", LibraryUrn: "urn:adsk.eagle:library:1",
+			Package: "SOT23", Package3dUrn: "urn:adsk.eagle:package:3/2", Locked: "yes", Populate: "no",
+			Smashed: "yes", Rotate: "MR270",
+			Attributes: []Attribute{{Name: "NAME", X: 12, Y: 11, Size: 1.27, Layer: 25}},
+			Variants:   []Variant{{Name: "full", Populate: "yes", Value: "BC547", Technology: "-A"}},
+		}},
+		{"signal", b.Signals[0], Signal{
+			Name: "VCC", Class: "2", AirwiresHidden: "yes",
+			ContactRefs: []ContactRef{{Element: "Q1", Pad: "2", Route: "any", RouteTag: "vcc"}},
+			Polygons: []Polygon{{
+				Vertices: []Vertex{{X: 0, Y: 0, Curve: -45}, {X: 20, Y: 0}, {X: 20, Y: 20}},
+				Width:    0.254, Layer: 16, Spacing: "0.8", Pour: "hatch", Isolate: "0.35", Orphans: "yes", Thermals: "no", Rank: 4,
+			}},
+			Wires: []Wire{{X1: 12, Y1: 9.1, X2: 12, Y2: 15, Width: 0.4, Layer: 1}},
+			Vias:  []Via{{X: 12, Y: 15, Extent: "1-16", Drill: 0.4, Diameter: 0.9, Shape: "octagon", AlwaysStop: "yes"}},
+		}},
+		{"errors", b.Errors, []Approved{{Hash: "4,1,abcdef0123456789"}}},
+		{"compatibility", len(e.Compatibility), 1},
+	})
+	note := e.Compatibility[0]
+	note.Text = strings.TrimSpace(note.Text)
+	runDecodeTests(t, []decodeTest{
+		{"note", note, Note{Version: "6.3", MinVersion: "6.2.2", Severity: "warning", Text: "Multi-line text note."}},
+	})
+}
+
+func TestDecodeLibrary(t *testing.T) {
+	e := loadTestFile(t, "testdata/dtd.lbr")
+	if e.Library == nil {
+		t.Fatal("library not decoded")
+	}
+	library := e.Library
+	if len(library.Symbols) != 1 || len(library.DeviceSets) != 1 {
+		t.Fatalf("library decoded with missing content: %+v", library)
+	}
+	runDecodeTests(t, []decodeTest{
+		{"library", []string{library.Name, library.Urn}, []string{"", "urn:adsk.eagle:library:1"}},
+		{"package3d", library.Packages3D, []Package3D{{
+			Name: "SOT23", Urn: "urn:adsk.eagle:package:3/2", Type: "box", Version: "4",
+			Description: &Description{Text: "SOT-23 box"},
+			Instances:   []PackageInstance{{Name: "SOT23"}},
+		}}},
+		{"symbol", library.Symbols[0], Symbol{
+			Name: "NPN", Urn: "urn:adsk.eagle:symbol:4/1", Version: "4",
+			Description: &Description{Text: "NPN transistor"},
+			Polygons:    []Polygon{{Vertices: []Vertex{{X: 1, Y: -1}, {X: 2, Y: -2}, {X: 0.5, Y: -2}}, Width: 0.1, Layer: 94}},
+			Wires:       []Wire{{X1: 0, Y1: 2.5, X2: 0, Y2: -2.5, Width: 0.254, Layer: 94}},
+			Texts:       []Text{{Text: ">NAME", X: 5, Y: 2, Size: 1.778, Layer: 95}},
+			Dimensions:  []Dimension{{X1: 0, Y1: 0, X2: 2.54, Y2: 0, X3: 1.27, Y3: -3, Layer: 97, TextSize: 1, Type: "radius"}},
+			Pins: []Pin{
+				{Name: "B", X: -2.54, Y: 0, Visible: "pin", Length: "point", Direction: "in", Function: "dot", SwapLevel: 2, Rotate: "R0"},
+				{Name: "C", X: 2.54, Y: 5.08, Visible: "both", Length: "middle", Direction: "out", Function: "clk", Rotate: "R270"},
+				{Name: "E", X: 2.54, Y: -5.08, Visible: "off", Length: "long", Direction: "pwr", Function: "dotclk", Rotate: "R90"},
+			},
+			Circles:    []Circle{{X: 1, Y: 0, Radius: 2.8, Width: 0.254, Layer: 94}},
+			Rectangles: []Rectangle{{X1: -0.3, Y1: -2.5, X2: 0.3, Y2: 2.5, Layer: 94}},
+			Frames:     []Frame{{X1: -5, Y1: -7, X2: 8, Y2: 7, Columns: 1, Rows: 1, Layer: 97}},
+		}},
+		{"deviceset", library.DeviceSets[0], DeviceSet{
+			Name: "NPN", Urn: "urn:adsk.eagle:component:5/3", Version: "4", Prefix: "Q", UserValue: "yes",
+			Description: &Description{Text: "General purpose NPN"},
+			Gates:       []Gate{{Name: "A", Symbol: "NPN", X: 1.27, Y: -2.54, AddLevel: "must", SwapLevel: 3}},
+			Devices: []Device{
+				{
+					Name: "-SOT23", Package: "SOT23",
+					Connects: []Connect{
+						{Gate: "A", Pin: "B", Pad: "1"},
+						{Gate: "A", Pin: "C", Pad: "3", Route: "any"},
+						{Gate: "A", Pin: "E", Pad: "2"},
+					},
+					Package3DInstances: []Package3DInstance{{Package3dUrn: "urn:adsk.eagle:package:3/2"}},
+					Technologies: []Technology{
+						{Name: "-A", Attributes: []Attribute{{Name: "HFE", Value: "110", Constant: "no"}}},
+						{Name: "-B"},
+					},
+				},
+				{Name: ""},
+			},
+		}},
+	})
+}
+
+// TestOptionalAttributes checks that optional attributes which aren't set
+// are left out, rather than written with empty values Eagle won't accept
+func TestOptionalAttributes(t *testing.T) {
+	e := Eagle{
+		Version:       "9.6.2",
+		Layers:        []Layer{{Number: 1, Name: "Top", Color: 4, Fill: 1}},
+		Compatibility: []Note{{Version: "8.2", Text: "note"}},
+		Board:         NewBoard(),
+	}
+	e.Board.Attributes = []Attribute{{Name: "NAME", X: 1, Y: 2}}
+	e.Board.Libraries = []Library{{Name: "lib", Packages3D: []Package3D{{Urn: "urn:3d", Type: "box"}}}}
+	output, err := xml.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unwanted := range []string{"severity=", "visible=", "active=", "value=", "distance=", "unit=", `name=""`} {
+		if strings.Contains(string(output), unwanted) {
+			t.Errorf("unexpected %s in %s", unwanted, output)
+		}
+	}
+}
@@ -31,3 +31,21 @@ func BoardOutlineWires(width, height float64, layer int) []Wire {
 		{X1: width, Y1: 0, X2: width, Y2: height, Width: 0, Layer: layer},  // right
 	}
 }
+
+// Corners returns the corners of a rectangle as polygon vertices, with its
+// rotation applied. Eagle rotates rectangles about their centre.
+func (r Rectangle) Corners() ([]Vertex, error) {
+	rot, err := ParseRotation(r.Rotate)
+	if err != nil {
+		return nil, err
+	}
+	// rectangles can't be mirrored, only rotated
+	rot.Mirror = false
+	cx, cy := (r.X1+r.X2)/2, (r.Y1+r.Y2)/2
+	vertices := []Vertex{}
+	for _, corner := range [][2]float64{{r.X1, r.Y1}, {r.X2, r.Y1}, {r.X2, r.Y2}, {r.X1, r.Y2}} {
+		x, y := rot.Apply(corner[0]-cx, corner[1]-cy)
+		vertices = append(vertices, Vertex{X: cx + x, Y: cy + y})
+	}
+	return vertices, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package all registers every panel format in this repository with the
+// pkg/panel format registry. Import it for its side effects.
+package all
+
+import (
+	// imported for their format registrations
+	_ "github.com/jsleeio/go-eagle/pkg/format/buchla"
+	_ "github.com/jsleeio/go-eagle/pkg/format/eurorack"
+	_ "github.com/jsleeio/go-eagle/pkg/format/fiveu"
+	_ "github.com/jsleeio/go-eagle/pkg/format/intellijel"
+	_ "github.com/jsleeio/go-eagle/pkg/format/pulplogic"
+	_ "github.com/jsleeio/go-eagle/pkg/format/rack19"
+	_ "github.com/jsleeio/go-eagle/pkg/format/serge"
+	_ "github.com/jsleeio/go-eagle/pkg/format/spec"
+)
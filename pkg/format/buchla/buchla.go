@@ -0,0 +1,171 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package buchla
+
+import (
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// Buchla 200-series 4U format. Panels are multiples of 4.25 inches wide and
+// 7 inches high, with a 6-32 screw a quarter inch in from each corner. Unlike
+// Serge panels, even single-width Buchla panels are fixed at both sides.
+
+const (
+	inch = 25.4
+
+	// PanelHeight represents the total height of a Buchla panel, in
+	// millimetres
+	PanelHeight = 7.0 * inch
+
+	// Unit represents the width of a single-width Buchla panel, in
+	// millimetres. Wider panels are a multiple of this.
+	Unit = 4.25 * inch
+
+	// MountingHolesLeftOffset represents the distance of the left mounting
+	// holes from the left edge of the panel, in millimetres
+	MountingHolesLeftOffset = 0.25 * inch
+
+	// MountingHolesRightOffset represents the distance of the right mounting
+	// holes from the right edge of the panel, in millimetres
+	MountingHolesRightOffset = 0.25 * inch
+
+	// MountingHoleTopY represents the Y value for the top row of mounting
+	// holes, in millimetres
+	MountingHoleTopY = PanelHeight - (0.25 * inch)
+
+	// MountingHoleBottomY represents the Y value for the bottom row of
+	// mounting holes, in millimetres
+	MountingHoleBottomY = 0.25 * inch
+
+	// MountingHoleDiameter represents the diameter of a Buchla mounting
+	// hole, suiting 6-32 screws, in millimetres
+	MountingHoleDiameter = 0.14 * inch
+
+	// HorizontalFit indicates the panel tolerance adjustment for the format
+	HorizontalFit = 0.25
+
+	// CornerRadius indicates the corner radius for the format
+	CornerRadius = 0.0
+
+	// RailHeightFromMountingHole represents how far a Buchla rail extends
+	// inwards from the centre of its mounting holes, in millimetres. Buchla
+	// cases use a flat rail about half an inch deep, centred on the holes.
+	RailHeightFromMountingHole = 0.25 * inch
+)
+
+// Buchla implements the panel.Panel interface and encapsulates the physical
+// characteristics of a Buchla panel
+type Buchla struct {
+	Units int
+}
+
+// NewBuchla constructs a new Buchla object
+func NewBuchla(units int) *Buchla {
+	return &Buchla{Units: units}
+}
+
+// UnitsForWidth returns the number of Buchla units needed to fit a board of
+// the given width, in millimetres
+func UnitsForWidth(width float64) int {
+	return int(math.Max(1, math.Ceil(width/Unit)))
+}
+
+// Width returns the width of a Buchla panel, in millimetres
+func (b Buchla) Width() float64 {
+	return Unit * float64(b.Units)
+}
+
+// Height returns the height of a Buchla panel, in millimetres
+func (b Buchla) Height() float64 {
+	return PanelHeight
+}
+
+// MountingHoleDiameter returns the Buchla mounting hole size, in millimetres
+func (b Buchla) MountingHoleDiameter() float64 {
+	return MountingHoleDiameter
+}
+
+// MountingHoles generates a set of Point objects representing the mounting
+// hole locations of a Buchla panel: one in each corner
+func (b Buchla) MountingHoles() []panel.Point {
+	rhsx := b.Width() - MountingHolesRightOffset
+	return []panel.Point{
+		{X: MountingHolesLeftOffset, Y: MountingHoleBottomY},
+		{X: MountingHolesLeftOffset, Y: MountingHoleTopY},
+		{X: rhsx, Y: MountingHoleBottomY},
+		{X: rhsx, Y: MountingHoleTopY},
+	}
+}
+
+// HorizontalFit indicates the panel tolerance adjustment for the format
+func (b Buchla) HorizontalFit() float64 {
+	return HorizontalFit
+}
+
+// CornerRadius indicates the corner radius for the format
+func (b Buchla) CornerRadius() float64 {
+	return CornerRadius
+}
+
+// RailHeightFromMountingHole is used to calculate space between rails
+func (b Buchla) RailHeightFromMountingHole() float64 {
+	return RailHeightFromMountingHole
+}
+
+// MountingHoleTopY returns the Y coordinate for the top row of mounting
+// holes
+func (b Buchla) MountingHoleTopY() float64 {
+	return MountingHoleTopY
+}
+
+// MountingHoleBottomY returns the Y coordinate for the bottom row of
+// mounting holes
+func (b Buchla) MountingHoleBottomY() float64 {
+	return MountingHoleBottomY
+}
+
+// HeaderLocation returns the location of the header text. Buchla has
+// mounting rails so this is aligned with the top mounting screws
+func (b Buchla) HeaderLocation() panel.Point {
+	return panel.Point{X: b.Width() / 2, Y: b.MountingHoleTopY()}
+}
+
+// FooterLocation returns the location of the footer text. Buchla has
+// mounting rails so this is aligned with the bottom mounting screws
+func (b Buchla) FooterLocation() panel.Point {
+	return panel.Point{X: b.Width() / 2, Y: b.MountingHoleBottomY()}
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "buchla",
+		Description: "Buchla 200-series 4U",
+		Units:       "4.25\" Buchla units",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			return NewBuchla(opts.Width), nil
+		},
+		WidthForBoard: func(width, height float64) int {
+			return UnitsForWidth(width)
+		},
+	})
+}
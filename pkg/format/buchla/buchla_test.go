@@ -0,0 +1,88 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package buchla
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+func TestUnitsForWidth(t *testing.T) {
+	tests := []struct {
+		width float64
+		want  int
+	}{
+		{width: 0, want: 1},
+		{width: 80, want: 1},
+		{width: 107.95, want: 1},
+		{width: 108, want: 2},
+		{width: 300, want: 3},
+	}
+	for _, test := range tests {
+		if got := UnitsForWidth(test.width); got != test.want {
+			t.Errorf("%vmm board: got %d units, want %d", test.width, got, test.want)
+		}
+	}
+}
+
+func TestMountingHoles(t *testing.T) {
+	// every Buchla panel has a hole in each corner, a quarter inch in from
+	// both edges, however narrow it is
+	for units := 1; units <= 3; units++ {
+		b := NewBuchla(units)
+		right := 107.95*float64(units) - 6.35
+		want := []panel.Point{
+			{X: 6.35, Y: 6.35},
+			{X: 6.35, Y: 171.45},
+			{X: right, Y: 6.35},
+			{X: right, Y: 171.45},
+		}
+		got := b.MountingHoles()
+		if len(got) != len(want) {
+			t.Errorf("%d units: got holes %v, want %v", units, got, want)
+			continue
+		}
+		for index := range got {
+			if math.Abs(got[index].X-want[index].X) > 1e-9 || math.Abs(got[index].Y-want[index].Y) > 1e-9 {
+				t.Errorf("%d units: hole %d is at %v, want %v", units, index, got[index], want[index])
+			}
+		}
+	}
+}
+
+func TestRails(t *testing.T) {
+	b := NewBuchla(2)
+	if got, want := b.Height(), 177.8; math.Abs(got-want) > 1e-9 {
+		t.Errorf("got height %v, want %v", got, want)
+	}
+	// the flat rails are centred on the mounting holes, so they cover the
+	// panel edges and nothing else is in the way
+	bottom := b.MountingHoleBottomY() + b.RailHeightFromMountingHole()
+	top := b.MountingHoleTopY() - b.RailHeightFromMountingHole()
+	if math.Abs(bottom-12.7) > 1e-9 || math.Abs(top-165.1) > 1e-9 {
+		t.Errorf("got space between rails %v-%v, want 12.7-165.1", bottom, top)
+	}
+	if header := b.HeaderLocation(); header.X != b.Width()/2 || header.Y != b.MountingHoleTopY() {
+		t.Errorf("header at %v, want centred on the top mounting holes", header)
+	}
+}
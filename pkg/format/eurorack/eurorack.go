@@ -20,6 +20,8 @@
 package eurorack
 
 import (
+	"math"
+
 	"github.com/jsleeio/go-eagle/pkg/panel"
 )
 
@@ -69,6 +71,9 @@ const (
 // characteristics of a Eurorack panel
 type Eurorack struct {
 	HP int
+	// SlotLength is the overall length of oval mounting slots, or zero for
+	// round mounting holes
+	SlotLength float64
 }
 
 // NewEurorack constructs a new Eurorack object
@@ -145,3 +150,29 @@ func (e Eurorack) HeaderLocation() panel.Point {
 func (e Eurorack) FooterLocation() panel.Point {
 	return panel.Point{X: e.Width() / 2, Y: e.MountingHoleBottomY()}
 }
+
+// MountingSlotLength returns the overall length of the mounting slots, or
+// zero if the panel has round mounting holes
+func (e Eurorack) MountingSlotLength() float64 {
+	return e.SlotLength
+}
+
+// SetMountingSlotLength sets the overall length of the mounting slots. Use
+// zero for round mounting holes
+func (e *Eurorack) SetMountingSlotLength(length float64) {
+	e.SlotLength = length
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "eurorack",
+		Description: "Eurorack 3U, per Doepfer spec",
+		Units:       "HP",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			return NewEurorack(opts.Width), nil
+		},
+		WidthForBoard: func(width, height float64) int {
+			return int(math.Ceil(math.Ceil(width) / HP))
+		},
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package fiveu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// 5U large-format panels. All 5U systems share the 8.75" panel height, but
+// unit widths and mounting hole positions differ from vendor to vendor, so
+// these are modelled as variants.
+
+const (
+	inch = 25.4
+
+	// PanelHeight represents the total height of a 5U panel, in millimetres
+	PanelHeight = 8.75 * inch
+
+	// HorizontalFit indicates the panel tolerance adjustment for the format
+	HorizontalFit = 0.25
+
+	// CornerRadius indicates the corner radius for the format
+	CornerRadius = 0.0
+
+	// RailHeightFromMountingHole represents how far a 5U rail extends inwards
+	// from the centre of its mounting holes, in millimetres. It is shared by
+	// all variants, so it errs on the large side as panel.Rails suggests.
+	RailHeightFromMountingHole = 0.25 * inch
+)
+
+// Variant describes the vendor-specific characteristics of a 5U panel
+type Variant struct {
+	// Name is the short name of the variant, eg. "motm"
+	Name string
+	// Description is a human-readable description of the variant
+	Description string
+	// Unit is the width of a single-width panel, in millimetres. Wider
+	// panels are a multiple of this.
+	Unit float64
+	// MountingHoleOffsetX is the distance of the mounting holes from the
+	// left and right edges of the panel, in millimetres
+	MountingHoleOffsetX float64
+	// MountingHoleOffsetY is the distance of the mounting holes from the
+	// top and bottom edges of the panel, in millimetres
+	MountingHoleOffsetY float64
+	// MountingHoleDiameter is the diameter of a mounting hole, in
+	// millimetres
+	MountingHoleDiameter float64
+	// RightHolesFrom is the panel width, in units, from which mounting holes
+	// are needed on the right as well as the left
+	RightHolesFrom int
+}
+
+var (
+	// MOTM is the Synthesis Technology MOTM format, with 1.75" units, the
+	// narrowest of the 5U variants. Like the others, single-width MOTM
+	// panels are only fixed on the left.
+	//
+	// based on http://www.synthtech.com/
+	MOTM = Variant{
+		Name:                 "motm",
+		Description:          "5U, Synthesis Technology MOTM format",
+		Unit:                 1.75 * inch,
+		MountingHoleOffsetX:  0.25 * inch,
+		MountingHoleOffsetY:  0.25 * inch,
+		MountingHoleDiameter: 0.156 * inch,
+		RightHolesFrom:       2,
+	}
+
+	// Dotcom is the Synthesizers.com format, with 2.125" units. Its
+	// holes sit further in from the sides and closer to the top and bottom
+	// edges than MOTM's. Synthesizers.com panels are made to fit classic
+	// Moog modular cabinets, so this format suits those too.
+	//
+	// based on https://www.synthesizers.com/
+	Dotcom = Variant{
+		Name:                 "dotcom",
+		Description:          "5U, Synthesizers.com format, also fits Moog cabinets",
+		Unit:                 2.125 * inch,
+		MountingHoleOffsetX:  0.3125 * inch,
+		MountingHoleOffsetY:  0.1875 * inch,
+		MountingHoleDiameter: 0.15 * inch,
+		RightHolesFrom:       2,
+	}
+)
+
+// Variants returns all of the known 5U variants
+func Variants() []Variant {
+	return []Variant{MOTM, Dotcom}
+}
+
+// VariantByName finds a 5U variant by its short name
+func VariantByName(name string) (Variant, bool) {
+	for _, v := range Variants() {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// UnitsForWidth returns the number of units of this variant needed to fit a
+// board of the given width, in millimetres
+func (v Variant) UnitsForWidth(width float64) int {
+	return int(math.Max(1, math.Ceil(width/v.Unit)))
+}
+
+// FiveU implements the panel.Panel interface and encapsulates the physical
+// characteristics of a 5U panel
+type FiveU struct {
+	Variant Variant
+	Units   int
+}
+
+// NewFiveU constructs a new FiveU object
+func NewFiveU(variant Variant, units int) *FiveU {
+	return &FiveU{Variant: variant, Units: units}
+}
+
+// Width returns the width of a 5U panel, in millimetres
+func (f FiveU) Width() float64 {
+	return f.Variant.Unit * float64(f.Units)
+}
+
+// Height returns the height of a 5U panel, in millimetres
+func (f FiveU) Height() float64 {
+	return PanelHeight
+}
+
+// MountingHoleDiameter returns the 5U mounting hole size, in millimetres
+func (f FiveU) MountingHoleDiameter() float64 {
+	return f.Variant.MountingHoleDiameter
+}
+
+// MountingHoles generates a set of Point objects representing the mounting
+// hole locations of a 5U panel. Narrow panels are only fixed on the left.
+func (f FiveU) MountingHoles() []panel.Point {
+	lhsx := f.Variant.MountingHoleOffsetX
+	holes := []panel.Point{
+		{X: lhsx, Y: f.MountingHoleBottomY()},
+		{X: lhsx, Y: f.MountingHoleTopY()},
+	}
+	if f.Units >= f.Variant.RightHolesFrom {
+		rhsx := f.Width() - f.Variant.MountingHoleOffsetX
+		holes = append(holes, panel.Point{X: rhsx, Y: f.MountingHoleBottomY()})
+		holes = append(holes, panel.Point{X: rhsx, Y: f.MountingHoleTopY()})
+	}
+	return holes
+}
+
+// HorizontalFit indicates the panel tolerance adjustment for the format
+func (f FiveU) HorizontalFit() float64 {
+	return HorizontalFit
+}
+
+// CornerRadius indicates the corner radius for the format
+func (f FiveU) CornerRadius() float64 {
+	return CornerRadius
+}
+
+// RailHeightFromMountingHole is used to calculate space between rails
+func (f FiveU) RailHeightFromMountingHole() float64 {
+	return RailHeightFromMountingHole
+}
+
+// MountingHoleTopY returns the Y coordinate for the top row of mounting
+// holes
+func (f FiveU) MountingHoleTopY() float64 {
+	return PanelHeight - f.Variant.MountingHoleOffsetY
+}
+
+// MountingHoleBottomY returns the Y coordinate for the bottom row of
+// mounting holes
+func (f FiveU) MountingHoleBottomY() float64 {
+	return f.Variant.MountingHoleOffsetY
+}
+
+// HeaderLocation returns the location of the header text. 5U has mounting
+// rails so this is aligned with the top mounting screws
+func (f FiveU) HeaderLocation() panel.Point {
+	return panel.Point{X: f.Width() / 2, Y: f.MountingHoleTopY()}
+}
+
+// FooterLocation returns the location of the footer text. 5U has mounting
+// rails so this is aligned with the bottom mounting screws
+func (f FiveU) FooterLocation() panel.Point {
+	return panel.Point{X: f.Width() / 2, Y: f.MountingHoleBottomY()}
+}
+
+func init() {
+	for _, v := range Variants() {
+		v := v
+		panel.Register(panel.Format{
+			Name:        "5u-" + v.Name,
+			Description: v.Description,
+			Units:       fmt.Sprintf("%.4g\" %s units", v.Unit/inch, v.Name),
+			New: func(opts panel.Options) (panel.Panel, error) {
+				return NewFiveU(v, opts.Width), nil
+			},
+			WidthForBoard: func(width, height float64) int {
+				return v.UnitsForWidth(width)
+			},
+		})
+	}
+}
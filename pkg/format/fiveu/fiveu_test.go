@@ -0,0 +1,126 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package fiveu
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+func TestUnitsForWidth(t *testing.T) {
+	tests := []struct {
+		variant Variant
+		width   float64
+		want    int
+	}{
+		{variant: MOTM, width: 0, want: 1},
+		{variant: MOTM, width: 44.45, want: 1},
+		{variant: MOTM, width: 44.5, want: 2},
+		{variant: MOTM, width: 100, want: 3},
+		{variant: Dotcom, width: 44.5, want: 1},
+		{variant: Dotcom, width: 53.975, want: 1},
+		{variant: Dotcom, width: 54, want: 2},
+		{variant: Dotcom, width: 200, want: 4},
+	}
+	for _, test := range tests {
+		if got := test.variant.UnitsForWidth(test.width); got != test.want {
+			t.Errorf("%s, %vmm board: got %d units, want %d", test.variant.Name, test.width, got, test.want)
+		}
+	}
+}
+
+func TestMountingHoles(t *testing.T) {
+	tests := []struct {
+		variant Variant
+		units   int
+		want    []panel.Point
+	}{
+		{
+			// single-width panels are only fixed on the left
+			variant: MOTM,
+			units:   1,
+			want:    []panel.Point{{X: 6.35, Y: 6.35}, {X: 6.35, Y: 215.9}},
+		},
+		{
+			variant: MOTM,
+			units:   2,
+			want: []panel.Point{
+				{X: 6.35, Y: 6.35}, {X: 6.35, Y: 215.9},
+				{X: 82.55, Y: 6.35}, {X: 82.55, Y: 215.9},
+			},
+		},
+		{
+			variant: Dotcom,
+			units:   1,
+			want:    []panel.Point{{X: 7.9375, Y: 4.7625}, {X: 7.9375, Y: 217.4875}},
+		},
+		{
+			variant: Dotcom,
+			units:   2,
+			want: []panel.Point{
+				{X: 7.9375, Y: 4.7625}, {X: 7.9375, Y: 217.4875},
+				{X: 100.0125, Y: 4.7625}, {X: 100.0125, Y: 217.4875},
+			},
+		},
+		{
+			variant: Dotcom,
+			units:   3,
+			want: []panel.Point{
+				{X: 7.9375, Y: 4.7625}, {X: 7.9375, Y: 217.4875},
+				{X: 153.9875, Y: 4.7625}, {X: 153.9875, Y: 217.4875},
+			},
+		},
+	}
+	for _, test := range tests {
+		f := NewFiveU(test.variant, test.units)
+		got := f.MountingHoles()
+		if len(got) != len(test.want) {
+			t.Errorf("%s, %d units: got holes %v, want %v", test.variant.Name, test.units, got, test.want)
+			continue
+		}
+		for index := range got {
+			if math.Abs(got[index].X-test.want[index].X) > 1e-9 || math.Abs(got[index].Y-test.want[index].Y) > 1e-9 {
+				t.Errorf("%s, %d units: hole %d is at %v, want %v", test.variant.Name, test.units, index, got[index], test.want[index])
+			}
+		}
+	}
+}
+
+func TestRegisteredFormats(t *testing.T) {
+	for _, v := range Variants() {
+		p, err := panel.NewPanel("5u-"+v.Name, panel.Options{Width: 2})
+		if err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+			continue
+		}
+		if got, want := p.Width(), 2*v.Unit; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: got width %v, want %v", v.Name, got, want)
+		}
+		if got := p.Height(); math.Abs(got-222.25) > 1e-9 {
+			t.Errorf("%s: got height %v, want 222.25", v.Name, got)
+		}
+		if got, ok := VariantByName(v.Name); !ok || got != v {
+			t.Errorf("VariantByName(%q): got %v, %v", v.Name, got, ok)
+		}
+	}
+}
@@ -21,6 +21,8 @@
 package intellijel
 
 import (
+	"math"
+
 	"github.com/jsleeio/go-eagle/pkg/format/eurorack"
 	"github.com/jsleeio/go-eagle/pkg/panel"
 )
@@ -68,6 +70,9 @@ const (
 // characteristics of a Intellijel panel
 type Intellijel struct {
 	HP int
+	// SlotLength is the overall length of oval mounting slots, or zero for
+	// round mounting holes
+	SlotLength float64
 }
 
 // NewIntellijel constructs a new Intellijel object
@@ -143,3 +148,29 @@ func (i Intellijel) HeaderLocation() panel.Point {
 func (i Intellijel) FooterLocation() panel.Point {
 	return panel.Point{X: i.Width() / 2.0, Y: i.MountingHoleBottomY()}
 }
+
+// MountingSlotLength returns the overall length of the mounting slots, or
+// zero if the panel has round mounting holes
+func (i Intellijel) MountingSlotLength() float64 {
+	return i.SlotLength
+}
+
+// SetMountingSlotLength sets the overall length of the mounting slots. Use
+// zero for round mounting holes
+func (i *Intellijel) SetMountingSlotLength(length float64) {
+	i.SlotLength = length
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "intellijel",
+		Description: "Intellijel 1U, per Intellijel spec",
+		Units:       "HP",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			return NewIntellijel(opts.Width), nil
+		},
+		WidthForBoard: func(width, height float64) int {
+			return int(math.Ceil(math.Ceil(width) / HP))
+		},
+	})
+}
@@ -21,6 +21,8 @@
 package pulplogic
 
 import (
+	"math"
+
 	"github.com/jsleeio/go-eagle/pkg/format/eurorack"
 	"github.com/jsleeio/go-eagle/pkg/panel"
 )
@@ -78,6 +80,9 @@ const (
 // characteristics of a Pulplogic panel
 type Pulplogic struct {
 	HP int
+	// SlotLength is the overall length of oval mounting slots, or zero for
+	// round mounting holes
+	SlotLength float64
 }
 
 // NewPulplogic constructs a new Pulplogic object
@@ -151,3 +156,29 @@ func (p Pulplogic) HeaderLocation() panel.Point {
 func (p Pulplogic) FooterLocation() panel.Point {
 	return panel.Point{X: p.Width() / 2.0, Y: p.MountingHoleBottomY()}
 }
+
+// MountingSlotLength returns the overall length of the mounting slots, or
+// zero if the panel has round mounting holes
+func (p Pulplogic) MountingSlotLength() float64 {
+	return p.SlotLength
+}
+
+// SetMountingSlotLength sets the overall length of the mounting slots. Use
+// zero for round mounting holes
+func (p *Pulplogic) SetMountingSlotLength(length float64) {
+	p.SlotLength = length
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "pulplogic",
+		Description: "Pulplogic 1U, per Pulplogic spec",
+		Units:       "HP",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			return NewPulplogic(opts.Width), nil
+		},
+		WidthForBoard: func(width, height float64) int {
+			return int(math.Ceil(math.Ceil(width) / HP))
+		},
+	})
+}
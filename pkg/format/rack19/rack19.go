@@ -0,0 +1,248 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package rack19
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// EIA-310 19" rack panels. Unlike the modular synth formats, rack panels are
+// a fixed width and vary in height, in multiples of the rack unit (U). They
+// are fixed to vertical rails at either side, conventionally through
+// horizontal slots to allow for the tolerance of the rails. As the rails are
+// at the sides, rack panels declare keepouts in front of them rather than
+// implementing panel.Rails.
+//
+// Half-rack panels are mounted in pairs, joined to each other in the middle
+// of the rack by a bracket, so only one edge of each sits on a rail. They
+// are modelled as the left-hand panel of a pair, with mounting holes and a
+// rail keepout on the left only; the right-hand panel is its mirror image.
+
+const (
+	inch = 25.4
+
+	// Unit represents one rack unit, in millimetres
+	Unit = 1.75 * inch
+
+	// HeightClearance represents how much shorter than a whole number of rack
+	// units a panel is, so that adjacent panels fit, in millimetres. Half is
+	// taken from the top edge and half from the bottom.
+	HeightClearance = 0.79
+
+	// MaxUnits is the tallest supported panel, in rack units
+	MaxUnits = 4
+
+	// PanelWidth represents the width of a full-width rack panel, in
+	// millimetres
+	PanelWidth = 482.6
+
+	// HalfRackWidth represents the width of a half-rack panel, in millimetres
+	HalfRackWidth = PanelWidth / 2
+
+	// MountingHoleSpacing represents the distance between the centres of the
+	// left and right mounting holes of a full-width panel, in millimetres
+	MountingHoleSpacing = 465.1
+
+	// MountingHolesOffset represents the distance of the mounting holes from
+	// the left and right edges of the panel, in millimetres
+	MountingHolesOffset = (PanelWidth - MountingHoleSpacing) / 2
+
+	// MountingHoleLowerY represents the height of the lower mounting hole
+	// above the bottom of each rack unit, in millimetres. EIA-310 rails have
+	// three holes per unit; panels use the outer two.
+	MountingHoleLowerY = 0.25 * inch
+
+	// MountingHoleUpperY represents the height of the upper mounting hole
+	// above the bottom of each rack unit, in millimetres
+	MountingHoleUpperY = Unit - (0.25 * inch)
+
+	// MountingHoleDiameter represents the diameter of a rack mounting hole,
+	// suiting M6, 10-32 and 12-24 rack screws, in millimetres
+	MountingHoleDiameter = 6.5
+
+	// DefaultSlotLength represents the overall length of the usual
+	// horizontal mounting slots, in millimetres
+	DefaultSlotLength = 10.0
+
+	// RailWidth represents the width of the area at either side of the panel
+	// that sits in front of the rack rails, and so can't have anything
+	// mounted behind it, in millimetres. The clear opening between rails is
+	// 450mm wide.
+	RailWidth = (PanelWidth - 450.0) / 2
+
+	// HorizontalFit indicates the panel tolerance adjustment for the format
+	HorizontalFit = 0.0
+
+	// CornerRadius indicates the corner radius for the format
+	CornerRadius = 0.0
+)
+
+// Rack19 implements the panel.Panel interface and encapsulates the physical
+// characteristics of a 19" rack panel
+type Rack19 struct {
+	Units int
+	// HalfRack selects a half-width panel, as used with rack joining kits.
+	// Half-width panels are only fixed on the left.
+	HalfRack bool
+	// SlotLength is the overall length of oval mounting slots, or zero for
+	// round mounting holes
+	SlotLength float64
+}
+
+// NewRack19 constructs a new Rack19 object, with the usual mounting slots
+func NewRack19(units int, halfRack bool) *Rack19 {
+	return &Rack19{Units: units, HalfRack: halfRack, SlotLength: DefaultSlotLength}
+}
+
+// UnitsForHeight returns the number of rack units needed to fit a board of
+// the given height, in millimetres
+func UnitsForHeight(height float64) int {
+	return int(math.Max(1, math.Ceil((height+HeightClearance)/Unit)))
+}
+
+// Width returns the width of a rack panel, in millimetres
+func (r Rack19) Width() float64 {
+	if r.HalfRack {
+		return HalfRackWidth
+	}
+	return PanelWidth
+}
+
+// Height returns the height of a rack panel, in millimetres
+func (r Rack19) Height() float64 {
+	return Unit*float64(r.Units) - HeightClearance
+}
+
+// MountingHoleDiameter returns the rack mounting hole size, in millimetres
+func (r Rack19) MountingHoleDiameter() float64 {
+	return MountingHoleDiameter
+}
+
+// MountingHoles generates a set of Point objects representing the mounting
+// hole locations of a rack panel: the outer two of the three rail holes in
+// each rack unit, on both sides of a full-width panel or the left side of a
+// half-width one
+func (r Rack19) MountingHoles() []panel.Point {
+	lhsx := MountingHolesOffset
+	rhsx := r.Width() - MountingHolesOffset
+	holes := []panel.Point{}
+	for u := 0; u < r.Units; u++ {
+		for _, y := range []float64{MountingHoleLowerY, MountingHoleUpperY} {
+			y = Unit*float64(u) + y - HeightClearance/2
+			holes = append(holes, panel.Point{X: lhsx, Y: y})
+			if !r.HalfRack {
+				holes = append(holes, panel.Point{X: rhsx, Y: y})
+			}
+		}
+	}
+	return holes
+}
+
+// HorizontalFit indicates the panel tolerance adjustment for the format
+func (r Rack19) HorizontalFit() float64 {
+	return HorizontalFit
+}
+
+// CornerRadius indicates the corner radius for the format
+func (r Rack19) CornerRadius() float64 {
+	return CornerRadius
+}
+
+// MountingHoleTopY returns the Y coordinate for the top row of mounting
+// holes
+func (r Rack19) MountingHoleTopY() float64 {
+	return r.Height() - MountingHoleLowerY + HeightClearance/2
+}
+
+// MountingHoleBottomY returns the Y coordinate for the bottom row of
+// mounting holes
+func (r Rack19) MountingHoleBottomY() float64 {
+	return MountingHoleLowerY - HeightClearance/2
+}
+
+// HeaderLocation returns the location of the header text, aligned with the
+// top mounting holes
+func (r Rack19) HeaderLocation() panel.Point {
+	return panel.Point{X: r.Width() / 2, Y: r.MountingHoleTopY()}
+}
+
+// FooterLocation returns the location of the footer text, aligned with the
+// bottom mounting holes
+func (r Rack19) FooterLocation() panel.Point {
+	return panel.Point{X: r.Width() / 2, Y: r.MountingHoleBottomY()}
+}
+
+// Keepouts returns the areas in front of the rack rails, at the left and
+// right edges of a full-width panel or the left edge of a half-width one
+func (r Rack19) Keepouts() []panel.Keepout {
+	keepouts := []panel.Keepout{
+		{
+			Region: panel.Region{Shape: panel.ShapeRectangle, X1: 0, Y1: 0, X2: RailWidth, Y2: r.Height()},
+			Side:   panel.SideBoth,
+		},
+	}
+	if !r.HalfRack {
+		keepouts = append(keepouts, panel.Keepout{
+			Region: panel.Region{Shape: panel.ShapeRectangle, X1: r.Width() - RailWidth, Y1: 0, X2: r.Width(), Y2: r.Height()},
+			Side:   panel.SideBoth,
+		})
+	}
+	return keepouts
+}
+
+// MountingSlotLength returns the overall length of the mounting slots, or
+// zero for round mounting holes
+func (r Rack19) MountingSlotLength() float64 {
+	return r.SlotLength
+}
+
+// SetMountingSlotLength sets the overall length of the mounting slots. Use
+// zero for round mounting holes
+func (r *Rack19) SetMountingSlotLength(length float64) {
+	r.SlotLength = length
+}
+
+func init() {
+	for _, half := range []bool{false, true} {
+		half := half
+		name, description := "rack19", "19\" rack panel, full width, per EIA-310"
+		if half {
+			name, description = "rack19-half", "19\" rack panel, half width, left rail only, per EIA-310"
+		}
+		panel.Register(panel.Format{
+			Name:        name,
+			Description: description,
+			Units:       "rack units of height",
+			New: func(opts panel.Options) (panel.Panel, error) {
+				if opts.Width < 1 || opts.Width > MaxUnits {
+					return nil, fmt.Errorf("rack panels must be between 1U and %dU", MaxUnits)
+				}
+				return NewRack19(opts.Width, half), nil
+			},
+			WidthForBoard: func(width, height float64) int {
+				return UnitsForHeight(height)
+			},
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package rack19
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitsForHeight(t *testing.T) {
+	tests := []struct {
+		height float64
+		want   int
+	}{
+		{height: 0, want: 1},
+		{height: 43.66, want: 1},
+		{height: 43.7, want: 2},
+		{height: 100, want: 3},
+		{height: 177, want: 4},
+	}
+	for _, test := range tests {
+		if got := UnitsForHeight(test.height); got != test.want {
+			t.Errorf("%vmm board: got %dU, want %dU", test.height, got, test.want)
+		}
+	}
+}
+
+func TestMountingHoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		units    int
+		halfRack bool
+		// X positions of the mounting hole columns
+		columns []float64
+	}{
+		{name: "1U", units: 1, columns: []float64{8.75, 473.85}},
+		{name: "3U", units: 3, columns: []float64{8.75, 473.85}},
+		// half-rack panels are joined to their partner at the right edge
+		{name: "1U half", units: 1, halfRack: true, columns: []float64{8.75}},
+		{name: "2U half", units: 2, halfRack: true, columns: []float64{8.75}},
+	}
+	for _, test := range tests {
+		r := NewRack19(test.units, test.halfRack)
+		holes := r.MountingHoles()
+		if len(holes) != 2*test.units*len(test.columns) {
+			t.Errorf("%s: got holes %v, want two per unit in columns %v", test.name, holes, test.columns)
+			continue
+		}
+		for index, hole := range holes {
+			x := test.columns[index%len(test.columns)]
+			row := index / len(test.columns)
+			y := Unit*float64(row/2) + []float64{MountingHoleLowerY, MountingHoleUpperY}[row%2] - HeightClearance/2
+			if math.Abs(hole.X-x) > 1e-9 || math.Abs(hole.Y-y) > 1e-9 {
+				t.Errorf("%s: hole %d is at %v, want (%v,%v)", test.name, index, hole, x, y)
+			}
+		}
+	}
+}
+
+func TestKeepouts(t *testing.T) {
+	full := NewRack19(2, false).Keepouts()
+	if len(full) != 2 {
+		t.Fatalf("full-width panel: got keepouts %v, want one per rail", full)
+	}
+	if full[0].X1 != 0 || math.Abs(full[0].X2-16.3) > 1e-9 {
+		t.Errorf("full-width panel: left keepout is %v-%v, want 0-16.3", full[0].X1, full[0].X2)
+	}
+	if math.Abs(full[1].X1-466.3) > 1e-9 || full[1].X2 != PanelWidth {
+		t.Errorf("full-width panel: right keepout is %v-%v, want 466.3-%v", full[1].X1, full[1].X2, PanelWidth)
+	}
+	half := NewRack19(2, true).Keepouts()
+	if len(half) != 1 || half[0].X1 != 0 || half[0].X2 != full[0].X2 {
+		t.Errorf("half-width panel: got keepouts %v, want the left rail only", half)
+	}
+	for _, k := range append(full, half...) {
+		if k.Y1 != 0 || k.Y2 != NewRack19(2, false).Height() {
+			t.Errorf("keepout %v does not cover the panel height", k)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package serge
+
+import (
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/panel"
+)
+
+// Serge 4U format, as used by Random*Source and others. Panels are multiples
+// of 2.125 inches wide and 7 inches high. The mounting holes sit closer to
+// the panel edges than Buchla's, and single-width panels are only fixed on
+// the left.
+
+const (
+	inch = 25.4
+
+	// PanelHeight represents the total height of a Serge 4U panel, in
+	// millimetres
+	PanelHeight = 7.0 * inch
+
+	// Unit represents the width of the narrowest Serge panel, in millimetres.
+	// Wider panels are a multiple of this.
+	Unit = 2.125 * inch
+
+	// MountingHolesLeftOffset represents the distance of the left mounting
+	// holes from the left edge of the panel, in millimetres
+	MountingHolesLeftOffset = 0.2 * inch
+
+	// MountingHolesRightOffset represents the distance of the right mounting
+	// holes from the right edge of the panel, in millimetres
+	MountingHolesRightOffset = 0.2 * inch
+
+	// MountingHoleTopY represents the Y value for the top row of mounting
+	// holes, in millimetres
+	MountingHoleTopY = PanelHeight - (0.15 * inch)
+
+	// MountingHoleBottomY represents the Y value for the bottom row of
+	// mounting holes, in millimetres
+	MountingHoleBottomY = 0.15 * inch
+
+	// MountingHoleDiameter represents the diameter of a Serge mounting hole,
+	// suiting 6-32 screws, in millimetres
+	MountingHoleDiameter = 0.14 * inch
+
+	// ExtraMountingHolesThreshold represents the panel width, in units, from
+	// which mounting holes are needed on the right as well as the left
+	ExtraMountingHolesThreshold = 2
+
+	// HorizontalFit indicates the panel tolerance adjustment for the format
+	HorizontalFit = 0.25
+
+	// CornerRadius indicates the corner radius for the format
+	CornerRadius = 0.0
+
+	// RailHeightFromMountingHole represents how far a Serge rail extends
+	// inwards from the centre of its mounting holes, in millimetres. Serge
+	// holes sit nearer the panel edge than Buchla's, so the Buchla figure
+	// leaves some margin here.
+	RailHeightFromMountingHole = 0.25 * inch
+)
+
+// Serge implements the panel.Panel interface and encapsulates the physical
+// characteristics of a Serge panel
+type Serge struct {
+	Units int
+}
+
+// NewSerge constructs a new Serge object
+func NewSerge(units int) *Serge {
+	return &Serge{Units: units}
+}
+
+// UnitsForWidth returns the number of Serge units needed to fit a board of
+// the given width, in millimetres
+func UnitsForWidth(width float64) int {
+	return int(math.Max(1, math.Ceil(width/Unit)))
+}
+
+// Width returns the width of a Serge panel, in millimetres
+func (s Serge) Width() float64 {
+	return Unit * float64(s.Units)
+}
+
+// Height returns the height of a Serge panel, in millimetres
+func (s Serge) Height() float64 {
+	return PanelHeight
+}
+
+// MountingHoleDiameter returns the Serge mounting hole size, in millimetres
+func (s Serge) MountingHoleDiameter() float64 {
+	return MountingHoleDiameter
+}
+
+// MountingHoles generates a set of Point objects representing the mounting
+// hole locations of a Serge panel. Narrow panels are only fixed on the left.
+func (s Serge) MountingHoles() []panel.Point {
+	holes := []panel.Point{
+		{X: MountingHolesLeftOffset, Y: MountingHoleBottomY},
+		{X: MountingHolesLeftOffset, Y: MountingHoleTopY},
+	}
+	if s.Units >= ExtraMountingHolesThreshold {
+		rhsx := s.Width() - MountingHolesRightOffset
+		holes = append(holes, panel.Point{X: rhsx, Y: MountingHoleBottomY})
+		holes = append(holes, panel.Point{X: rhsx, Y: MountingHoleTopY})
+	}
+	return holes
+}
+
+// HorizontalFit indicates the panel tolerance adjustment for the format
+func (s Serge) HorizontalFit() float64 {
+	return HorizontalFit
+}
+
+// CornerRadius indicates the corner radius for the format
+func (s Serge) CornerRadius() float64 {
+	return CornerRadius
+}
+
+// RailHeightFromMountingHole is used to calculate space between rails
+func (s Serge) RailHeightFromMountingHole() float64 {
+	return RailHeightFromMountingHole
+}
+
+// MountingHoleTopY returns the Y coordinate for the top row of mounting
+// holes
+func (s Serge) MountingHoleTopY() float64 {
+	return MountingHoleTopY
+}
+
+// MountingHoleBottomY returns the Y coordinate for the bottom row of
+// mounting holes
+func (s Serge) MountingHoleBottomY() float64 {
+	return MountingHoleBottomY
+}
+
+// HeaderLocation returns the location of the header text. Serge has
+// mounting rails so this is aligned with the top mounting screws
+func (s Serge) HeaderLocation() panel.Point {
+	return panel.Point{X: s.Width() / 2, Y: s.MountingHoleTopY()}
+}
+
+// FooterLocation returns the location of the footer text. Serge has
+// mounting rails so this is aligned with the bottom mounting screws
+func (s Serge) FooterLocation() panel.Point {
+	return panel.Point{X: s.Width() / 2, Y: s.MountingHoleBottomY()}
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "serge",
+		Description: "Serge 4U",
+		Units:       "2.125\" Serge units",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			return NewSerge(opts.Width), nil
+		},
+		WidthForBoard: func(width, height float64) int {
+			return UnitsForWidth(width)
+		},
+	})
+}
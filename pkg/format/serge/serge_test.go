@@ -0,0 +1,64 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package serge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMountingHoles(t *testing.T) {
+	tests := []struct {
+		units int
+		// X positions of the mounting hole columns
+		want []float64
+	}{
+		// single-width panels are only fixed on the left
+		{units: 1, want: []float64{5.08}},
+		{units: 2, want: []float64{5.08, 102.87}},
+		{units: 4, want: []float64{5.08, 210.82}},
+	}
+	for _, test := range tests {
+		holes := NewSerge(test.units).MountingHoles()
+		if len(holes) != 2*len(test.want) {
+			t.Errorf("%d units: got holes %v, want columns at %v", test.units, holes, test.want)
+			continue
+		}
+		for index, hole := range holes {
+			x := test.want[index/2]
+			y := []float64{3.81, 173.99}[index%2]
+			if math.Abs(hole.X-x) > 1e-9 || math.Abs(hole.Y-y) > 1e-9 {
+				t.Errorf("%d units: hole %d is at %v, want (%v,%v)", test.units, index, hole, x, y)
+			}
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	for _, width := range []float64{10, 53.975, 54, 150} {
+		units := UnitsForWidth(width)
+		panelWidth := NewSerge(units).Width()
+		// the smallest panel that fits, in whole 2.125 inch units
+		if panelWidth < width || panelWidth-width >= 53.975 {
+			t.Errorf("%vmm board: got %d units, %vmm wide", width, units, panelWidth)
+		}
+	}
+}
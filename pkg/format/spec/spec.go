@@ -23,23 +23,49 @@ package spec
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"sort"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/jsleeio/go-eagle/pkg/dxf"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
 	"github.com/jsleeio/go-eagle/pkg/panel"
 )
 
+const (
+	// outlineTolerance is the maximum deviation from true arcs when sizing
+	// panels with curved outlines
+	outlineTolerance = 0.01
+)
+
 // Spec implements the panel.Panel interface and encapsulates the physical
 // characteristics of a Spec panel
 type Spec struct {
-	SpecName                 string        `yaml:"name"`
-	SpecWidth                float64       `yaml:"width"`
-	SpecHeight               float64       `yaml:"height"`
-	SpecMountingHoles        []panel.Point `yaml:"mountingHoles"`
-	SpecMountingHoleDiameter float64       `yaml:"mountingHoleDiameter"`
-	SpecHorizontalFit        float64       `yaml:"horizontalFit"`
-	SpecCornerRadius         float64       `yaml:"cornerRadius"`
+	SpecName                 string          `yaml:"name"`
+	SpecWidth                float64         `yaml:"width"`
+	SpecHeight               float64         `yaml:"height"`
+	SpecMountingHoles        []panel.Point   `yaml:"mountingHoles"`
+	SpecMountingHoleDiameter float64         `yaml:"mountingHoleDiameter"`
+	SpecHorizontalFit        float64         `yaml:"horizontalFit"`
+	SpecCornerRadius         float64         `yaml:"cornerRadius"`
+	SpecKeepouts             []panel.Keepout `yaml:"keepouts"`
+	SpecMountingSlotLength   float64         `yaml:"mountingSlotLength"`
+	SpecCutouts              []panel.Cutout  `yaml:"cutouts"`
+	SpecHeader               *panel.Point    `yaml:"header"`
+	SpecFooter               *panel.Point    `yaml:"footer"`
+	SpecOutline              *Outline        `yaml:"outline"`
+	SpecCorners              *panel.Corners  `yaml:"corners"`
+}
+
+// Outline describes a non-rectangular panel outline, either directly as a
+// polygon that may have curved edges, or as the outermost shape in a DXF
+// file. Curves are as for Eagle polygon vertices: the angle in degrees swept
+// by the edge to the next vertex, positive for counter-clockwise.
+type Outline struct {
+	Vertices []geometry.Vertex `yaml:"vertices"`
+	// DXF is the name of a DXF file, relative to the spec file
+	DXF string `yaml:"dxf"`
 }
 
 type PanelSpecError struct {
@@ -67,12 +93,70 @@ func LoadSpec(filename string) (*Spec, error) {
 	if len(sp.SpecMountingHoles) < 1 {
 		return nil, NewPanelSpecError("need at least one mounting hole")
 	}
+	for index, keepout := range sp.SpecKeepouts {
+		if err := keepout.Validate(); err != nil {
+			return nil, NewPanelSpecError(fmt.Sprintf("keepout %d: %v", index+1, err))
+		}
+	}
+	for index, cutout := range sp.SpecCutouts {
+		if err := cutout.Validate(); err != nil {
+			return nil, NewPanelSpecError(fmt.Sprintf("cutout %d: %v", index+1, err))
+		}
+	}
+	if sp.SpecOutline != nil {
+		if err := sp.loadOutline(filepath.Dir(filename)); err != nil {
+			return nil, err
+		}
+	}
+	if sp.SpecCorners != nil {
+		if err := sp.SpecCorners.Validate(sp.SpecWidth-sp.SpecHorizontalFit, sp.SpecHeight); err != nil {
+			return nil, NewPanelSpecError(err.Error())
+		}
+	}
 	sort.Slice(sp.SpecMountingHoles, func(i, j int) bool {
 		return sp.SpecMountingHoles[i].Y < sp.SpecMountingHoles[j].Y
 	})
 	return &sp, nil
 }
 
+// loadOutline reads any DXF outline, makes sure the outline runs
+// counter-clockwise, moves it so that its bottom left corner is at the
+// origin, and sizes the panel to fit it if the YAML file doesn't say
+// otherwise
+func (s *Spec) loadOutline(dir string) error {
+	o := s.SpecOutline
+	if o.DXF != "" {
+		if len(o.Vertices) > 0 {
+			return NewPanelSpecError("outline may have vertices or a DXF file, not both")
+		}
+		drawing, err := dxf.LoadFile(filepath.Join(dir, o.DXF))
+		if err != nil {
+			return NewPanelSpecError(fmt.Sprintf("outline DXF file %q: %v", o.DXF, err))
+		}
+		o.Vertices = drawing.Largest()
+	}
+	if len(o.Vertices) < 2 {
+		return NewPanelSpecError("outline needs at least two vertices")
+	}
+	points := geometry.Flatten(o.Vertices, outlineTolerance)
+	if geometry.Area(points) < 0 {
+		o.Vertices = geometry.Reverse(o.Vertices)
+	}
+	// drawings are rarely drawn from the origin, but the panel always is
+	minX, minY, maxX, maxY := geometry.Bounds(points)
+	for index := range o.Vertices {
+		o.Vertices[index].X -= minX
+		o.Vertices[index].Y -= minY
+	}
+	if s.SpecWidth == 0 {
+		s.SpecWidth = maxX - minX
+	}
+	if s.SpecHeight == 0 {
+		s.SpecHeight = maxY - minY
+	}
+	return nil
+}
+
 // Width returns the width of a Spec panel, in millimetres
 func (s Spec) Width() float64 {
 	return s.SpecWidth
@@ -106,39 +190,77 @@ func (s Spec) CornerRadius() float64 {
 	return s.SpecCornerRadius
 }
 
-// RailHeightFromMountingHole doesn't really directly apply to YAML-spec
-// panels where the keepout area is more likely to be a ring around each
-// mounting hole, with the thickness of the ring likely varying with the
-// mounting hole diameter. For now, keep it simple and return 0. The PCB
-// designer will need to be careful regardless in order to fit their
-// design within the given enclosure's envelope.
-//
-// FIXME: fix the interface design here to better facilitate non-system
-//        panels (without making it unnecessarily awful!)
-func (s Spec) RailHeightFromMountingHole() float64 {
-	return 0.0
+// HeaderLocation returns the location of the header text. Spec panels may
+// not have mounting rails, so unless the YAML file says otherwise, this is
+// aligned with the topmost mounting hole
+func (s Spec) HeaderLocation() panel.Point {
+	if s.SpecHeader != nil {
+		return *s.SpecHeader
+	}
+	return panel.Point{X: s.Width() / 2, Y: s.SpecMountingHoles[len(s.SpecMountingHoles)-1].Y}
 }
 
-// MountingHoleTopY returns the Y coordinate for the top row of mounting
-// holes
-func (s Spec) MountingHoleTopY() float64 {
-	return s.SpecMountingHoles[0].Y
+// FooterLocation returns the location of the footer text. Spec panels may
+// not have mounting rails, so unless the YAML file says otherwise, this is
+// aligned with the bottommost mounting hole
+func (s Spec) FooterLocation() panel.Point {
+	if s.SpecFooter != nil {
+		return *s.SpecFooter
+	}
+	return panel.Point{X: s.Width() / 2, Y: s.SpecMountingHoles[0].Y}
 }
 
-// MountingHoleBottomY returns the Y coordinate for the bottom row of
-// mounting holes
-func (s Spec) MountingHoleBottomY() float64 {
-	return s.SpecMountingHoles[len(s.SpecMountingHoles)-1].Y
+// Keepouts returns the areas of a Spec panel that must be kept clear, eg.
+// around the screw bosses of an enclosure
+func (s Spec) Keepouts() []panel.Keepout {
+	return s.SpecKeepouts
 }
 
-// HeaderLocation returns the location of the header text. Spec panels
-// may not have mounting rails so this is entirely arbitrary
-func (s Spec) HeaderLocation() panel.Point {
-	return panel.Point{X: s.Width() / 2, Y: s.MountingHoleTopY()}
+// Corners returns the shapes of the corners of a Spec panel. Unless the
+// YAML file describes each corner, they are all rounded to the same radius.
+func (s Spec) Corners() panel.Corners {
+	if s.SpecCorners != nil {
+		return *s.SpecCorners
+	}
+	return panel.UniformCorners(s.SpecCornerRadius)
 }
 
-// FooterLocation returns the location of the footer text. Spec panels
-// may not have mounting rails so this is entirely arbitrary
-func (s Spec) FooterLocation() panel.Point {
-	return panel.Point{X: s.Width() / 2, Y: s.MountingHoleBottomY()}
+// Outline returns the outline of a Spec panel, or nil if it is rectangular
+func (s Spec) Outline() []geometry.Vertex {
+	if s.SpecOutline == nil {
+		return nil
+	}
+	return s.SpecOutline.Vertices
+}
+
+// Cutouts returns the openings of a Spec panel that aren't associated with
+// any component
+func (s Spec) Cutouts() []panel.Cutout {
+	return s.SpecCutouts
+}
+
+// MountingSlotLength returns the overall length of the mounting slots, or
+// zero if the panel has round mounting holes
+func (s Spec) MountingSlotLength() float64 {
+	return s.SpecMountingSlotLength
+}
+
+// SetMountingSlotLength sets the overall length of the mounting slots. Use
+// zero for round mounting holes
+func (s *Spec) SetMountingSlotLength(length float64) {
+	s.SpecMountingSlotLength = length
+}
+
+func init() {
+	panel.Register(panel.Format{
+		Name:        "spec",
+		Description: "custom enclosure spec defined in a YAML file (see -spec-file)",
+		New: func(opts panel.Options) (panel.Panel, error) {
+			sp, err := LoadSpec(opts.SpecFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading YAML panel spec from '%v': %v", opts.SpecFile, err)
+			}
+			return sp, nil
+		},
+	})
 }
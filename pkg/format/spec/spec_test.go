@@ -0,0 +1,119 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package spec
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// loadYAML writes a spec file to a temporary directory and loads it
+func loadYAML(t *testing.T, yaml string) *Spec {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "spec.yaml")
+	if err := ioutil.WriteFile(filename, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := LoadSpec(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sp
+}
+
+func TestLoadOutline(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		width, height float64
+		want          []geometry.Vertex
+	}{
+		{
+			name: "drawn away from the origin, clockwise",
+			yaml: `
+mountingHoles: [{ x: 5, y: 5 }]
+outline:
+  vertices: [{ x: 100, y: 50 }, { x: 100, y: 80 }, { x: 140, y: 80 }, { x: 140, y: 50 }]
+`,
+			width:  40,
+			height: 30,
+			want:   []geometry.Vertex{{X: 40, Y: 0}, {X: 40, Y: 30}, {X: 0, Y: 30}, {X: 0, Y: 0}},
+		},
+		{
+			// the arc bulges below the lowest vertex, by its sagitta
+			name: "curved edge below the origin",
+			yaml: `
+mountingHoles: [{ x: 5, y: 5 }]
+width: 50
+outline:
+  vertices: [{ x: 0, y: 0, curve: 180 }, { x: 20, y: 0 }, { x: 20, y: 20 }, { x: 0, y: 20 }]
+`,
+			width:  50,
+			height: 30,
+			want: []geometry.Vertex{
+				{X: 0, Y: 10, Curve: 180}, {X: 20, Y: 10}, {X: 20, Y: 30}, {X: 0, Y: 30},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sp := loadYAML(t, test.yaml)
+			if math.Abs(sp.Width()-test.width) > 1e-3 || math.Abs(sp.Height()-test.height) > 1e-3 {
+				t.Errorf("got %vx%v panel, want %vx%v", sp.Width(), sp.Height(), test.width, test.height)
+			}
+			got := sp.Outline()
+			if len(got) != len(test.want) {
+				t.Fatalf("got outline %v, want %v", got, test.want)
+			}
+			for index := range got {
+				g, w := got[index], test.want[index]
+				if math.Abs(g.X-w.X) > 1e-3 || math.Abs(g.Y-w.Y) > 1e-3 || g.Curve != w.Curve {
+					t.Errorf("vertex %d: got %+v, want %+v", index, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadOutlineDXF(t *testing.T) {
+	sp, err := LoadSpec("../../../enclosures/spec-test-angled.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the top edge bows down from the back of the panel, so the corner at
+	// the back is the highest point
+	if math.Abs(sp.Width()-150) > 1e-9 || math.Abs(sp.Height()-80) > 1e-9 {
+		t.Errorf("got %vx%v panel, want 150x80", sp.Width(), sp.Height())
+	}
+	if len(sp.Outline()) != 4 {
+		t.Errorf("got outline %v, want 4 vertices", sp.Outline())
+	}
+}
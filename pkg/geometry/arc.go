@@ -0,0 +1,65 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import "math"
+
+// ArcCentre finds the centre and radius of the arc described by an Eagle
+// curved wire or polygon edge, which runs from (x1,y1) to (x2,y2) and sweeps
+// curve degrees. Positive curve values sweep counter-clockwise.
+func ArcCentre(x1, y1, x2, y2, curve float64) (cx, cy, r float64) {
+	dx, dy := x2-x1, y2-y1
+	chord := math.Hypot(dx, dy)
+	half := curve * math.Pi / 360.0
+	// signed distance from the chord midpoint to the centre, along the
+	// chord's left-hand normal
+	d := (chord / 2) / math.Tan(half)
+	cx = (x1+x2)/2 - d*dy/chord
+	cy = (y1+y2)/2 + d*dx/chord
+	r = math.Abs((chord / 2) / math.Sin(half))
+	return cx, cy, r
+}
+
+// ArcPoints approximates an Eagle arc with a series of points, including
+// both endpoints. The number of segments is chosen so that no segment
+// deviates from the true arc by more than tolerance millimetres.
+func ArcPoints(x1, y1, x2, y2, curve, tolerance float64) []Point {
+	if curve == 0 {
+		return []Point{{X: x1, Y: y1}, {X: x2, Y: y2}}
+	}
+	cx, cy, r := ArcCentre(x1, y1, x2, y2, curve)
+	start := math.Atan2(y1-cy, x1-cx)
+	sweep := curve * math.Pi / 180.0
+	segments := 1
+	if tolerance > 0 && tolerance < r {
+		step := 2 * math.Acos(1-tolerance/r)
+		segments = int(math.Ceil(math.Abs(sweep) / step))
+	}
+	if segments < 1 {
+		segments = 1
+	}
+	points := []Point{{X: x1, Y: y1}}
+	for i := 1; i < segments; i++ {
+		angle := start + sweep*float64(i)/float64(segments)
+		points = append(points, Point{X: cx + r*math.Cos(angle), Y: cy + r*math.Sin(angle)})
+	}
+	return append(points, Point{X: x2, Y: y2})
+}
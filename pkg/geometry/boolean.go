@@ -0,0 +1,508 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// regionGrid is the resolution, in millimetres, that region vertices
+	// are snapped to, so that edges meeting at a point really do meet
+	regionGrid = 1e-6
+
+	// regionProbe is how far either side of an edge a region is sampled to
+	// decide whether the edge is part of the region's boundary
+	regionProbe = 1e-5
+)
+
+// Region is an area made up of closed rings of points, each implicitly
+// closed. A point lies inside the region where the rings wind around it a
+// positive number of times in total, so outside boundaries run
+// counter-clockwise and holes run clockwise. Rings may overlap; the region
+// is then their union.
+type Region [][]Point
+
+// NewRegion approximates a closed polygon that may have curved edges with a
+// region. The polygon may run in either direction.
+func NewRegion(vertices []Vertex, tolerance float64) Region {
+	if len(vertices) == 0 {
+		return Region{}
+	}
+	return Region{Flatten(CounterClockwise(vertices), tolerance)}
+}
+
+// CircleRegion approximates a circle with a region
+func CircleRegion(x, y, r, tolerance float64) Region {
+	return NewRegion([]Vertex{{X: x + r, Y: y, Curve: 180}, {X: x - r, Y: y, Curve: 180}}, tolerance)
+}
+
+// Winding returns the total number of times the rings of a region wind
+// counter-clockwise around a point
+func (r Region) Winding(p Point) int {
+	winding := 0
+	for _, ring := range r {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[j], ring[i]
+			if a.Y <= p.Y {
+				if b.Y > p.Y && cross(a, b, p) > 0 {
+					winding++
+				}
+			} else if b.Y <= p.Y && cross(a, b, p) < 0 {
+				winding--
+			}
+		}
+	}
+	return winding
+}
+
+// Contains reports whether a point lies inside a region
+func (r Region) Contains(p Point) bool {
+	return r.Winding(p) > 0
+}
+
+// Area returns the area of a region whose rings don't overlap, such as
+// the result of any of the region operations
+func (r Region) Area() float64 {
+	area := 0.0
+	for _, ring := range r {
+		area += Area(ring)
+	}
+	return area
+}
+
+// Union returns the area covered by either of two regions
+func Union(a, b Region) Region {
+	return boolean(a, b, func(inA, inB bool) bool { return inA || inB })
+}
+
+// Intersection returns the area covered by both of two regions
+func Intersection(a, b Region) Region {
+	return boolean(a, b, func(inA, inB bool) bool { return inA && inB })
+}
+
+// Difference returns the area covered by region a but not by region b
+func Difference(a, b Region) Region {
+	return boolean(a, b, func(inA, inB bool) bool { return inA && !inB })
+}
+
+// Offset grows a region by distance millimetres in every direction, or
+// shrinks it for negative distances. Corners that grow are rounded, with
+// arcs approximated to within tolerance millimetres.
+func Offset(r Region, distance, tolerance float64) Region {
+	if distance == 0 {
+		return boolean(r, nil, func(inA, _ bool) bool { return inA })
+	}
+	raw := Region{}
+	for _, ring := range r {
+		raw = append(raw, offsetRing(ring, distance, tolerance))
+	}
+	return boolean(raw, nil, func(inA, _ bool) bool { return inA })
+}
+
+// Stroke returns the area covered by drawing a line of the given width
+// through a series of points, with round ends and joins
+func Stroke(points []Point, width, tolerance float64) Region {
+	if len(points) == 0 || width <= 0 {
+		return Region{}
+	}
+	// a ring running there and back again has no area of its own, so
+	// offsetting it gives just the stroke
+	ring := append([]Point{}, points...)
+	for index := len(points) - 2; index > 0; index-- {
+		ring = append(ring, points[index])
+	}
+	if len(points) == 1 {
+		return CircleRegion(points[0].X, points[0].Y, width/2, tolerance)
+	}
+	return Offset(Region{ring}, width/2, tolerance)
+}
+
+// offsetRing moves each edge of a ring to its right by distance, or to its
+// left for negative distances, and joins the moved edges. Where the edges
+// move apart they are joined by an arc around the original vertex; where
+// they overlap they are joined through the original vertex, leaving loops
+// that wind the wrong way and so drop out when the ring is cleaned up.
+func offsetRing(ring []Point, distance, tolerance float64) []Point {
+	n := len(ring)
+	offset := []Point{}
+	for index, p := range ring {
+		prev := ring[(index+n-1)%n]
+		next := ring[(index+1)%n]
+		inX, inY, inLen := unit(p.X-prev.X, p.Y-prev.Y)
+		outX, outY, outLen := unit(next.X-p.X, next.Y-p.Y)
+		if inLen == 0 || outLen == 0 {
+			continue
+		}
+		// right-hand normals of the incoming and outgoing edges
+		start := Point{X: p.X + distance*inY, Y: p.Y - distance*inX}
+		end := Point{X: p.X + distance*outY, Y: p.Y - distance*outX}
+		turn := math.Atan2(inX*outY-inY*outX, inX*outX+inY*outY)
+		if math.Abs(turn) > math.Pi-1e-9 {
+			// doubling back, eg. at the ends of a stroke, always needs a
+			// round end, whichever way the offset goes
+			turn = math.Copysign(math.Pi, distance)
+		}
+		switch {
+		case math.Abs(turn) < 1e-9:
+			offset = append(offset, start)
+		case turn*distance > 0:
+			offset = append(offset, ArcPoints(start.X, start.Y, end.X, end.Y, turn*180/math.Pi, tolerance)...)
+		default:
+			offset = append(offset, start, p, end)
+		}
+	}
+	return offset
+}
+
+// segment is a straight edge of a region ring
+type segment struct {
+	a, b Point
+}
+
+// boolean combines two regions, keeping the areas for which keep returns
+// true given whether they are inside each region. Every edge of both
+// regions is split wherever it meets another, and each piece is kept as
+// part of the result's boundary if the result is inside on one side of it
+// and outside on the other. The kept pieces are then linked into rings.
+func boolean(a, b Region, keep func(inA, inB bool) bool) Region {
+	a, b = snapRegion(a), snapRegion(b)
+	edges := append(regionSegments(a), regionSegments(b)...)
+	seen := map[[2]Point]bool{}
+	boundary := []segment{}
+	for _, s := range splitSegments(edges) {
+		if seen[[2]Point{s.a, s.b}] || seen[[2]Point{s.b, s.a}] {
+			continue
+		}
+		seen[[2]Point{s.a, s.b}] = true
+		dx, dy, _ := unit(s.b.X-s.a.X, s.b.Y-s.a.Y)
+		mid := Point{X: (s.a.X + s.b.X) / 2, Y: (s.a.Y + s.b.Y) / 2}
+		left := Point{X: mid.X - dy*regionProbe, Y: mid.Y + dx*regionProbe}
+		right := Point{X: mid.X + dy*regionProbe, Y: mid.Y - dx*regionProbe}
+		inLeft := keep(a.Contains(left), b.Contains(left))
+		inRight := keep(a.Contains(right), b.Contains(right))
+		if inLeft && !inRight {
+			boundary = append(boundary, s)
+		} else if inRight && !inLeft {
+			boundary = append(boundary, segment{a: s.b, b: s.a})
+		}
+	}
+	return linkSegments(boundary)
+}
+
+func snap(v float64) float64 {
+	return math.Round(v/regionGrid) * regionGrid
+}
+
+func snapPoint(p Point) Point {
+	return Point{X: snap(p.X), Y: snap(p.Y)}
+}
+
+func snapRegion(r Region) Region {
+	snapped := Region{}
+	for _, ring := range r {
+		sr := []Point{}
+		for _, p := range ring {
+			sr = append(sr, snapPoint(p))
+		}
+		snapped = append(snapped, sr)
+	}
+	return snapped
+}
+
+func regionSegments(r Region) []segment {
+	segments := []segment{}
+	for _, ring := range r {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			if ring[j] != ring[i] {
+				segments = append(segments, segment{a: ring[j], b: ring[i]})
+			}
+		}
+	}
+	return segments
+}
+
+// splitSegments splits segments wherever they cross or touch one another,
+// including where they overlap along the same line
+func splitSegments(segments []segment) []segment {
+	n := len(segments)
+	cuts := make([][]float64, n)
+	order := make([]int, n)
+	for index := range order {
+		order[index] = index
+	}
+	minX := func(s segment) float64 { return math.Min(s.a.X, s.b.X) }
+	maxX := func(s segment) float64 { return math.Max(s.a.X, s.b.X) }
+	sort.Slice(order, func(i, j int) bool { return minX(segments[order[i]]) < minX(segments[order[j]]) })
+	for oi, i := range order {
+		s := segments[i]
+		for _, j := range order[oi+1:] {
+			t := segments[j]
+			if minX(t) > maxX(s) {
+				break
+			}
+			if math.Min(t.a.Y, t.b.Y) > math.Max(s.a.Y, s.b.Y) || math.Min(s.a.Y, s.b.Y) > math.Max(t.a.Y, t.b.Y) {
+				continue
+			}
+			si, ti := intersect(s, t)
+			cuts[i] = append(cuts[i], si...)
+			cuts[j] = append(cuts[j], ti...)
+		}
+	}
+	pieces := []segment{}
+	for index, s := range segments {
+		params := append(cuts[index], 1)
+		sort.Float64s(params)
+		from := s.a
+		for _, t := range params {
+			to := snapPoint(Point{X: s.a.X + t*(s.b.X-s.a.X), Y: s.a.Y + t*(s.b.Y-s.a.Y)})
+			if t == 1 {
+				to = s.b
+			}
+			if to != from {
+				pieces = append(pieces, segment{a: from, b: to})
+				from = to
+			}
+		}
+	}
+	return pieces
+}
+
+// intersect finds where two segments meet, as parameters along each of
+// them, excluding their endpoints
+func intersect(s, t segment) (sParams, tParams []float64) {
+	const eps = 1e-9
+	rx, ry := s.b.X-s.a.X, s.b.Y-s.a.Y
+	qx, qy := t.b.X-t.a.X, t.b.Y-t.a.Y
+	px, py := t.a.X-s.a.X, t.a.Y-s.a.Y
+	denom := rx*qy - ry*qx
+	inside := func(v float64) bool { return v > eps && v < 1-eps }
+	if math.Abs(denom) > eps*math.Hypot(rx, ry)*math.Hypot(qx, qy) {
+		u := (px*qy - py*qx) / denom
+		v := (px*ry - py*rx) / denom
+		if u < -eps || u > 1+eps || v < -eps || v > 1+eps {
+			return nil, nil
+		}
+		if inside(u) {
+			sParams = append(sParams, u)
+		}
+		if inside(v) {
+			tParams = append(tParams, v)
+		}
+		return sParams, tParams
+	}
+	// parallel: only overlapping segments on the same line matter
+	sLen, tLen := math.Hypot(rx, ry), math.Hypot(qx, qy)
+	if sLen == 0 || tLen == 0 || math.Abs(px*ry-py*rx)/sLen > regionGrid {
+		return nil, nil
+	}
+	project := func(p Point, o Point, dx, dy, length float64) float64 {
+		return ((p.X-o.X)*dx + (p.Y-o.Y)*dy) / (length * length)
+	}
+	for _, p := range []Point{t.a, t.b} {
+		if u := project(p, s.a, rx, ry, sLen); inside(u) {
+			sParams = append(sParams, u)
+		}
+	}
+	for _, p := range []Point{s.a, s.b} {
+		if v := project(p, t.a, qx, qy, tLen); inside(v) {
+			tParams = append(tParams, v)
+		}
+	}
+	return sParams, tParams
+}
+
+// linkSegments joins directed boundary segments end to end into rings.
+// Where several segments leave the same point, the one turning furthest
+// right is taken, which keeps rings that touch at a point separate.
+func linkSegments(segments []segment) Region {
+	from := map[Point][]int{}
+	for index, s := range segments {
+		from[s.a] = append(from[s.a], index)
+	}
+	used := make([]bool, len(segments))
+	region := Region{}
+	for first := range segments {
+		if used[first] {
+			continue
+		}
+		used[first] = true
+		ring := []Point{segments[first].a}
+		current := segments[first]
+		for current.b != segments[first].a {
+			next := -1
+			best := math.Inf(1)
+			inX, inY, _ := unit(current.b.X-current.a.X, current.b.Y-current.a.Y)
+			for _, candidate := range from[current.b] {
+				if used[candidate] {
+					continue
+				}
+				c := segments[candidate]
+				outX, outY, _ := unit(c.b.X-c.a.X, c.b.Y-c.a.Y)
+				if turn := math.Atan2(inX*outY-inY*outX, inX*outX+inY*outY); turn < best {
+					next, best = candidate, turn
+				}
+			}
+			if next < 0 {
+				// an open chain can only come from numerical trouble;
+				// drop it rather than produce a bogus ring
+				ring = nil
+				break
+			}
+			used[next] = true
+			ring = append(ring, current.b)
+			current = segments[next]
+		}
+		if ring = simplifyRing(ring); len(ring) >= 3 {
+			region = append(region, ring)
+		}
+	}
+	return region
+}
+
+// simplifyRing drops points that lie on a straight line between their
+// neighbours
+func simplifyRing(ring []Point) []Point {
+	for changed := true; changed && len(ring) >= 3; {
+		changed = false
+		n := len(ring)
+		for index := 0; index < n; index++ {
+			prev, p, next := ring[(index+n-1)%n], ring[index], ring[(index+1)%n]
+			if math.Abs(cross(prev, p, next)) < regionGrid*regionGrid {
+				ring = append(ring[:index], ring[index+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+	if len(ring) < 3 || math.Abs(Area(ring)) < regionGrid*regionGrid {
+		return nil
+	}
+	return ring
+}
+
+// Keyhole converts a region into simple polygons, for formats such as
+// Eagle polygons that can't describe holes. Each hole is joined to the
+// outside boundary around it by a zero-width cut to its nearest visible
+// vertex. Polygons run counter-clockwise. It fails if a hole can't be
+// joined, rather than silently filling it in.
+func Keyhole(r Region) ([][]Point, error) {
+	outers, holes := [][]Point{}, [][]Point{}
+	for _, ring := range r {
+		if Area(ring) > 0 {
+			outers = append(outers, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	// each hole belongs to the smallest outside boundary around it
+	owned := make([][][]Point, len(outers))
+	for _, hole := range holes {
+		owner := -1
+		for index, outer := range outers {
+			if PointInPolygon(hole[0], outer) && (owner < 0 || Area(outer) < Area(outers[owner])) {
+				owner = index
+			}
+		}
+		if owner >= 0 {
+			owned[owner] = append(owned[owner], hole)
+		}
+	}
+	polygons := [][]Point{}
+	for index, outer := range outers {
+		polygon := append([]Point{}, outer...)
+		remaining := owned[index]
+		// working from right to left means each hole can reach the
+		// boundary without crossing holes not yet joined
+		sort.Slice(remaining, func(i, j int) bool { return rightmost(remaining[i]) > rightmost(remaining[j]) })
+		for len(remaining) > 0 {
+			hole := remaining[0]
+			remaining = remaining[1:]
+			joined, err := bridge(polygon, hole, remaining)
+			if err != nil {
+				return nil, err
+			}
+			polygon = joined
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+// rightmost returns the largest X coordinate in a ring
+func rightmost(ring []Point) float64 {
+	x := math.Inf(-1)
+	for _, p := range ring {
+		x = math.Max(x, p.X)
+	}
+	return x
+}
+
+// bridge splices a hole into a polygon via a cut from the hole's rightmost
+// point to the nearest polygon vertex that can be reached without crossing
+// any edges of the polygon or of the other holes
+func bridge(polygon, hole []Point, others [][]Point) ([]Point, error) {
+	hi := 0
+	for index, p := range hole {
+		if p.X > hole[hi].X {
+			hi = index
+		}
+	}
+	h := hole[hi]
+	candidates := make([]int, len(polygon))
+	for index := range candidates {
+		candidates[index] = index
+	}
+	distance := func(index int) float64 { return math.Hypot(polygon[index].X-h.X, polygon[index].Y-h.Y) }
+	sort.Slice(candidates, func(i, j int) bool { return distance(candidates[i]) < distance(candidates[j]) })
+	crosses := func(ring []Point, a, b Point) bool {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			if segmentsIntersect(a, b, ring[j], ring[i]) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, mi := range candidates {
+		m := polygon[mi]
+		mid := Point{X: (h.X + m.X) / 2, Y: (h.Y + m.Y) / 2}
+		if !PointInPolygon(mid, polygon) || PointInPolygon(mid, hole) || crosses(polygon, h, m) || crosses(hole, h, m) {
+			continue
+		}
+		blocked := false
+		for _, other := range others {
+			if crosses(other, h, m) {
+				blocked = true
+				break
+			}
+		}
+		if blocked {
+			continue
+		}
+		spliced := append([]Point{}, polygon[:mi+1]...)
+		spliced = append(spliced, hole[hi:]...)
+		spliced = append(spliced, hole[:hi+1]...)
+		return append(spliced, polygon[mi:]...), nil
+	}
+	return nil, fmt.Errorf("can't join hole at (%v,%v) to the surrounding polygon", h.X, h.Y)
+}
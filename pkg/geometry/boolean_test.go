@@ -0,0 +1,235 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+// rect returns a rectangular region
+func rect(x1, y1, x2, y2 float64) Region {
+	return Region{{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}}}
+}
+
+func TestBooleanArea(t *testing.T) {
+	tests := []struct {
+		name    string
+		region  Region
+		want    float64
+		inside  []Point
+		outside []Point
+	}{
+		{
+			name:    "square minus circle",
+			region:  Difference(rect(0, 0, 20, 20), CircleRegion(10, 10, 5, 0.001)),
+			want:    400 - 25*math.Pi,
+			inside:  []Point{{X: 1, Y: 1}, {X: 10, Y: 16}},
+			outside: []Point{{X: 10, Y: 10}, {X: 13, Y: 13}},
+		},
+		{
+			name:    "overlapping union",
+			region:  Union(rect(0, 0, 10, 10), rect(5, 5, 15, 15)),
+			want:    175,
+			inside:  []Point{{X: 1, Y: 1}, {X: 7, Y: 7}, {X: 14, Y: 14}},
+			outside: []Point{{X: 14, Y: 1}, {X: 1, Y: 14}},
+		},
+		{
+			name:   "union of three, one inside another",
+			region: Union(Union(rect(0, 0, 10, 10), rect(5, 5, 15, 15)), rect(2, 2, 4, 4)),
+			want:   175,
+		},
+		{
+			name:    "disjoint union",
+			region:  Union(rect(0, 0, 10, 10), rect(20, 0, 30, 10)),
+			want:    200,
+			outside: []Point{{X: 15, Y: 5}},
+		},
+		{
+			name:    "overlapping intersection",
+			region:  Intersection(rect(0, 0, 10, 10), rect(5, 5, 15, 15)),
+			want:    25,
+			inside:  []Point{{X: 7, Y: 7}},
+			outside: []Point{{X: 1, Y: 1}, {X: 14, Y: 14}},
+		},
+		{
+			name:    "overlapping difference",
+			region:  Difference(rect(0, 0, 10, 10), rect(5, 5, 15, 15)),
+			want:    75,
+			inside:  []Point{{X: 1, Y: 1}, {X: 9, Y: 1}},
+			outside: []Point{{X: 7, Y: 7}},
+		},
+		{
+			// the hole shares part of the right-hand edge, so it's a notch
+			name:    "hole touching the outer edge",
+			region:  Difference(rect(0, 0, 20, 20), rect(15, 5, 20, 10)),
+			want:    375,
+			inside:  []Point{{X: 17, Y: 2}, {X: 17, Y: 12}},
+			outside: []Point{{X: 17, Y: 7}},
+		},
+		{
+			name:    "hole overlapping the outer edge",
+			region:  Difference(rect(0, 0, 20, 20), rect(15, 5, 25, 10)),
+			want:    375,
+			outside: []Point{{X: 19.9, Y: 7}},
+		},
+		{
+			name:    "circle meeting the outer edge at a point",
+			region:  Difference(rect(0, 0, 20, 20), CircleRegion(15, 10, 5, 0.001)),
+			want:    400 - 25*math.Pi,
+			inside:  []Point{{X: 19.9, Y: 1}, {X: 19.9, Y: 19}},
+			outside: []Point{{X: 15, Y: 10}, {X: 19.5, Y: 10}},
+		},
+		{
+			name:   "everything removed",
+			region: Difference(rect(0, 0, 10, 10), rect(-1, -1, 11, 11)),
+			want:   0,
+		},
+		{
+			name:    "grown square",
+			region:  Offset(rect(0, 0, 10, 10), 1, 0.001),
+			want:    100 + 40 + math.Pi,
+			inside:  []Point{{X: -0.9, Y: 5}, {X: 10.6, Y: 10.6}},
+			outside: []Point{{X: 10.8, Y: 10.8}},
+		},
+		{
+			name:   "shrunk square",
+			region: Offset(rect(0, 0, 10, 10), -1, 0.001),
+			want:   64,
+		},
+	}
+	for _, test := range tests {
+		// curves are approximated, so allow a little error
+		if got := test.region.Area(); math.Abs(got-test.want) > 0.05 {
+			t.Errorf("%s: got area %v, want %v", test.name, got, test.want)
+		}
+		for _, p := range test.inside {
+			if !test.region.Contains(p) {
+				t.Errorf("%s: (%v,%v) should be inside", test.name, p.X, p.Y)
+			}
+		}
+		for _, p := range test.outside {
+			if test.region.Contains(p) {
+				t.Errorf("%s: (%v,%v) should be outside", test.name, p.X, p.Y)
+			}
+		}
+	}
+}
+
+// selfIntersects reports whether any two edges of a polygon cross. The
+// zero-width cuts made by Keyhole run along the same line in both
+// directions, which doesn't count as crossing.
+func selfIntersects(polygon []Point) bool {
+	n := len(polygon)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if segmentsIntersect(polygon[i], polygon[(i+1)%n], polygon[j], polygon[(j+1)%n]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestKeyhole(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   Region
+		polygons int
+		// inside the holes, so outside every polygon
+		holes []Point
+	}{
+		{
+			name:     "no holes",
+			region:   rect(0, 0, 10, 10),
+			polygons: 1,
+		},
+		{
+			name:     "one hole",
+			region:   Difference(rect(0, 0, 20, 20), CircleRegion(10, 10, 5, 0.01)),
+			polygons: 1,
+			holes:    []Point{{X: 10, Y: 10}},
+		},
+		{
+			// the left hole is hidden from the right edge by the right one,
+			// so one cut has to go around it
+			name: "holes in a row",
+			region: Difference(Difference(rect(0, 0, 40, 20),
+				CircleRegion(10, 10, 3, 0.01)), CircleRegion(25, 10, 3, 0.01)),
+			polygons: 1,
+			holes:    []Point{{X: 10, Y: 10}, {X: 25, Y: 10}},
+		},
+		{
+			name:     "hole touching the outer edge",
+			region:   Difference(rect(0, 0, 20, 20), rect(15, 5, 20, 10)),
+			polygons: 1,
+			holes:    []Point{{X: 17, Y: 7}},
+		},
+		{
+			name:     "separate pieces",
+			region:   Difference(Union(rect(0, 0, 10, 10), rect(20, 0, 30, 10)), rect(22, 2, 28, 8)),
+			polygons: 2,
+			holes:    []Point{{X: 25, Y: 5}},
+		},
+	}
+	for _, test := range tests {
+		polygons, err := Keyhole(test.region)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(polygons) != test.polygons {
+			t.Errorf("%s: got %d polygons, want %d", test.name, len(polygons), test.polygons)
+			continue
+		}
+		area := 0.0
+		for index, polygon := range polygons {
+			if Area(polygon) <= 0 {
+				t.Errorf("%s: polygon %d doesn't run counter-clockwise", test.name, index)
+			}
+			if selfIntersects(polygon) {
+				t.Errorf("%s: polygon %d crosses itself", test.name, index)
+			}
+			area += Area(polygon)
+			for _, p := range test.holes {
+				if PointInPolygon(p, polygon) {
+					t.Errorf("%s: polygon %d covers (%v,%v), which should be a hole", test.name, index, p.X, p.Y)
+				}
+			}
+		}
+		// the cuts have no width, so they don't change the area
+		if want := test.region.Area(); math.Abs(area-want) > 1e-6 {
+			t.Errorf("%s: got area %v, want %v", test.name, area, want)
+		}
+	}
+}
+
+func TestKeyholeUnjoinableHole(t *testing.T) {
+	// a hole inside another hole, with no island between them, can't be
+	// reached from the outside boundary without crossing the outer hole
+	clockwise := func(x1, y1, x2, y2 float64) []Point {
+		return []Point{{X: x1, Y: y1}, {X: x1, Y: y2}, {X: x2, Y: y2}, {X: x2, Y: y1}}
+	}
+	region := Region{rect(0, 0, 40, 40)[0], clockwise(5, 5, 35, 35), clockwise(15, 15, 25, 25)}
+	if polygons, err := Keyhole(region); err == nil {
+		t.Errorf("expected an error, got %d polygons", len(polygons))
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+// Extents is an axis-aligned bounding box. The zero value is a box
+// containing only the origin; use NewExtents for one containing nothing.
+type Extents struct {
+	XMin, YMin, XMax, YMax float64
+}
+
+// NewExtents returns an empty bounding box, to which points may be added
+func NewExtents() Extents {
+	return Extents{
+		XMin: math.Inf(1), YMin: math.Inf(1),
+		XMax: math.Inf(-1), YMax: math.Inf(-1),
+	}
+}
+
+// Empty reports whether nothing has been added to the bounding box
+func (e Extents) Empty() bool {
+	return e.XMin > e.XMax || e.YMin > e.YMax
+}
+
+// Width returns the width of the bounding box
+func (e Extents) Width() float64 {
+	if e.Empty() {
+		return 0
+	}
+	return e.XMax - e.XMin
+}
+
+// Height returns the height of the bounding box
+func (e Extents) Height() float64 {
+	if e.Empty() {
+		return 0
+	}
+	return e.YMax - e.YMin
+}
+
+// AddPoint grows the bounding box to include a point
+func (e *Extents) AddPoint(x, y float64) {
+	e.XMin, e.XMax = math.Min(e.XMin, x), math.Max(e.XMax, x)
+	e.YMin, e.YMax = math.Min(e.YMin, y), math.Max(e.YMax, y)
+}
+
+// Add grows the bounding box to include another one
+func (e *Extents) Add(other Extents) {
+	if other.Empty() {
+		return
+	}
+	e.AddPoint(other.XMin, other.YMin)
+	e.AddPoint(other.XMax, other.YMax)
+}
+
+// Grow enlarges a non-empty bounding box by d in every direction, eg. to
+// account for the width of a line
+func (e *Extents) Grow(d float64) {
+	if e.Empty() {
+		return
+	}
+	e.XMin, e.YMin = e.XMin-d, e.YMin-d
+	e.XMax, e.YMax = e.XMax+d, e.YMax+d
+}
+
+// Edge is a straight or curved line from (X1,Y1) to (X2,Y2). As for Eagle
+// wires, Curve is the angle in degrees swept by the edge, with positive
+// values sweeping counter-clockwise.
+type Edge struct {
+	X1, Y1, X2, Y2, Curve float64
+}
+
+// Reversed returns the same edge, running the other way
+func (e Edge) Reversed() Edge {
+	return Edge{X1: e.X2, Y1: e.Y2, X2: e.X1, Y2: e.Y1, Curve: -e.Curve}
+}
+
+// Extents returns the true bounds of an edge, including any parts of an
+// arc that bulge past its endpoints
+func (e Edge) Extents() Extents {
+	ext := NewExtents()
+	ext.AddPoint(e.X1, e.Y1)
+	ext.AddPoint(e.X2, e.Y2)
+	if e.Curve == 0 {
+		return ext
+	}
+	cx, cy, r := ArcCentre(e.X1, e.Y1, e.X2, e.Y2, e.Curve)
+	start := math.Atan2(e.Y1-cy, e.X1-cx)
+	sweep := e.Curve * math.Pi / 180.0
+	// add each of the four compass points the arc passes through
+	for quadrant := 0; quadrant < 4; quadrant++ {
+		angle := float64(quadrant) * math.Pi / 2
+		var d float64
+		if sweep > 0 {
+			d = math.Mod(angle-start+4*math.Pi, 2*math.Pi)
+		} else {
+			d = math.Mod(start-angle+4*math.Pi, 2*math.Pi)
+		}
+		if d <= math.Abs(sweep) {
+			ext.AddPoint(cx+r*math.Cos(angle), cy+r*math.Sin(angle))
+		}
+	}
+	return ext
+}
+
+// EdgesExtents returns the true bounds of a set of edges
+func EdgesExtents(edges []Edge) Extents {
+	ext := NewExtents()
+	for _, e := range edges {
+		ext.Add(e.Extents())
+	}
+	return ext
+}
+
+// LoopEdges returns the edges of a closed polygon that may have curved edges
+func LoopEdges(vertices []Vertex) []Edge {
+	edges := []Edge{}
+	for index, v := range vertices {
+		next := vertices[(index+1)%len(vertices)]
+		edges = append(edges, Edge{X1: v.X, Y1: v.Y, X2: next.X, Y2: next.Y, Curve: v.Curve})
+	}
+	return edges
+}
+
+// CircleEdges returns a circle as a pair of semicircular edges, running
+// counter-clockwise
+func CircleEdges(x, y, r float64) []Edge {
+	return []Edge{
+		{X1: x + r, Y1: y, X2: x - r, Y2: y, Curve: 180},
+		{X1: x - r, Y1: y, X2: x + r, Y2: y, Curve: 180},
+	}
+}
+
+// WireEdge returns the centreline of an Eagle wire
+func WireEdge(w eagle.Wire) Edge {
+	return Edge{X1: w.X1, Y1: w.Y1, X2: w.X2, Y2: w.Y2, Curve: w.Curve}
+}
+
+// PolygonEdges returns the centreline of the outline of an Eagle polygon
+func PolygonEdges(p eagle.Polygon) []Edge {
+	vertices := []Vertex{}
+	for _, v := range p.Vertices {
+		vertices = append(vertices, Vertex{X: v.X, Y: v.Y, Curve: v.Curve})
+	}
+	return LoopEdges(vertices)
+}
+
+// RectangleEdges returns the outline of an Eagle rectangle, which may be
+// rotated about its centre
+func RectangleEdges(r eagle.Rectangle) ([]Edge, error) {
+	rot, err := eagle.ParseRotation(r.Rotate)
+	if err != nil {
+		return nil, err
+	}
+	cx, cy := (r.X1+r.X2)/2, (r.Y1+r.Y2)/2
+	t := RotateAbout(rot.Angle, cx, cy)
+	corners := []Vertex{}
+	for _, p := range []Point{{X: r.X1, Y: r.Y1}, {X: r.X2, Y: r.Y1}, {X: r.X2, Y: r.Y2}, {X: r.X1, Y: r.Y2}} {
+		x, y := t.Apply(p.X, p.Y)
+		corners = append(corners, Vertex{X: x, Y: y})
+	}
+	return LoopEdges(corners), nil
+}
+
+// WireExtents returns the true bounds of an Eagle wire, including its width
+func WireExtents(w eagle.Wire) Extents {
+	ext := WireEdge(w).Extents()
+	ext.Grow(w.Width / 2)
+	return ext
+}
+
+// CircleExtents returns the true bounds of an Eagle circle, including its
+// width
+func CircleExtents(c eagle.Circle) Extents {
+	ext := EdgesExtents(CircleEdges(c.X, c.Y, c.Radius))
+	ext.Grow(c.Width / 2)
+	return ext
+}
+
+// PolygonExtents returns the true bounds of an Eagle polygon, including the
+// width of its outline
+func PolygonExtents(p eagle.Polygon) Extents {
+	ext := EdgesExtents(PolygonEdges(p))
+	ext.Grow(p.Width / 2)
+	return ext
+}
+
+// RectangleExtents returns the true bounds of an Eagle rectangle
+func RectangleExtents(r eagle.Rectangle) (Extents, error) {
+	edges, err := RectangleEdges(r)
+	if err != nil {
+		return NewExtents(), err
+	}
+	return EdgesExtents(edges), nil
+}
+
+// HoleExtents returns the true bounds of an Eagle hole
+func HoleExtents(h eagle.Hole) Extents {
+	return EdgesExtents(CircleEdges(h.X, h.Y, h.Drill/2))
+}
+
+// PackageExtents returns the true bounds of the wires, circles, polygons,
+// rectangles, holes, pads and SMDs of an Eagle package, relative to the
+// package origin. Texts are ignored, as their size depends on the font.
+// Pads are assumed to be no bigger than twice their drill.
+func PackageExtents(pkg eagle.Package) (Extents, error) {
+	ext := NewExtents()
+	for _, w := range pkg.Wires {
+		ext.Add(WireExtents(w))
+	}
+	for _, c := range pkg.Circles {
+		ext.Add(CircleExtents(c))
+	}
+	for _, p := range pkg.Polygons {
+		ext.Add(PolygonExtents(p))
+	}
+	for _, r := range pkg.Rectangles {
+		re, err := RectangleExtents(r)
+		if err != nil {
+			return ext, err
+		}
+		ext.Add(re)
+	}
+	for _, h := range pkg.Holes {
+		ext.Add(HoleExtents(h))
+	}
+	for _, p := range pkg.Pads {
+		radius := p.Drill
+		if p.Diameter > 0 {
+			radius = p.Diameter / 2
+		}
+		ext.Add(EdgesExtents(CircleEdges(p.X, p.Y, radius)))
+	}
+	for _, s := range pkg.Smds {
+		rect := eagle.Rectangle{X1: s.X - s.DX/2, Y1: s.Y - s.DY/2, X2: s.X + s.DX/2, Y2: s.Y + s.DY/2, Rotate: s.Rotate}
+		re, err := RectangleExtents(rect)
+		if err != nil {
+			return ext, err
+		}
+		ext.Add(re)
+	}
+	return ext, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+const testTolerance = 1e-9
+
+func sameExtents(a, b Extents) bool {
+	return math.Abs(a.XMin-b.XMin) < testTolerance && math.Abs(a.YMin-b.YMin) < testTolerance &&
+		math.Abs(a.XMax-b.XMax) < testTolerance && math.Abs(a.YMax-b.YMax) < testTolerance
+}
+
+func TestEmptyExtents(t *testing.T) {
+	ext := NewExtents()
+	if !ext.Empty() {
+		t.Errorf("new extents %+v should be empty", ext)
+	}
+	if ext.Width() != 0 || ext.Height() != 0 {
+		t.Errorf("empty extents should have no size, got %vx%v", ext.Width(), ext.Height())
+	}
+	ext.Grow(1)
+	ext.Add(NewExtents())
+	if !ext.Empty() {
+		t.Errorf("growing or adding nothing should leave extents empty, got %+v", ext)
+	}
+	// everything in positive coordinates must not be pulled back to the
+	// origin, as the zero value would be
+	ext.AddPoint(20, 30)
+	ext.AddPoint(70, 158.5)
+	want := Extents{XMin: 20, YMin: 30, XMax: 70, YMax: 158.5}
+	if !sameExtents(ext, want) {
+		t.Errorf("got %+v, want %+v", ext, want)
+	}
+	if ext.Width() != 50 || ext.Height() != 128.5 {
+		t.Errorf("got %vx%v, want 50x128.5", ext.Width(), ext.Height())
+	}
+}
+
+func TestEdgeExtents(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want Extents
+	}{
+		{
+			name: "straight",
+			edge: Edge{X1: 1, Y1: 2, X2: 5, Y2: -3},
+			want: Extents{XMin: 1, YMin: -3, XMax: 5, YMax: 2},
+		},
+		{
+			name: "counter-clockwise semicircle bulges below",
+			edge: Edge{X1: 0, Y1: 0, X2: 10, Y2: 0, Curve: 180},
+			want: Extents{XMin: 0, YMin: -5, XMax: 10, YMax: 0},
+		},
+		{
+			name: "clockwise semicircle bulges above",
+			edge: Edge{X1: 0, Y1: 0, X2: 10, Y2: 0, Curve: -180},
+			want: Extents{XMin: 0, YMin: 0, XMax: 10, YMax: 5},
+		},
+		{
+			name: "quarter circle stays within its endpoints",
+			edge: Edge{X1: 10, Y1: 0, X2: 0, Y2: 10, Curve: 90},
+			want: Extents{XMin: 0, YMin: 0, XMax: 10, YMax: 10},
+		},
+		{
+			name: "three-quarter circle passes three compass points",
+			edge: Edge{X1: 10, Y1: 0, X2: 0, Y2: -10, Curve: 270},
+			want: Extents{XMin: -10, YMin: -10, XMax: 10, YMax: 10},
+		},
+		{
+			name: "shallow arc in positive coordinates",
+			edge: Edge{X1: 50, Y1: 40, X2: 10, Y2: 40, Curve: 180},
+			want: Extents{XMin: 10, YMin: 40, XMax: 50, YMax: 60},
+		},
+	}
+	for _, test := range tests {
+		if got := test.edge.Extents(); !sameExtents(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+		if got := test.edge.Reversed().Extents(); !sameExtents(got, test.want) {
+			t.Errorf("%s, reversed: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPrimitiveExtents(t *testing.T) {
+	rectangle, err := RectangleExtents(eagle.Rectangle{X1: 0, Y1: 0, X2: 10, Y2: 2, Rotate: "R90"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want Extents
+	}{
+		{
+			name: "wire",
+			got:  WireExtents(eagle.Wire{X1: 0, Y1: 3, X2: 2, Y2: 3, Width: 0.4}),
+			want: Extents{XMin: -0.2, YMin: 2.8, XMax: 2.2, YMax: 3.2},
+		},
+		{
+			name: "circle",
+			got:  CircleExtents(eagle.Circle{X: 5, Y: 5, Radius: 2, Width: 1}),
+			want: Extents{XMin: 2.5, YMin: 2.5, XMax: 7.5, YMax: 7.5},
+		},
+		{
+			name: "polygon with a curved edge",
+			got: PolygonExtents(eagle.Polygon{Vertices: []eagle.Vertex{
+				{X: 0, Y: 0}, {X: 10, Y: 0, Curve: 180}, {X: 10, Y: 10}, {X: 0, Y: 10},
+			}}),
+			want: Extents{XMin: 0, YMin: 0, XMax: 15, YMax: 10},
+		},
+		{
+			name: "rotated rectangle",
+			got:  rectangle,
+			want: Extents{XMin: 4, YMin: -4, XMax: 6, YMax: 6},
+		},
+		{
+			name: "hole",
+			got:  HoleExtents(eagle.Hole{X: 1, Y: 1, Drill: 3}),
+			want: Extents{XMin: -0.5, YMin: -0.5, XMax: 2.5, YMax: 2.5},
+		},
+	}
+	for _, test := range tests {
+		if !sameExtents(test.got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, test.got, test.want)
+		}
+	}
+	if _, err := RectangleExtents(eagle.Rectangle{Rotate: "Rbogus"}); err == nil {
+		t.Errorf("expected an error for an invalid rectangle rotation")
+	}
+}
+
+func TestPackageExtents(t *testing.T) {
+	pkg := eagle.Package{
+		Wires: []eagle.Wire{{X1: 0, Y1: 3, X2: 2, Y2: 3, Width: 0.4}},
+		Pads:  []eagle.Pad{{X: 0, Y: 0, Drill: 1}},
+		Smds:  []eagle.Smd{{X: 5, Y: 0, DX: 2, DY: 4}},
+		Texts: []eagle.Text{{X: 100, Y: 100, Size: 2, Text: ">NAME"}},
+	}
+	got, err := PackageExtents(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Extents{XMin: -1, YMin: -2, XMax: 6, YMax: 3.2}
+	if !sameExtents(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	pkg.Smds[0].Rotate = "Rbogus"
+	if _, err := PackageExtents(pkg); err == nil {
+		t.Errorf("expected an error for an invalid SMD rotation")
+	}
+}
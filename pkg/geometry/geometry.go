@@ -22,7 +22,12 @@ package geometry
 
 import "math"
 
-// Point holds a Cartesian point and also an angle in degrees.
+// Point holds a Cartesian point
+type Point struct {
+	X, Y float64
+}
+
+// RadialPoint holds a Cartesian point and also an angle in degrees.
 type RadialPoint struct {
 	Angle float64
 	X, Y  float64
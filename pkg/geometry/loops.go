@@ -0,0 +1,125 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"fmt"
+	"math"
+)
+
+// JoinLoops links edges end to end into closed loops, as Eagle-style polygon
+// vertices. Edge ends within tolerance of each other are considered joined,
+// and edges are reversed where necessary. An error is returned if any chain
+// of edges does not close.
+func JoinLoops(edges []Edge, tolerance float64) ([][]Vertex, error) {
+	loops := [][]Vertex{}
+	used := make([]bool, len(edges))
+	near := func(x1, y1, x2, y2 float64) bool {
+		return math.Hypot(x2-x1, y2-y1) < tolerance
+	}
+	for first := range edges {
+		if used[first] {
+			continue
+		}
+		used[first] = true
+		chain := []Edge{edges[first]}
+		for {
+			last := chain[len(chain)-1]
+			if near(last.X2, last.Y2, chain[0].X1, chain[0].Y1) {
+				break
+			}
+			found := false
+			for index, e := range edges {
+				if used[index] {
+					continue
+				}
+				if near(last.X2, last.Y2, e.X1, e.Y1) {
+					chain, found = append(chain, e), true
+				} else if near(last.X2, last.Y2, e.X2, e.Y2) {
+					chain, found = append(chain, e.Reversed()), true
+				}
+				if found {
+					used[index] = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("outline is not closed near (%v,%v)", last.X2, last.Y2)
+			}
+		}
+		loop := []Vertex{}
+		for _, e := range chain {
+			loop = append(loop, Vertex{X: e.X1, Y: e.Y1, Curve: e.Curve})
+		}
+		loops = append(loops, loop)
+	}
+	return loops, nil
+}
+
+// LoopArea returns the signed area of a closed loop that may have curved
+// edges: positive if it runs counter-clockwise, negative if clockwise
+func LoopArea(loop []Vertex) float64 {
+	return Area(Flatten(loop, 0.01))
+}
+
+// CounterClockwise returns a closed loop running counter-clockwise,
+// reversing it if necessary
+func CounterClockwise(loop []Vertex) []Vertex {
+	if LoopArea(loop) < 0 {
+		return Reverse(loop)
+	}
+	return loop
+}
+
+// SplitLoops picks the loop enclosing the greatest area as the outside of a
+// shape, and returns it along with those loops lying inside or crossing it,
+// which are cutouts. Loops lying wholly outside the largest one are ignored.
+// All returned loops run counter-clockwise.
+func SplitLoops(loops [][]Vertex) (outer []Vertex, cutouts [][]Vertex) {
+	largest := -1
+	largestArea := 0.0
+	for index, loop := range loops {
+		if area := math.Abs(LoopArea(loop)); area > largestArea {
+			largest, largestArea = index, area
+		}
+	}
+	if largest < 0 {
+		return nil, nil
+	}
+	outer = CounterClockwise(loops[largest])
+	flat := Flatten(outer, 0.01)
+	for index, loop := range loops {
+		if index != largest && overlaps(Flatten(loop, 0.01), flat) {
+			cutouts = append(cutouts, CounterClockwise(loop))
+		}
+	}
+	return outer, cutouts
+}
+
+// overlaps reports whether any part of polygon a lies inside polygon b
+func overlaps(a, b []Point) bool {
+	for _, p := range a {
+		if PointInPolygon(p, b) {
+			return true
+		}
+	}
+	return PolygonsDistance(a, b) == 0
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJoinLoops(t *testing.T) {
+	edges := []Edge{
+		{X1: 0, Y1: 0, X2: 10, Y2: 0},
+		// a circle, which forms a loop of its own
+		{X1: 60, Y1: 50, X2: 40, Y2: 50, Curve: 180},
+		// drawn the other way round from its neighbours
+		{X1: 10, Y1: 10, X2: 10, Y2: 0, Curve: -30},
+		{X1: 40, Y1: 50, X2: 60, Y2: 50, Curve: 180},
+		// ends not quite meeting, but within tolerance
+		{X1: 0, Y1: 10.0001, X2: 0, Y2: 0.0001},
+		{X1: 10, Y1: 10, X2: 0, Y2: 10},
+	}
+	loops, err := JoinLoops(edges, 1e-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]Vertex{
+		{{X: 0, Y: 0}, {X: 10, Y: 0, Curve: 30}, {X: 10, Y: 10}, {X: 0, Y: 10.0001}},
+		{{X: 60, Y: 50, Curve: 180}, {X: 40, Y: 50, Curve: 180}},
+	}
+	if !reflect.DeepEqual(loops, want) {
+		t.Errorf("got loops %v, want %v", loops, want)
+	}
+	if _, err := JoinLoops(edges[:3], 1e-3); err == nil {
+		t.Errorf("expected an error for an outline that isn't closed")
+	}
+}
+
+// square returns a square loop, running counter-clockwise
+func square(x1, y1, x2, y2 float64) []Vertex {
+	return []Vertex{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}}
+}
+
+func TestLoopArea(t *testing.T) {
+	tests := []struct {
+		name string
+		loop []Vertex
+		want float64
+	}{
+		{name: "counter-clockwise square", loop: square(0, 0, 10, 10), want: 100},
+		{name: "clockwise square", loop: Reverse(square(0, 0, 10, 10)), want: -100},
+		{name: "circle", loop: []Vertex{{X: 10, Y: 0, Curve: 180}, {X: -10, Y: 0, Curve: 180}}, want: math.Pi * 100},
+	}
+	for _, test := range tests {
+		// curves are flattened, so allow a little error
+		if got := LoopArea(test.loop); math.Abs(got-test.want) > 0.5 {
+			t.Errorf("%s: got area %v, want %v", test.name, got, test.want)
+		}
+		if got := LoopArea(CounterClockwise(test.loop)); got <= 0 {
+			t.Errorf("%s: counter-clockwise loop has area %v", test.name, got)
+		}
+	}
+}
+
+func TestSplitLoops(t *testing.T) {
+	outer := Reverse(square(10, 10, 110, 90))
+	hole := []Vertex{{X: 50, Y: 50, Curve: -180}, {X: 30, Y: 50, Curve: -180}}
+	notch := square(100, 40, 120, 60)
+	outside := square(200, 200, 210, 210)
+	got, cutouts := SplitLoops([][]Vertex{hole, outside, outer, notch})
+	if !reflect.DeepEqual(got, CounterClockwise(outer)) {
+		t.Errorf("got outer loop %v, want %v", got, CounterClockwise(outer))
+	}
+	want := [][]Vertex{CounterClockwise(hole), notch}
+	if !reflect.DeepEqual(cutouts, want) {
+		t.Errorf("got cutouts %v, want %v", cutouts, want)
+	}
+	if outer, cutouts := SplitLoops(nil); outer != nil || cutouts != nil {
+		t.Errorf("expected nothing from no loops, got %v and %v", outer, cutouts)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import "math"
+
+// Vertex is a polygon vertex, as for Eagle polygons: Curve is the angle in
+// degrees swept by the edge from this vertex to the next one, with positive
+// values sweeping counter-clockwise and zero meaning a straight edge.
+type Vertex struct {
+	X, Y, Curve float64
+}
+
+// Flatten approximates a closed polygon that may have curved edges with a
+// series of points. The polygon is implicitly closed and the first point is
+// not repeated at the end.
+func Flatten(vertices []Vertex, tolerance float64) []Point {
+	points := []Point{}
+	for index, v := range vertices {
+		next := vertices[(index+1)%len(vertices)]
+		arc := ArcPoints(v.X, v.Y, next.X, next.Y, v.Curve, tolerance)
+		points = append(points, arc[:len(arc)-1]...)
+	}
+	return points
+}
+
+// RoundCorners replaces each corner of a closed polygon with an arc of the
+// given radius, tangent to both edges. The radius is reduced where a corner's
+// edges are too short to fit it.
+func RoundCorners(points []Point, radius float64) []Vertex {
+	vertices := []Vertex{}
+	n := len(points)
+	for index, p := range points {
+		if radius <= 0 || n < 3 {
+			vertices = append(vertices, Vertex{X: p.X, Y: p.Y})
+			continue
+		}
+		prev := points[(index+n-1)%n]
+		next := points[(index+1)%n]
+		inX, inY, inLen := unit(p.X-prev.X, p.Y-prev.Y)
+		outX, outY, outLen := unit(next.X-p.X, next.Y-p.Y)
+		// signed turning angle, positive for a left (counter-clockwise) turn
+		turn := math.Atan2(inX*outY-inY*outX, inX*outX+inY*outY)
+		if inLen == 0 || outLen == 0 || math.Abs(turn) < 1e-9 {
+			vertices = append(vertices, Vertex{X: p.X, Y: p.Y})
+			continue
+		}
+		tangent := radius * math.Tan(math.Abs(turn)/2)
+		// never use more than half of either edge, so that neighbouring
+		// corners don't overlap
+		if limit := math.Min(inLen, outLen) / 2; tangent > limit {
+			tangent = limit
+		}
+		start := Vertex{X: p.X - inX*tangent, Y: p.Y - inY*tangent, Curve: turn * 180 / math.Pi}
+		// where the previous corner's arc ends exactly where this one
+		// starts, eg. for slots, drop the zero-length edge between them
+		if last := len(vertices) - 1; last >= 0 && samePoint(vertices[last], start) {
+			vertices = vertices[:last]
+		}
+		vertices = append(vertices, start, Vertex{X: p.X + outX*tangent, Y: p.Y + outY*tangent})
+	}
+	if last := len(vertices) - 1; last > 0 && samePoint(vertices[last], vertices[0]) {
+		vertices = vertices[:last]
+	}
+	return vertices
+}
+
+func samePoint(a, b Vertex) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+// PointInPolygon reports whether a point lies inside a closed polygon, using
+// the even-odd rule
+func PointInPolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// SegmentDistance returns the shortest distance from a point to the line
+// segment running from a to b
+func SegmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / lengthSquared
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
+
+// PolygonDistance returns the shortest distance from a point to the edges
+// of a closed polygon. The point may be inside or outside the polygon.
+func PolygonDistance(p Point, polygon []Point) float64 {
+	distance := math.Inf(1)
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		distance = math.Min(distance, SegmentDistance(p, polygon[j], polygon[i]))
+	}
+	return distance
+}
+
+func unit(dx, dy float64) (float64, float64, float64) {
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return 0, 0, 0
+	}
+	return dx / length, dy / length, length
+}
+
+// RoundedRectangle returns the outline of an axis-aligned rectangle centred
+// on (cx,cy), with corners rounded to radius r. The outline runs
+// counter-clockwise. A radius of half the smaller dimension gives a slot.
+func RoundedRectangle(cx, cy, width, height, r float64) []Vertex {
+	x1, y1 := cx-width/2, cy-height/2
+	x2, y2 := cx+width/2, cy+height/2
+	return RoundCorners([]Point{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}}, r)
+}
+
+// PolygonsDistance returns the shortest distance between the edges of two
+// closed polygons, or zero if they overlap
+func PolygonsDistance(a, b []Point) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+	if PointInPolygon(a[0], b) || PointInPolygon(b[0], a) {
+		return 0
+	}
+	distance := math.Inf(1)
+	for i, j := 0, len(a)-1; i < len(a); j, i = i, i+1 {
+		for k, l := 0, len(b)-1; k < len(b); l, k = k, k+1 {
+			if segmentsIntersect(a[j], a[i], b[l], b[k]) {
+				return 0
+			}
+		}
+		distance = math.Min(distance, PolygonDistance(a[i], b))
+	}
+	for _, p := range b {
+		distance = math.Min(distance, PolygonDistance(p, a))
+	}
+	return distance
+}
+
+// segmentsIntersect reports whether line segments p1-p2 and p3-p4 cross
+func segmentsIntersect(p1, p2, p3, p4 Point) bool {
+	d1 := cross(p3, p4, p1)
+	d2 := cross(p3, p4, p2)
+	d3 := cross(p1, p2, p3)
+	d4 := cross(p1, p2, p4)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// cross returns the z component of the cross product of (b-a) and (c-a)
+func cross(a, b, c Point) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// Area returns the signed area of a closed polygon: positive if it runs
+// counter-clockwise, negative if clockwise
+func Area(polygon []Point) float64 {
+	area := 0.0
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		area += polygon[j].X*polygon[i].Y - polygon[i].X*polygon[j].Y
+	}
+	return area / 2
+}
+
+// Reverse returns a closed polygon that may have curved edges, running the
+// other way around. Curves are moved to the vertex that now starts each edge
+// and negated, so the shape is unchanged.
+func Reverse(vertices []Vertex) []Vertex {
+	n := len(vertices)
+	reversed := make([]Vertex, n)
+	for k := range vertices {
+		v := vertices[n-1-k]
+		reversed[k] = Vertex{X: v.X, Y: v.Y, Curve: -vertices[(2*n-2-k)%n].Curve}
+	}
+	return reversed
+}
+
+// Bounds returns the smallest axis-aligned rectangle containing a set of
+// points
+func Bounds(points []Point) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	return minX, minY, maxX, maxY
+}
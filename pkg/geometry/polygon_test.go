@@ -0,0 +1,240 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestArea(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon []Point
+		want    float64
+	}{
+		{name: "counter-clockwise", polygon: []Point{{0, 0}, {4, 0}, {4, 3}, {0, 3}}, want: 12},
+		{name: "clockwise", polygon: []Point{{0, 0}, {0, 3}, {4, 3}, {4, 0}}, want: -12},
+		{name: "triangle", polygon: []Point{{1, 1}, {5, 1}, {1, 4}}, want: 6},
+		{name: "degenerate", polygon: []Point{{0, 0}, {4, 0}}, want: 0},
+		{name: "empty", want: 0},
+	}
+	for _, test := range tests {
+		if got := Area(test.polygon); got != test.want {
+			t.Errorf("%s: got area %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	// a D shape: straight left edge, and a semicircle from bottom to top
+	d := []Vertex{{X: 0, Y: -5, Curve: 180}, {X: 0, Y: 5}}
+	want := []Vertex{{X: 0, Y: 5, Curve: -180}, {X: 0, Y: -5}}
+	if got := Reverse(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// each curve stays with its edge, now running the other way
+	loop := []Vertex{{X: 0, Y: 0, Curve: 10}, {X: 10, Y: 0, Curve: 20}, {X: 10, Y: 10, Curve: 30}, {X: 0, Y: 10, Curve: 40}}
+	want = []Vertex{{X: 0, Y: 10, Curve: -30}, {X: 10, Y: 10, Curve: -20}, {X: 10, Y: 0, Curve: -10}, {X: 0, Y: 0, Curve: -40}}
+	got := Reverse(loop)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(Reverse(got), loop) {
+		t.Errorf("reversing twice gave %v, want %v", Reverse(got), loop)
+	}
+	// the flattened shape is the same, with its area negated
+	a, b := Area(Flatten(loop, 0.01)), Area(Flatten(got, 0.01))
+	if math.Abs(a+b) > 1e-9 {
+		t.Errorf("reversed area %v isn't the negation of %v", b, a)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	minX, minY, maxX, maxY := Bounds([]Point{{3, -1}, {-2, 4}, {7, 2}})
+	if minX != -2 || minY != -1 || maxX != 7 || maxY != 4 {
+		t.Errorf("got bounds %v,%v %v,%v, want -2,-1 7,4", minX, minY, maxX, maxY)
+	}
+	minX, _, maxX, _ = Bounds(nil)
+	if !math.IsInf(minX, 1) || !math.IsInf(maxX, -1) {
+		t.Errorf("got bounds %v..%v for no points, want +Inf..-Inf", minX, maxX)
+	}
+}
+
+func sameVertices(a, b []Vertex) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !samePoint(a[i], b[i]) || math.Abs(a[i].Curve-b[i].Curve) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRoundCorners(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	tests := []struct {
+		name   string
+		points []Point
+		radius float64
+		want   []Vertex
+	}{
+		{
+			name:   "square",
+			points: square,
+			radius: 2,
+			want: []Vertex{
+				{X: 0, Y: 2, Curve: 90}, {X: 2, Y: 0},
+				{X: 8, Y: 0, Curve: 90}, {X: 10, Y: 2},
+				{X: 10, Y: 8, Curve: 90}, {X: 8, Y: 10},
+				{X: 2, Y: 10, Curve: 90}, {X: 0, Y: 8},
+			},
+		},
+		{
+			name:   "clockwise square",
+			points: []Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+			radius: 2,
+			want: []Vertex{
+				{X: 2, Y: 0, Curve: -90}, {X: 0, Y: 2},
+				{X: 0, Y: 8, Curve: -90}, {X: 2, Y: 10},
+				{X: 8, Y: 10, Curve: -90}, {X: 10, Y: 8},
+				{X: 10, Y: 2, Curve: -90}, {X: 8, Y: 0},
+			},
+		},
+		{
+			// the radius is reduced to half an edge, leaving a circle
+			name:   "radius too large",
+			points: square,
+			radius: 20,
+			want:   []Vertex{{X: 0, Y: 5, Curve: 90}, {X: 5, Y: 0, Curve: 90}, {X: 10, Y: 5, Curve: 90}, {X: 5, Y: 10, Curve: 90}},
+		},
+		{
+			name:   "collinear vertex",
+			points: []Point{{0, 0}, {5, 0}, {10, 0}, {10, 10}, {0, 10}},
+			radius: 1,
+			want: []Vertex{
+				{X: 0, Y: 1, Curve: 90}, {X: 1, Y: 0},
+				{X: 5, Y: 0},
+				{X: 9, Y: 0, Curve: 90}, {X: 10, Y: 1},
+				{X: 10, Y: 9, Curve: 90}, {X: 9, Y: 10},
+				{X: 1, Y: 10, Curve: 90}, {X: 0, Y: 9},
+			},
+		},
+		{
+			name:   "zero radius",
+			points: square,
+			want:   []Vertex{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}},
+		},
+		{
+			name:   "too few points",
+			points: []Point{{0, 0}, {10, 0}},
+			radius: 2,
+			want:   []Vertex{{X: 0, Y: 0}, {X: 10, Y: 0}},
+		},
+	}
+	for _, test := range tests {
+		if got := RoundCorners(test.points, test.radius); !sameVertices(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+	// a fully rounded rectangle is a slot: each end is a pair of quarter
+	// circles, with no zero-length edge between them
+	want := []Vertex{
+		{X: -5, Y: 0, Curve: 90}, {X: -3, Y: -2},
+		{X: 3, Y: -2, Curve: 90}, {X: 5, Y: 0, Curve: 90}, {X: 3, Y: 2},
+		{X: -3, Y: 2, Curve: 90},
+	}
+	if got := RoundedRectangle(0, 0, 10, 4, 2); !sameVertices(got, want) {
+		t.Errorf("slot: got %v, want %v", got, want)
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	// a U shape, open at the top
+	u := []Point{{0, 0}, {10, 0}, {10, 10}, {7, 10}, {7, 3}, {3, 3}, {3, 10}, {0, 10}}
+	tests := []struct {
+		name   string
+		p      Point
+		inside bool
+	}{
+		{name: "base", p: Point{5, 1}, inside: true},
+		{name: "left arm", p: Point{1, 8}, inside: true},
+		{name: "right arm", p: Point{9, 8}, inside: true},
+		{name: "notch", p: Point{5, 8}},
+		{name: "below", p: Point{5, -1}},
+		{name: "beside", p: Point{11, 5}},
+	}
+	for _, test := range tests {
+		if got := PointInPolygon(test.p, u); got != test.inside {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.inside)
+		}
+	}
+	if PointInPolygon(Point{0, 0}, nil) {
+		t.Errorf("point inside an empty polygon")
+	}
+}
+
+func TestSegmentDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Point
+		a, b Point
+		want float64
+	}{
+		{name: "beside", p: Point{5, 3}, a: Point{0, 0}, b: Point{10, 0}, want: 3},
+		{name: "on", p: Point{5, 0}, a: Point{0, 0}, b: Point{10, 0}, want: 0},
+		{name: "before start", p: Point{-3, 4}, a: Point{0, 0}, b: Point{10, 0}, want: 5},
+		{name: "past end", p: Point{13, -4}, a: Point{0, 0}, b: Point{10, 0}, want: 5},
+		{name: "diagonal", p: Point{0, 2}, a: Point{0, 0}, b: Point{2, 2}, want: math.Sqrt2},
+		{name: "zero length", p: Point{4, 4}, a: Point{1, 0}, b: Point{1, 0}, want: 5},
+	}
+	for _, test := range tests {
+		if got := SegmentDistance(test.p, test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPolygonDistance(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	tests := []struct {
+		name string
+		p    Point
+		want float64
+	}{
+		{name: "inside", p: Point{2, 5}, want: 2},
+		{name: "centre", p: Point{5, 5}, want: 5},
+		{name: "outside", p: Point{15, 5}, want: 5},
+		{name: "off a corner", p: Point{13, 14}, want: 5},
+		{name: "on an edge", p: Point{10, 5}, want: 0},
+	}
+	for _, test := range tests {
+		if got := PolygonDistance(test.p, square); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+	if got := PolygonDistance(Point{0, 0}, nil); !math.IsInf(got, 1) {
+		t.Errorf("empty polygon: got %v, want +Inf", got)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+// Transform is a 2D affine transform, mapping (x,y) to
+// (A*x + B*y + C, D*x + E*y + F). The zero value is not useful; start with
+// Identity or one of the other constructors.
+//
+// Eagle can only represent rotations, mirroring and uniform scaling of arcs,
+// circles and text, so the methods that transform Eagle objects assume the
+// transform is made of these and translations.
+type Transform struct {
+	A, B, C float64
+	D, E, F float64
+}
+
+// Identity returns a transform that changes nothing
+func Identity() Transform {
+	return Transform{A: 1, E: 1}
+}
+
+// Translate returns a transform that moves things by (dx,dy)
+func Translate(dx, dy float64) Transform {
+	return Transform{A: 1, C: dx, E: 1, F: dy}
+}
+
+// Rotate returns a transform that rotates things counter-clockwise about
+// the origin by the given angle in degrees
+func Rotate(degrees float64) Transform {
+	sin, cos := math.Sincos(degrees * math.Pi / 180.0)
+	// keep right angles exact, as they are by far the most common
+	sin, cos = math.Round(sin*1e12)/1e12, math.Round(cos*1e12)/1e12
+	return Transform{A: cos, B: -sin, D: sin, E: cos}
+}
+
+// RotateAbout returns a transform that rotates things counter-clockwise
+// about (x,y) by the given angle in degrees
+func RotateAbout(degrees, x, y float64) Transform {
+	return Translate(-x, -y).Then(Rotate(degrees)).Then(Translate(x, y))
+}
+
+// Mirror returns a transform that mirrors things about the Y axis, as Eagle
+// does when placing objects on the bottom of a board
+func Mirror() Transform {
+	return Transform{A: -1, E: 1}
+}
+
+// Scale returns a transform that scales things about the origin
+func Scale(sx, sy float64) Transform {
+	return Transform{A: sx, E: sy}
+}
+
+// FromRotation returns the transform described by an Eagle rotation:
+// mirroring, if any, followed by a counter-clockwise rotation
+func FromRotation(r eagle.Rotation) Transform {
+	t := Identity()
+	if r.Mirror {
+		t = Mirror()
+	}
+	return t.Then(Rotate(r.Angle))
+}
+
+// Placement returns the transform that maps coordinates relative to an
+// object's origin, eg. within a package, to the coordinates the object is
+// placed at
+func Placement(x, y float64, r eagle.Rotation) Transform {
+	return FromRotation(r).Then(Translate(x, y))
+}
+
+// Then returns a transform that applies t and then u
+func (t Transform) Then(u Transform) Transform {
+	return Transform{
+		A: u.A*t.A + u.B*t.D,
+		B: u.A*t.B + u.B*t.E,
+		C: u.A*t.C + u.B*t.F + u.C,
+		D: u.D*t.A + u.E*t.D,
+		E: u.D*t.B + u.E*t.E,
+		F: u.D*t.C + u.E*t.F + u.F,
+	}
+}
+
+// Determinant returns the determinant of the linear part of the transform.
+// It is negative for transforms that mirror things.
+func (t Transform) Determinant() float64 {
+	return t.A*t.E - t.B*t.D
+}
+
+// Invert returns the transform that undoes t
+func (t Transform) Invert() (Transform, error) {
+	det := t.Determinant()
+	if math.Abs(det) < 1e-12 {
+		return Transform{}, fmt.Errorf("transform can't be inverted")
+	}
+	return Transform{
+		A: t.E / det,
+		B: -t.B / det,
+		C: (t.B*t.F - t.E*t.C) / det,
+		D: -t.D / det,
+		E: t.A / det,
+		F: (t.D*t.C - t.A*t.F) / det,
+	}, nil
+}
+
+// Apply transforms a point
+func (t Transform) Apply(x, y float64) (float64, float64) {
+	return t.A*x + t.B*y + t.C, t.D*x + t.E*y + t.F
+}
+
+// Point transforms a Point
+func (t Transform) Point(p Point) Point {
+	x, y := t.Apply(p.X, p.Y)
+	return Point{X: x, Y: y}
+}
+
+// Mirrored reports whether the transform mirrors things
+func (t Transform) Mirrored() bool {
+	return t.Determinant() < 0
+}
+
+// ScaleFactor returns how much the transform scales lengths by, assuming
+// it scales uniformly
+func (t Transform) ScaleFactor() float64 {
+	return math.Sqrt(math.Abs(t.Determinant()))
+}
+
+// curve transforms the angle swept by an arc, which changes direction if
+// the transform mirrors things
+func (t Transform) curve(curve float64) float64 {
+	if t.Mirrored() {
+		return -curve
+	}
+	return curve
+}
+
+// Rotation composes the transform with an Eagle rotation, returning the
+// rotation an object would need to appear transformed. The spin flag is
+// unchanged.
+func (t Transform) Rotation(r eagle.Rotation) eagle.Rotation {
+	l := FromRotation(r).Then(Transform{A: t.A, B: t.B, D: t.D, E: t.E})
+	result := eagle.Rotation{Spin: r.Spin, Mirror: l.Mirrored()}
+	// the angle is that of the X axis after the mirroring, if any
+	x, y := l.A, l.D
+	if result.Mirror {
+		x, y = -x, -y
+	}
+	angle := math.Atan2(y, x) * 180.0 / math.Pi
+	angle = math.Round(angle*1e6) / 1e6
+	angle = math.Mod(angle+360.0, 360.0)
+	result.Angle = angle
+	return result
+}
+
+// rotation transforms an Eagle rot attribute value
+func (t Transform) rotation(rot string) (string, error) {
+	r, err := eagle.ParseRotation(rot)
+	if err != nil {
+		return "", err
+	}
+	r = t.Rotation(r)
+	if r == (eagle.Rotation{}) {
+		return "", nil
+	}
+	return r.String(), nil
+}
+
+// Vertices transforms a polygon
+func (t Transform) Vertices(vertices []Vertex) []Vertex {
+	transformed := []Vertex{}
+	for _, v := range vertices {
+		x, y := t.Apply(v.X, v.Y)
+		transformed = append(transformed, Vertex{X: x, Y: y, Curve: t.curve(v.Curve)})
+	}
+	return transformed
+}
+
+// Wire transforms an Eagle wire
+func (t Transform) Wire(w eagle.Wire) eagle.Wire {
+	w.X1, w.Y1 = t.Apply(w.X1, w.Y1)
+	w.X2, w.Y2 = t.Apply(w.X2, w.Y2)
+	w.Width *= t.ScaleFactor()
+	w.Curve = t.curve(w.Curve)
+	return w
+}
+
+// Circle transforms an Eagle circle
+func (t Transform) Circle(c eagle.Circle) eagle.Circle {
+	c.X, c.Y = t.Apply(c.X, c.Y)
+	c.Radius *= t.ScaleFactor()
+	c.Width *= t.ScaleFactor()
+	return c
+}
+
+// Hole transforms an Eagle hole
+func (t Transform) Hole(h eagle.Hole) eagle.Hole {
+	h.X, h.Y = t.Apply(h.X, h.Y)
+	h.Drill *= t.ScaleFactor()
+	return h
+}
+
+// Polygon transforms an Eagle polygon
+func (t Transform) Polygon(p eagle.Polygon) eagle.Polygon {
+	vertices := make([]eagle.Vertex, len(p.Vertices))
+	for index, v := range p.Vertices {
+		v.X, v.Y = t.Apply(v.X, v.Y)
+		v.Curve = t.curve(v.Curve)
+		vertices[index] = v
+	}
+	p.Vertices = vertices
+	p.Width *= t.ScaleFactor()
+	return p
+}
+
+// Rectangle transforms an Eagle rectangle. Eagle rotates rectangles about
+// their centres, and as rectangles are symmetrical, mirroring only changes
+// their rotation.
+func (t Transform) Rectangle(r eagle.Rectangle) (eagle.Rectangle, error) {
+	rot, err := eagle.ParseRotation(r.Rotate)
+	if err != nil {
+		return r, err
+	}
+	cx, cy := t.Apply((r.X1+r.X2)/2, (r.Y1+r.Y2)/2)
+	halfW := math.Abs(r.X2-r.X1) / 2 * t.ScaleFactor()
+	halfH := math.Abs(r.Y2-r.Y1) / 2 * t.ScaleFactor()
+	rot = t.Rotation(eagle.Rotation{Angle: rot.Angle})
+	r.X1, r.Y1, r.X2, r.Y2 = cx-halfW, cy-halfH, cx+halfW, cy+halfH
+	r.Rotate = ""
+	if rot.Angle != 0 {
+		r.Rotate = eagle.Rotation{Angle: rot.Angle}.String()
+	}
+	return r, nil
+}
+
+// Text transforms an Eagle text. Alignment is relative to the text's own
+// rotation and mirroring, so it doesn't change.
+func (t Transform) Text(text eagle.Text) (eagle.Text, error) {
+	rot, err := t.rotation(text.Rotate)
+	if err != nil {
+		return text, err
+	}
+	text.X, text.Y = t.Apply(text.X, text.Y)
+	text.Size *= t.ScaleFactor()
+	text.Rotate = rot
+	return text, nil
+}
+
+// Element transforms the placement of an Eagle element. Mirroring an
+// element moves it to the other side of the board.
+func (t Transform) Element(e eagle.Element) (eagle.Element, error) {
+	rot, err := t.rotation(e.Rotate)
+	if err != nil {
+		return e, err
+	}
+	e.X, e.Y = t.Apply(e.X, e.Y)
+	e.Rotate = rot
+	return e, nil
+}
+
+// Dimension transforms an Eagle dimension. Its text and extension lines
+// are drawn by Eagle from the three reference points, so scaling them is
+// all that's needed.
+func (t Transform) Dimension(d eagle.Dimension) eagle.Dimension {
+	scale := t.ScaleFactor()
+	d.X1, d.Y1 = t.Apply(d.X1, d.Y1)
+	d.X2, d.Y2 = t.Apply(d.X2, d.Y2)
+	d.X3, d.Y3 = t.Apply(d.X3, d.Y3)
+	d.Width *= scale
+	d.ExtWidth *= scale
+	d.ExtLength *= scale
+	d.ExtOffset *= scale
+	d.TextSize *= scale
+	return d
+}
+
+// Frame transforms an Eagle frame. Frames can't be rotated, so the result
+// covers the transformed corners, with columns and rows swapped by quarter
+// turns.
+func (t Transform) Frame(f eagle.Frame) eagle.Frame {
+	x1, y1 := t.Apply(f.X1, f.Y1)
+	x2, y2 := t.Apply(f.X2, f.Y2)
+	f.X1, f.X2 = math.Min(x1, x2), math.Max(x1, x2)
+	f.Y1, f.Y2 = math.Min(y1, y2), math.Max(y1, y2)
+	if math.Abs(t.B) > math.Abs(t.A) {
+		f.Columns, f.Rows = f.Rows, f.Columns
+	}
+	return f
+}
+
+// Plain transforms everything in an Eagle Plain
+func (t Transform) Plain(p eagle.Plain) (eagle.Plain, error) {
+	out := p
+	out.Wires, out.Circles, out.Holes, out.Polygons = nil, nil, nil, nil
+	out.Rectangles, out.Texts, out.Dimensions, out.Frames = nil, nil, nil, nil
+	for _, w := range p.Wires {
+		out.Wires = append(out.Wires, t.Wire(w))
+	}
+	for _, c := range p.Circles {
+		out.Circles = append(out.Circles, t.Circle(c))
+	}
+	for _, h := range p.Holes {
+		out.Holes = append(out.Holes, t.Hole(h))
+	}
+	for _, polygon := range p.Polygons {
+		out.Polygons = append(out.Polygons, t.Polygon(polygon))
+	}
+	for _, r := range p.Rectangles {
+		r, err := t.Rectangle(r)
+		if err != nil {
+			return p, err
+		}
+		out.Rectangles = append(out.Rectangles, r)
+	}
+	for _, text := range p.Texts {
+		text, err := t.Text(text)
+		if err != nil {
+			return p, err
+		}
+		out.Texts = append(out.Texts, text)
+	}
+	for _, d := range p.Dimensions {
+		out.Dimensions = append(out.Dimensions, t.Dimension(d))
+	}
+	for _, f := range p.Frames {
+		out.Frames = append(out.Frames, t.Frame(f))
+	}
+	return out, nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+// near reports whether two transforms are the same, allowing for rounding
+func near(t, u Transform) bool {
+	for _, d := range []float64{t.A - u.A, t.B - u.B, t.C - u.C, t.D - u.D, t.E - u.E, t.F - u.F} {
+		if math.Abs(d) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestThen(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+		x, y      float64
+		wantX     float64
+		wantY     float64
+	}{
+		{name: "translate then rotate", transform: Translate(1, 0).Then(Rotate(90)), wantX: 0, wantY: 1},
+		{name: "rotate then translate", transform: Rotate(90).Then(Translate(1, 0)), wantX: 1, wantY: 0},
+		{name: "rotate about", transform: RotateAbout(90, 10, 10), x: 12, y: 10, wantX: 10, wantY: 12},
+		{name: "translate, rotate, scale", transform: Translate(3, 4).Then(Rotate(90)).Then(Scale(2, 2)), x: 1, wantX: -8, wantY: 8},
+		{name: "placement", transform: Placement(10, 20, eagle.Rotation{Angle: 180}), x: 1, y: 2, wantX: 9, wantY: 18},
+	}
+	for _, test := range tests {
+		x, y := test.transform.Apply(test.x, test.y)
+		if math.Abs(x-test.wantX) > 1e-9 || math.Abs(y-test.wantY) > 1e-9 {
+			t.Errorf("%s: got (%v,%v), want (%v,%v)", test.name, x, y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestInvert(t *testing.T) {
+	transforms := []Transform{
+		Identity(),
+		Translate(3, 4).Then(Rotate(30)).Then(Scale(2, 2)),
+		Placement(10, 20, eagle.Rotation{Mirror: true, Angle: 270}),
+	}
+	for _, transform := range transforms {
+		inverse, err := transform.Invert()
+		if err != nil {
+			t.Errorf("%+v: %v", transform, err)
+			continue
+		}
+		if got := transform.Then(inverse); !near(got, Identity()) {
+			t.Errorf("%+v then its inverse is %+v, not the identity", transform, got)
+		}
+		if got := inverse.Then(transform); !near(got, Identity()) {
+			t.Errorf("%+v after its inverse is %+v, not the identity", transform, got)
+		}
+	}
+	if _, err := Scale(0, 1).Invert(); err == nil {
+		t.Errorf("expected an error inverting a transform that flattens everything")
+	}
+}
+
+func TestMirrorRotation(t *testing.T) {
+	// MR270 mirrors and then rotates, which swaps X and Y
+	mr270 := eagle.Rotation{Mirror: true, Angle: 270}
+	transform := FromRotation(mr270)
+	if !near(transform, Mirror().Then(Rotate(270))) {
+		t.Errorf("MR270 is %+v, want mirror then rotate", transform)
+	}
+	if x, y := transform.Apply(2, 1); math.Abs(x-1) > 1e-9 || math.Abs(y-2) > 1e-9 {
+		t.Errorf("MR270 moves (2,1) to (%v,%v), want (1,2)", x, y)
+	}
+	if !transform.Mirrored() {
+		t.Errorf("MR270 should mirror")
+	}
+	if got := Identity().Rotation(mr270); got != mr270 {
+		t.Errorf("got rotation %v, want %v", got, mr270)
+	}
+	// rotating a mirrored object by a further 90 degrees brings it round
+	// to MR0
+	if got := Rotate(90).Rotation(mr270); got != (eagle.Rotation{Mirror: true}) {
+		t.Errorf("MR270 rotated by 90 is %v, want MR0", got)
+	}
+	// and mirroring it again cancels out the mirroring
+	if got := Mirror().Rotation(mr270); got != (eagle.Rotation{Angle: 90}) {
+		t.Errorf("MR270 mirrored is %v, want R90", got)
+	}
+}
+
+func TestWire(t *testing.T) {
+	w := eagle.Wire{X1: 1, Y1: 2, X2: 3, Y2: 4, Width: 0.5, Curve: 90, Layer: 20}
+	got := Placement(10, 20, eagle.Rotation{Mirror: true}).Wire(w)
+	want := eagle.Wire{X1: 9, Y1: 22, X2: 7, Y2: 24, Width: 0.5, Curve: -90, Layer: 20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mirrored wire: got %+v, want %+v", got, want)
+	}
+	got = Scale(2, 2).Wire(w)
+	want = eagle.Wire{X1: 2, Y1: 4, X2: 6, Y2: 8, Width: 1, Curve: 90, Layer: 20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scaled wire: got %+v, want %+v", got, want)
+	}
+}
+
+func TestCircle(t *testing.T) {
+	c := eagle.Circle{X: 1, Y: 1, Radius: 3, Width: 0.2, Layer: 21}
+	got := Scale(2, 2).Then(Translate(1, 1)).Circle(c)
+	want := eagle.Circle{X: 3, Y: 3, Radius: 6, Width: 0.4, Layer: 21}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	p := eagle.Polygon{
+		Width:    0.3,
+		Vertices: []eagle.Vertex{{X: 1, Y: 0, Curve: 45}, {X: 0, Y: 1}, {X: 0, Y: 0}},
+	}
+	tests := []struct {
+		name      string
+		transform Transform
+		want      []eagle.Vertex
+	}{
+		{name: "rotated", transform: Rotate(90), want: []eagle.Vertex{{X: 0, Y: 1, Curve: 45}, {X: -1, Y: 0}, {X: 0, Y: 0}}},
+		{name: "mirrored", transform: Mirror(), want: []eagle.Vertex{{X: -1, Y: 0, Curve: -45}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+	}
+	for _, test := range tests {
+		got := test.transform.Polygon(p)
+		if got.Width != p.Width || len(got.Vertices) != len(test.want) {
+			t.Errorf("%s: got %+v", test.name, got)
+			continue
+		}
+		for index, v := range got.Vertices {
+			w := test.want[index]
+			if math.Abs(v.X-w.X) > 1e-9 || math.Abs(v.Y-w.Y) > 1e-9 || v.Curve != w.Curve {
+				t.Errorf("%s: vertex %d is %+v, want %+v", test.name, index, v, w)
+			}
+		}
+	}
+	// the original polygon is left alone
+	if !reflect.DeepEqual(p.Vertices[0], eagle.Vertex{X: 1, Y: 0, Curve: 45}) {
+		t.Errorf("original polygon was changed to %+v", p)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+		text      eagle.Text
+		want      eagle.Text
+		wantErr   bool
+	}{
+		{
+			name:      "placed",
+			transform: Placement(5, 5, eagle.Rotation{Angle: 90}),
+			text:      eagle.Text{X: 1, Y: 0, Size: 2, Rotate: "R0", Text: "A"},
+			want:      eagle.Text{X: 5, Y: 6, Size: 2, Rotate: "R90", Text: "A"},
+		},
+		{
+			name:      "mirrored",
+			transform: Mirror(),
+			text:      eagle.Text{X: 1, Y: 0, Size: 2, Rotate: "R90", Text: "A"},
+			want:      eagle.Text{X: -1, Y: 0, Size: 2, Rotate: "MR270", Text: "A"},
+		},
+		{
+			name:      "scaled",
+			transform: Scale(2, 2),
+			text:      eagle.Text{X: 1, Y: 1, Size: 2, Text: "A"},
+			want:      eagle.Text{X: 2, Y: 2, Size: 4, Text: "A"},
+		},
+		{
+			name:      "bad rotation",
+			transform: Identity(),
+			text:      eagle.Text{Rotate: "R9O"},
+			wantErr:   true,
+		},
+	}
+	for _, test := range tests {
+		got, err := test.transform.Text(test.text)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestElement(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+		element   eagle.Element
+		wantX     float64
+		wantY     float64
+		wantRot   string
+	}{
+		{name: "unrotated", transform: Translate(1, 1), element: eagle.Element{X: 1, Y: 2}, wantX: 2, wantY: 3, wantRot: ""},
+		{name: "mirrored element rotated", transform: Rotate(180), element: eagle.Element{X: 1, Y: 2, Rotate: "MR90"}, wantX: -1, wantY: -2, wantRot: "MR270"},
+		{name: "spin is kept", transform: Rotate(90), element: eagle.Element{X: 1, Y: 0, Rotate: "SR90"}, wantX: 0, wantY: 1, wantRot: "SR180"},
+		// mirroring an element moves it to the other side of the board
+		{name: "mirrored", transform: Mirror(), element: eagle.Element{X: 1, Y: 2, Rotate: "R0"}, wantX: -1, wantY: 2, wantRot: "MR0"},
+	}
+	for _, test := range tests {
+		got, err := test.transform.Element(test.element)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if math.Abs(got.X-test.wantX) > 1e-9 || math.Abs(got.Y-test.wantY) > 1e-9 || got.Rotate != test.wantRot {
+			t.Errorf("%s: got (%v,%v) %q, want (%v,%v) %q", test.name, got.X, got.Y, got.Rotate, test.wantX, test.wantY, test.wantRot)
+		}
+	}
+	if _, err := Identity().Element(eagle.Element{Rotate: "X90"}); err == nil {
+		t.Errorf("expected an error for a bad rotation")
+	}
+}
+
+func TestDimension(t *testing.T) {
+	d := eagle.Dimension{X1: 0, Y1: 0, X2: 10, Y2: 0, X3: 5, Y3: 3, Layer: 47, Width: 0.1, ExtWidth: 0.1, ExtLength: 1, ExtOffset: 0.5, TextSize: 1.27}
+	got := Rotate(90).Then(Scale(2, 2)).Dimension(d)
+	want := eagle.Dimension{X1: 0, Y1: 0, X2: 0, Y2: 20, X3: -6, Y3: 10, Layer: 47, Width: 0.2, ExtWidth: 0.2, ExtLength: 2, ExtOffset: 1, TextSize: 2.54}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	f := eagle.Frame{X1: 0, Y1: 0, X2: 40, Y2: 20, Columns: 4, Rows: 2, Layer: 97}
+	tests := []struct {
+		name      string
+		transform Transform
+		want      eagle.Frame
+	}{
+		{name: "moved", transform: Translate(5, 5), want: eagle.Frame{X1: 5, Y1: 5, X2: 45, Y2: 25, Columns: 4, Rows: 2, Layer: 97}},
+		{name: "mirrored", transform: Mirror(), want: eagle.Frame{X1: -40, Y1: 0, X2: 0, Y2: 20, Columns: 4, Rows: 2, Layer: 97}},
+		{name: "quarter turn", transform: Rotate(90), want: eagle.Frame{X1: -20, Y1: 0, X2: 0, Y2: 40, Columns: 2, Rows: 4, Layer: 97}},
+	}
+	for _, test := range tests {
+		if got := test.transform.Frame(f); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPlain(t *testing.T) {
+	p := eagle.NewPlain()
+	p.Wires = []eagle.Wire{{X1: 0, Y1: 0, X2: 1, Y2: 0}}
+	p.Circles = []eagle.Circle{{X: 1, Y: 1, Radius: 1}}
+	p.Holes = []eagle.Hole{{X: 2, Y: 2, Drill: 3}}
+	p.Polygons = []eagle.Polygon{{Vertices: []eagle.Vertex{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}}}
+	p.Rectangles = []eagle.Rectangle{{X1: 0, Y1: 0, X2: 2, Y2: 1}}
+	p.Texts = []eagle.Text{{X: 3, Y: 3, Size: 1, Text: "A"}}
+	p.Dimensions = []eagle.Dimension{{X1: 0, Y1: 0, X2: 4, Y2: 0, X3: 2, Y3: 1}}
+	p.Frames = []eagle.Frame{{X1: 0, Y1: 0, X2: 10, Y2: 10}}
+	move := Translate(100, 0)
+	got, err := move.Plain(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every kind of object is moved
+	xs := []float64{
+		got.Wires[0].X1, got.Circles[0].X, got.Holes[0].X, got.Polygons[0].Vertices[0].X,
+		got.Rectangles[0].X1, got.Texts[0].X, got.Dimensions[0].X1, got.Frames[0].X1,
+	}
+	originals := []float64{0, 1, 2, 0, 0, 3, 0, 0}
+	for index, x := range xs {
+		if x != originals[index]+100 {
+			t.Errorf("object %d is at X=%v, want %v", index, x, originals[index]+100)
+		}
+	}
+	p.Texts[0].Rotate = "R9O"
+	if _, err := move.Plain(p); err == nil {
+		t.Errorf("expected an error for a bad text rotation")
+	}
+}
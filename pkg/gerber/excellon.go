@@ -0,0 +1,63 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package gerber
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+// Excellon generates an Excellon drill file for a set of non-plated holes.
+// Holes are grouped by drill size, with one tool per size, smallest first.
+func Excellon(holes []eagle.Hole) []byte {
+	sizes := []float64{}
+	byDrill := map[float64][]eagle.Hole{}
+	for _, hole := range holes {
+		if _, ok := byDrill[hole.Drill]; !ok {
+			sizes = append(sizes, hole.Drill)
+		}
+		byDrill[hole.Drill] = append(byDrill[hole.Drill], hole)
+	}
+	sort.Float64s(sizes)
+	var out strings.Builder
+	out.WriteString("M48\n")
+	out.WriteString("; generated by go-eagle\n")
+	out.WriteString("; #@! TF.FileFunction,NonPlated,1,2,NPTH\n")
+	out.WriteString("FMAT,2\n")
+	out.WriteString("METRIC,TZ\n")
+	for i, size := range sizes {
+		fmt.Fprintf(&out, "T%dC%.3f\n", i+1, size)
+	}
+	out.WriteString("%\n")
+	out.WriteString("G90\n")
+	out.WriteString("G05\n")
+	for i, size := range sizes {
+		fmt.Fprintf(&out, "T%d\n", i+1)
+		for _, hole := range byDrill[size] {
+			fmt.Fprintf(&out, "X%.3fY%.3f\n", hole.X, hole.Y)
+		}
+	}
+	out.WriteString("M30\n")
+	return []byte(out.String())
+}
@@ -0,0 +1,347 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package gerber generates RS-274X Gerber and Excellon drill files directly
+// from an Eagle board, so that panels can be sent to a fab house without a
+// trip through Eagle's CAM processor.
+package gerber
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/vectorfont"
+)
+
+const (
+	// HoleClearance is how far copper is cleared back from the edge of a
+	// non-plated hole. This matches the copper pullback used for panel edges.
+	HoleClearance = 0.5
+
+	// ProfileWidth is the line width used when drawing the board profile.
+	// Fab houses only care about the centre line, so keep it thin.
+	ProfileWidth = 0.1
+
+	// HairlineWidth is used in place of zero-width wires, which Eagle draws
+	// as thin as possible. A zero-diameter aperture draws nothing.
+	HairlineWidth = 0.1
+)
+
+// fabLayer describes one Gerber file in a fabrication package. Copper is
+// cleared around holes even where a copper pour already avoids them, so
+// that hand-drawn copper never runs over a hole.
+type fabLayer struct {
+	extension string
+	function  string
+	layers    []string
+	// copper layers clear cutout polygons and holes from everything else
+	copper bool
+	// soldermask layers are opened over holes
+	mask    bool
+	profile bool
+}
+
+// fabLayers uses the file extensions most fab houses (OSHPark included)
+// recognise without further instruction
+var fabLayers = []fabLayer{
+	{extension: "GTL", function: "Copper,L1,Top", layers: []string{"Top"}, copper: true},
+	{extension: "GBL", function: "Copper,L2,Bot", layers: []string{"Bottom"}, copper: true},
+	{extension: "GTS", function: "Soldermask,Top", layers: []string{"tStop"}, mask: true},
+	{extension: "GBS", function: "Soldermask,Bot", layers: []string{"bStop"}, mask: true},
+	{extension: "GTO", function: "Legend,Top", layers: []string{"tPlace", "tNames"}},
+	{extension: "GBO", function: "Legend,Bot", layers: []string{"bPlace", "bNames"}},
+	{extension: "GKO", function: "Profile,NP", layers: []string{"Dimension", "Milling"}, profile: true},
+}
+
+// WriteFabFiles writes a Gerber file for each fabrication layer, plus an
+// Excellon drill file, for the Plain section of an Eagle board. Filenames
+// are formed by appending conventional extensions to basename. The names of
+// the files written are returned.
+func WriteFabFiles(e *eagle.Eagle, basename string) ([]string, error) {
+	written := []string{}
+	for _, fl := range fabLayers {
+		filename := basename + "." + fl.extension
+		gerber, err := renderLayer(e, fl)
+		if err != nil {
+			return written, fmt.Errorf("%s: %v", filename, err)
+		}
+		if err := ioutil.WriteFile(filename, gerber, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, filename)
+	}
+	filename := basename + ".XLN"
+	if err := ioutil.WriteFile(filename, Excellon(e.Board.Plain.Holes), 0644); err != nil {
+		return written, err
+	}
+	return append(written, filename), nil
+}
+
+// renderLayer draws every Plain object on the given Eagle layers into a
+// single Gerber image
+func renderLayer(e *eagle.Eagle, fl fabLayer) ([]byte, error) {
+	im := &image{}
+	layers := map[int]bool{}
+	for _, name := range fl.layers {
+		if number, ok := e.LayerNumber(name); ok {
+			layers[number] = true
+		}
+	}
+	plain := e.Board.Plain
+	cutouts := []eagle.Polygon{}
+	for _, polygon := range plain.Polygons {
+		if !layers[polygon.Layer] {
+			continue
+		}
+		if polygon.Pour == "cutout" {
+			cutouts = append(cutouts, polygon)
+			continue
+		}
+		im.polygon(polygon)
+	}
+	for _, rectangle := range plain.Rectangles {
+		if !layers[rectangle.Layer] {
+			continue
+		}
+		if err := im.rectangle(rectangle); err != nil {
+			return nil, fmt.Errorf("rectangle on layer %d: %v", rectangle.Layer, err)
+		}
+	}
+	for _, wire := range plain.Wires {
+		if layers[wire.Layer] {
+			if fl.profile {
+				wire.Width = ProfileWidth
+			}
+			im.wire(wire)
+		}
+	}
+	for _, circle := range plain.Circles {
+		if layers[circle.Layer] {
+			if fl.profile {
+				circle.Width = ProfileWidth
+			}
+			im.circle(circle)
+		}
+	}
+	for _, text := range plain.Texts {
+		if !layers[text.Layer] {
+			continue
+		}
+		if err := im.text(text); err != nil {
+			return nil, fmt.Errorf("text %q: %v", text.Text, err)
+		}
+	}
+	if fl.copper {
+		im.polarity(true)
+		for _, polygon := range cutouts {
+			im.polygon(polygon)
+		}
+		for _, hole := range plain.Holes {
+			im.flash(hole.Drill+2*HoleClearance, hole.X, hole.Y)
+		}
+	}
+	if fl.mask {
+		for _, hole := range plain.Holes {
+			im.flash(hole.Drill, hole.X, hole.Y)
+		}
+	}
+	return im.bytes(fl.function), nil
+}
+
+// image accumulates drawing operations for a single Gerber file. Only
+// circular apertures are needed for Eagle primitives.
+type image struct {
+	apertures []float64
+	current   int
+	clear     bool
+	body      strings.Builder
+}
+
+func coord(v float64) string {
+	return strconv.FormatInt(int64(math.Round(v*1e6)), 10)
+}
+
+func (im *image) op(format string, args ...interface{}) {
+	fmt.Fprintf(&im.body, format+"\n", args...)
+}
+
+// use selects a circular aperture of the given diameter, defining it if
+// necessary
+func (im *image) use(diameter float64) {
+	index := -1
+	for i, d := range im.apertures {
+		if d == diameter {
+			index = i
+		}
+	}
+	if index < 0 {
+		im.apertures = append(im.apertures, diameter)
+		index = len(im.apertures) - 1
+	}
+	if dcode := index + 10; dcode != im.current {
+		im.op("D%d*", dcode)
+		im.current = dcode
+	}
+}
+
+func (im *image) polarity(clear bool) {
+	if clear == im.clear {
+		return
+	}
+	if clear {
+		im.op("%%LPC*%%")
+	} else {
+		im.op("%%LPD*%%")
+	}
+	im.clear = clear
+}
+
+func (im *image) move(x, y float64) {
+	im.op("X%sY%sD02*", coord(x), coord(y))
+}
+
+func (im *image) line(x, y float64) {
+	im.op("G01X%sY%sD01*", coord(x), coord(y))
+}
+
+// arc draws an Eagle-style arc from (x1,y1) to (x2,y2) sweeping curve
+// degrees, assuming the current point is already (x1,y1)
+func (im *image) arc(x1, y1, x2, y2, curve float64) {
+	if curve == 0 {
+		im.line(x2, y2)
+		return
+	}
+	cx, cy, _ := geometry.ArcCentre(x1, y1, x2, y2, curve)
+	direction := "G03" // counter-clockwise
+	if curve < 0 {
+		direction = "G02"
+	}
+	im.op("%sX%sY%sI%sJ%sD01*", direction, coord(x2), coord(y2), coord(cx-x1), coord(cy-y1))
+}
+
+func (im *image) flash(diameter, x, y float64) {
+	im.use(diameter)
+	im.op("X%sY%sD03*", coord(x), coord(y))
+}
+
+func (im *image) wire(w eagle.Wire) {
+	width := w.Width
+	if width <= 0 {
+		width = HairlineWidth
+	}
+	im.use(width)
+	im.move(w.X1, w.Y1)
+	im.arc(w.X1, w.Y1, w.X2, w.Y2, w.Curve)
+}
+
+// circle draws an Eagle circle, which is a ring of the given width, or a
+// filled disc if the width is zero
+func (im *image) circle(c eagle.Circle) {
+	if c.Width == 0 {
+		im.flash(2*c.Radius, c.X, c.Y)
+		return
+	}
+	im.use(c.Width)
+	// in multi-quadrant mode an arc ending where it started is a full circle
+	im.move(c.X+c.Radius, c.Y)
+	im.op("G03X%sY%sI%sJ0D01*", coord(c.X+c.Radius), coord(c.Y), coord(-c.Radius))
+}
+
+// region fills the area enclosed by a list of Eagle vertices. Each vertex's
+// curve applies to the edge running from it to the next vertex.
+func (im *image) region(vertices []eagle.Vertex) {
+	if len(vertices) < 3 {
+		return
+	}
+	im.op("G36*")
+	im.move(vertices[0].X, vertices[0].Y)
+	for i, v := range vertices {
+		next := vertices[(i+1)%len(vertices)]
+		im.arc(v.X, v.Y, next.X, next.Y, v.Curve)
+	}
+	im.op("G37*")
+}
+
+// polygon fills an Eagle polygon and strokes its outline, as the polygon
+// width extends the filled area by half the width all round
+func (im *image) polygon(p eagle.Polygon) {
+	im.region(p.Vertices)
+	if p.Width <= 0 || len(p.Vertices) < 2 {
+		return
+	}
+	im.use(p.Width)
+	im.move(p.Vertices[0].X, p.Vertices[0].Y)
+	for i, v := range p.Vertices {
+		next := p.Vertices[(i+1)%len(p.Vertices)]
+		im.arc(v.X, v.Y, next.X, next.Y, v.Curve)
+	}
+}
+
+// rectangle fills an Eagle rectangle
+func (im *image) rectangle(r eagle.Rectangle) error {
+	corners, err := r.Corners()
+	if err != nil {
+		return err
+	}
+	im.region(corners)
+	return nil
+}
+
+func (im *image) text(t eagle.Text) error {
+	strokes, err := vectorfont.Strokes(t)
+	if err != nil {
+		return err
+	}
+	width := vectorfont.StrokeWidth(t)
+	for _, stroke := range strokes {
+		if len(stroke) == 1 {
+			im.flash(width, stroke[0].X, stroke[0].Y)
+			continue
+		}
+		im.use(width)
+		im.move(stroke[0].X, stroke[0].Y)
+		for _, p := range stroke[1:] {
+			im.line(p.X, p.Y)
+		}
+	}
+	return nil
+}
+
+// bytes assembles the complete Gerber file
+func (im *image) bytes(function string) []byte {
+	var out strings.Builder
+	out.WriteString("G04 generated by go-eagle*\n")
+	fmt.Fprintf(&out, "%%TF.FileFunction,%s*%%\n", function)
+	out.WriteString("%FSLAX46Y46*%\n")
+	out.WriteString("%MOMM*%\n")
+	out.WriteString("%LPD*%\n")
+	for i, d := range im.apertures {
+		fmt.Fprintf(&out, "%%ADD%dC,%s*%%\n", i+10, strconv.FormatFloat(d, 'f', -1, 64))
+	}
+	out.WriteString("G75*\n")
+	out.WriteString(im.body.String())
+	out.WriteString("M02*\n")
+	return []byte(out.String())
+}
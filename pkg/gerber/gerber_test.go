@@ -0,0 +1,120 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package gerber
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testBoard returns a small board using each kind of Plain object that ends
+// up in a fabrication package
+func testBoard() *eagle.Eagle {
+	plain := eagle.NewPlain()
+	plain.Wires = []eagle.Wire{
+		// board profile, with one rounded corner
+		{X1: 0, Y1: 0, X2: 20, Y2: 0, Width: 0.254, Layer: 20},
+		{X1: 20, Y1: 0, X2: 25, Y2: 5, Width: 0.254, Layer: 20, Curve: 90},
+		{X1: 25, Y1: 5, X2: 25, Y2: 30, Width: 0.254, Layer: 20},
+		{X1: 25, Y1: 30, X2: 0, Y2: 30, Width: 0.254, Layer: 20},
+		{X1: 0, Y1: 30, X2: 0, Y2: 0, Width: 0.254, Layer: 20},
+		// zero-width silkscreen, which Eagle draws as a hairline
+		{X1: 2, Y1: 25, X2: 10, Y2: 25, Width: 0, Layer: 21},
+		{X1: 10, Y1: 25, X2: 10, Y2: 20, Width: 0.3, Layer: 21, Curve: -90},
+	}
+	plain.Circles = []eagle.Circle{
+		{X: 18, Y: 20, Radius: 2, Width: 0.3, Layer: 21},
+		{X: 18, Y: 12, Radius: 1, Width: 0, Layer: 21},
+	}
+	plain.Rectangles = []eagle.Rectangle{
+		{X1: 1, Y1: 1, X2: 24, Y2: 29, Layer: 1},
+	}
+	plain.Polygons = []eagle.Polygon{
+		{Width: 0.2, Layer: 29, Vertices: []eagle.Vertex{
+			{X: 3, Y: 8}, {X: 8, Y: 8, Curve: 90}, {X: 8, Y: 13}, {X: 3, Y: 13},
+		}},
+	}
+	plain.Holes = []eagle.Hole{
+		{X: 5, Y: 3, Drill: 3.2},
+		{X: 20, Y: 27, Drill: 3.2},
+		{X: 12.5, Y: 15, Drill: 6},
+	}
+	return &eagle.Eagle{
+		Layers: []eagle.Layer{
+			{Number: 1, Name: "Top"},
+			{Number: 20, Name: "Dimension"},
+			{Number: 21, Name: "tPlace"},
+			{Number: 29, Name: "tStop"},
+		},
+		Board: eagle.Board{Plain: plain},
+	}
+}
+
+func TestWriteFabFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gerber")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	written, err := WriteFabFiles(testBoard(), filepath.Join(dir, "panel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != len(fabLayers)+1 {
+		t.Errorf("got %d files, want %d", len(written), len(fabLayers)+1)
+	}
+	for _, filename := range written {
+		got, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", filepath.Base(filename))
+		if *update {
+			if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from %s:\n%s", filepath.Base(filename), golden, got)
+		}
+	}
+}
+
+func TestRenderLayerRotationError(t *testing.T) {
+	e := testBoard()
+	e.Board.Plain.Rectangles[0].Rotate = "R45x"
+	if _, err := renderLayer(e, fabLayers[0]); err == nil {
+		t.Errorf("expected an error for a bad rectangle rotation")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package hardware describes what is fitted through panel holes --- knobs,
+// nuts and washers, and the plugs of patch cables --- so that panels can be
+// checked for room to fit and use it all.
+package hardware
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// Kinds of panel hardware
+const (
+	Knob = "knob"
+	Nut  = "nut"
+	Plug = "plug"
+)
+
+// Catalogue lists typical diameters, in millimetres, of common panel
+// hardware by kind and name. Nuts are measured across their corners, and
+// include any washer. Sizes vary between manufacturers, so measure the
+// actual parts where space is tight.
+var Catalogue = map[string]map[string]float64{
+	Knob: {
+		"trimmer-topper": 7.0,
+		"small":          10.0,
+		"medium":         15.0,
+		"large":          20.0,
+		"xlarge":         28.0,
+	},
+	Nut: {
+		"m6-knurled": 8.0,  // 3.5mm jacks, eg. Thonkiconn
+		"m7-hex":     11.6, // 9mm pots, 10mm across flats
+		"m9-hex":     13.9, // 16mm pots, 12mm across flats
+		"3/8-hex":    14.7, // 3/8" bushings, 1/2" across flats
+	},
+	Plug: {
+		"3.5mm":  7.0,
+		"6.35mm": 12.0,
+		"banana": 9.0,
+	},
+}
+
+// Lookup finds the diameter of a catalogue item
+func Lookup(kind, name string) (float64, error) {
+	items, ok := Catalogue[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown hardware kind %q", kind)
+	}
+	diameter, ok := items[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown %s %q, expected one of: %s", kind, name, strings.Join(Names(kind), ", "))
+	}
+	return diameter, nil
+}
+
+// Names returns the sorted names of the catalogue items of a kind
+func Names(kind string) []string {
+	names := []string{}
+	for name := range Catalogue[kind] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Hardware is what is fitted through a single panel hole at (X,Y). Each
+// diameter is zero if there is no such item.
+type Hardware struct {
+	Name string
+	X, Y float64
+	Knob float64
+	Nut  float64
+	Plug float64
+}
+
+// Present reports whether any hardware is described
+func (h Hardware) Present() bool {
+	return h.Knob > 0 || h.Nut > 0 || h.Plug > 0
+}
+
+// Above returns the diameter of whatever stands proud of the nut: a knob,
+// or the plug of a patch cable
+func (h Hardware) Above() float64 {
+	return math.Max(h.Knob, h.Plug)
+}
+
+// Diameter returns the overall diameter of the hardware
+func (h Hardware) Diameter() float64 {
+	return math.Max(h.Above(), h.Nut)
+}
+
+// aboveKind names whatever stands proud of the nut
+func (h Hardware) aboveKind() string {
+	if h.Knob >= h.Plug {
+		return Knob
+	}
+	return Plug
+}
+
+// Clash is a pair of hardware items that collide, or come closer than the
+// required clearance. Overlap is how much closer they are than allowed, in
+// millimetres
+type Clash struct {
+	A, B         Hardware
+	KindA, KindB string
+	Overlap      float64
+}
+
+func (c Clash) String() string {
+	return fmt.Sprintf("%s %s and %s %s are %.2fmm too close", c.A.Name, c.KindA, c.B.Name, c.KindB, c.Overlap)
+}
+
+// Clashes finds every pair of hardware items that come within clearance
+// millimetres of each other. Nuts sit on the panel surface, below any knobs
+// and plugs, so nuts are only checked against nuts, and knobs and plugs
+// against knobs and plugs.
+func Clashes(items []Hardware, clearance float64) []Clash {
+	clashes := []Clash{}
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			a, b := items[i], items[j]
+			distance := math.Hypot(a.X-b.X, a.Y-b.Y)
+			if a.Nut > 0 && b.Nut > 0 {
+				if overlap := (a.Nut+b.Nut)/2 + clearance - distance; overlap > 0 {
+					clashes = append(clashes, Clash{A: a, B: b, KindA: Nut, KindB: Nut, Overlap: overlap})
+				}
+			}
+			if a.Above() > 0 && b.Above() > 0 {
+				if overlap := (a.Above()+b.Above())/2 + clearance - distance; overlap > 0 {
+					clashes = append(clashes, Clash{A: a, B: b, KindA: a.aboveKind(), KindB: b.aboveKind(), Overlap: overlap})
+				}
+			}
+		}
+	}
+	return clashes
+}
+
+// EdgeGap returns the distance from the outside of the hardware to the edge
+// of a panel outline, negative if the hardware overhangs the edge
+func (h Hardware) EdgeGap(outline []geometry.Point) float64 {
+	centre := geometry.Point{X: h.X, Y: h.Y}
+	distance := geometry.PolygonDistance(centre, outline)
+	if !geometry.PointInPolygon(centre, outline) {
+		distance = -distance
+	}
+	return distance - h.Diameter()/2
+}
+
+// RailOverlap returns how far the hardware, plus clearance, reaches into a
+// rail spanning bottom to top on the Y axis. Positive values overlap.
+func (h Hardware) RailOverlap(bottom, top, clearance float64) float64 {
+	reach := h.Diameter()/2 + clearance
+	return math.Min(h.Y+reach-bottom, top-(h.Y-reach))
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package hardware
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		kind, name string
+		want       float64
+		err        string
+	}{
+		{kind: Knob, name: "medium", want: 15},
+		{kind: Knob, name: "Medium", want: 15},
+		{kind: Nut, name: "m7-hex", want: 11.6},
+		{kind: Plug, name: "banana", want: 9},
+		{kind: Knob, name: "huge", err: `unknown knob "huge", expected one of: large, medium, small, trimmer-topper, xlarge`},
+		{kind: Nut, name: "banana", err: `unknown nut "banana"`},
+		{kind: "washer", name: "m7", err: `unknown hardware kind "washer"`},
+	}
+	for _, test := range tests {
+		got, err := Lookup(test.kind, test.name)
+		switch {
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s %q: got error %v, want %q", test.kind, test.name, err, test.err)
+		case test.err == "" && err != nil:
+			t.Errorf("%s %q: unexpected error %v", test.kind, test.name, err)
+		case got != test.want:
+			t.Errorf("%s %q: got %v, want %v", test.kind, test.name, got, test.want)
+		}
+	}
+}
+
+func TestClashes(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      Hardware
+		clearance float64
+		want      []Clash
+	}{
+		{
+			name: "overlapping nuts",
+			a:    Hardware{Name: "J1", Nut: 8},
+			b:    Hardware{Name: "J2", X: 7, Nut: 8},
+			want: []Clash{{KindA: Nut, KindB: Nut, Overlap: 1}},
+		},
+		{
+			name: "touching nuts",
+			a:    Hardware{Name: "J1", Nut: 8},
+			b:    Hardware{Name: "J2", X: 8, Nut: 8},
+		},
+		{
+			name:      "touching nuts without clearance",
+			a:         Hardware{Name: "J1", Nut: 8},
+			b:         Hardware{Name: "J2", X: 8, Nut: 8},
+			clearance: 1,
+			want:      []Clash{{KindA: Nut, KindB: Nut, Overlap: 1}},
+		},
+		{
+			name:      "nuts just clear",
+			a:         Hardware{Name: "J1", Nut: 8},
+			b:         Hardware{Name: "J2", X: 9.01, Nut: 8},
+			clearance: 1,
+		},
+		{
+			name:      "knob over a nut",
+			a:         Hardware{Name: "P1", Knob: 15},
+			b:         Hardware{Name: "J1", X: 5, Nut: 8},
+			clearance: 1,
+		},
+		{
+			name:      "knob and plug",
+			a:         Hardware{Name: "P1", Knob: 15, Nut: 11.6},
+			b:         Hardware{Name: "J1", Y: 10, Nut: 8, Plug: 7},
+			clearance: 1,
+			want:      []Clash{{KindA: Nut, KindB: Nut, Overlap: 0.8}, {KindA: Knob, KindB: Plug, Overlap: 2}},
+		},
+		{
+			name:      "knob and plug just clear",
+			a:         Hardware{Name: "P1", Knob: 15},
+			b:         Hardware{Name: "J1", Y: 12.01, Plug: 7},
+			clearance: 1,
+		},
+	}
+	for _, test := range tests {
+		got := Clashes([]Hardware{test.a, test.b}, test.clearance)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got clashes %v, want %d", test.name, got, len(test.want))
+			continue
+		}
+		for index, clash := range got {
+			want := test.want[index]
+			if clash.A.Name != test.a.Name || clash.B.Name != test.b.Name ||
+				clash.KindA != want.KindA || clash.KindB != want.KindB ||
+				math.Abs(clash.Overlap-want.Overlap) > 1e-9 {
+				t.Errorf("%s: got clash %q, want %s and %s overlapping by %v", test.name, clash, want.KindA, want.KindB, want.Overlap)
+			}
+		}
+	}
+}
+
+func TestEdgeGap(t *testing.T) {
+	outline := []geometry.Point{{X: 0, Y: 0}, {X: 50, Y: 0}, {X: 50, Y: 100}, {X: 0, Y: 100}}
+	tests := []struct {
+		name string
+		hw   Hardware
+		want float64
+	}{
+		{name: "clear of the edge", hw: Hardware{X: 10, Y: 50, Nut: 8}, want: 6},
+		{name: "touching the edge", hw: Hardware{X: 4, Y: 50, Nut: 8}, want: 0},
+		{name: "overhanging the edge", hw: Hardware{X: 3, Y: 50, Nut: 8}, want: -1},
+		{name: "centred off the panel", hw: Hardware{X: -1, Y: 50, Nut: 8}, want: -5},
+		{name: "knob wider than its nut", hw: Hardware{X: 45, Y: 50, Nut: 8, Knob: 15}, want: -2.5},
+	}
+	for _, test := range tests {
+		if got := test.hw.EdgeGap(outline); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got gap %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRailOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		hw          Hardware
+		bottom, top float64
+		want        float64
+	}{
+		{name: "overlapping the bottom rail", hw: Hardware{Y: 12, Nut: 8}, bottom: 3, top: 8, want: 1},
+		{name: "touching the bottom rail", hw: Hardware{Y: 13, Nut: 8}, bottom: 3, top: 8, want: 0},
+		{name: "just clear of the bottom rail", hw: Hardware{Y: 13.5, Nut: 8}, bottom: 3, top: 8, want: -0.5},
+		{name: "overlapping the top rail", hw: Hardware{Y: 113, Knob: 15}, bottom: 120.5, top: 125.5, want: 1},
+		{name: "just clear of the top rail", hw: Hardware{Y: 111.5, Knob: 15}, bottom: 120.5, top: 125.5, want: -0.5},
+	}
+	for _, test := range tests {
+		if got := test.hw.RailOverlap(test.bottom, test.top, 1); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got overlap %v, want %v", test.name, got, test.want)
+		}
+	}
+}
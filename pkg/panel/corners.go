@@ -0,0 +1,148 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+const (
+	// CornerRound is a corner rounded with an arc of the corner's size
+	CornerRound = "round"
+	// CornerChamfer is a corner cut off at 45 degrees, the cut starting the
+	// corner's size in from the corner along each edge
+	CornerChamfer = "chamfer"
+)
+
+// Corner describes the shape of a single corner of a panel. The style
+// defaults to round, and a zero size gives a square corner.
+type Corner struct {
+	Style string  `yaml:"style"`
+	Size  float64 `yaml:"size"`
+}
+
+// Corners describes the shapes of all four corners of a rectangular panel
+type Corners struct {
+	BottomLeft  Corner `yaml:"bottomLeft"`
+	BottomRight Corner `yaml:"bottomRight"`
+	TopRight    Corner `yaml:"topRight"`
+	TopLeft     Corner `yaml:"topLeft"`
+}
+
+// ShapedCorners is implemented by panels whose corners differ from each
+// other, or aren't simply rounded. The CornerRadius of such panels is
+// ignored.
+type ShapedCorners interface {
+	// Corners returns the shapes of the panel's corners
+	Corners() Corners
+}
+
+// UniformCorners returns corners all rounded to the same radius, as
+// described by Panel.CornerRadius
+func UniformCorners(radius float64) Corners {
+	c := Corner{Style: CornerRound, Size: radius}
+	return Corners{BottomLeft: c, BottomRight: c, TopRight: c, TopLeft: c}
+}
+
+// CornersOf returns the shapes of a panel's corners, whether or not it
+// implements ShapedCorners
+func CornersOf(p Panel) Corners {
+	if sc, ok := p.(ShapedCorners); ok {
+		return sc.Corners()
+	}
+	return UniformCorners(p.CornerRadius())
+}
+
+// Validate checks that the corners are sensibly described, and fit on a
+// panel of the given size
+func (c Corners) Validate(width, height float64) error {
+	names := []string{"bottom left", "bottom right", "top right", "top left"}
+	for index, corner := range c.list() {
+		switch corner.Style {
+		case "", CornerRound, CornerChamfer:
+		default:
+			return fmt.Errorf("%s corner style must be %q or %q, not %q", names[index], CornerRound, CornerChamfer, corner.Style)
+		}
+		if corner.Size < 0 {
+			return fmt.Errorf("%s corner size can't be negative", names[index])
+		}
+	}
+	if c.BottomLeft.Size+c.BottomRight.Size > width || c.TopLeft.Size+c.TopRight.Size > width {
+		return fmt.Errorf("corners are too big for the panel width")
+	}
+	if c.BottomLeft.Size+c.TopLeft.Size > height || c.BottomRight.Size+c.TopRight.Size > height {
+		return fmt.Errorf("corners are too big for the panel height")
+	}
+	return nil
+}
+
+// list returns the corners in counter-clockwise order, starting from the
+// bottom left
+func (c Corners) list() []Corner {
+	return []Corner{c.BottomLeft, c.BottomRight, c.TopRight, c.TopLeft}
+}
+
+// RectangleOutline returns the counter-clockwise outline of a rectangle
+// with opposite corners (x1,y1) and (x2,y2), x1 < x2 and y1 < y2, with its
+// corners shaped as described
+func RectangleOutline(x1, y1, x2, y2 float64, c Corners) []geometry.Vertex {
+	type vertex struct {
+		x, y       float64 // the corner itself
+		inX, inY   float64 // direction of the edge arriving at the corner
+		outX, outY float64 // direction of the edge leaving the corner
+		corner     Corner
+	}
+	corners := c.list()
+	vertices := []vertex{
+		{x: x1, y: y1, inX: 0, inY: -1, outX: 1, outY: 0, corner: corners[0]},
+		{x: x2, y: y1, inX: 1, inY: 0, outX: 0, outY: 1, corner: corners[1]},
+		{x: x2, y: y2, inX: 0, inY: 1, outX: -1, outY: 0, corner: corners[2]},
+		{x: x1, y: y2, inX: -1, inY: 0, outX: 0, outY: -1, corner: corners[3]},
+	}
+	outline := []geometry.Vertex{}
+	for _, v := range vertices {
+		s := v.corner.Size
+		if s <= 0 {
+			outline = append(outline, geometry.Vertex{X: v.x, Y: v.y})
+			continue
+		}
+		curve := 90.0
+		if v.corner.Style == CornerChamfer {
+			curve = 0
+		}
+		outline = append(outline,
+			geometry.Vertex{X: v.x - v.inX*s, Y: v.y - v.inY*s, Curve: curve},
+			geometry.Vertex{X: v.x + v.outX*s, Y: v.y + v.outY*s})
+	}
+	// where neighbouring corners take up a whole edge, drop the zero-length
+	// edge between them
+	deduped := []geometry.Vertex{}
+	for index, v := range outline {
+		next := outline[(index+1)%len(outline)]
+		if v.X == next.X && v.Y == next.Y && v.Curve == 0 {
+			continue
+		}
+		deduped = append(deduped, v)
+	}
+	return deduped
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+func TestRectangleOutline(t *testing.T) {
+	round := func(size float64) Corner { return Corner{Style: CornerRound, Size: size} }
+	chamfer := func(size float64) Corner { return Corner{Style: CornerChamfer, Size: size} }
+	tests := []struct {
+		name    string
+		x2, y2  float64
+		corners Corners
+		want    []geometry.Vertex
+	}{
+		{
+			name: "all square",
+			x2:   20, y2: 10,
+			want: []geometry.Vertex{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}, {X: 0, Y: 10}},
+		},
+		{
+			name: "all round",
+			x2:   20, y2: 10,
+			corners: UniformCorners(2),
+			want: []geometry.Vertex{
+				{X: 0, Y: 2, Curve: 90}, {X: 2, Y: 0},
+				{X: 18, Y: 0, Curve: 90}, {X: 20, Y: 2},
+				{X: 20, Y: 8, Curve: 90}, {X: 18, Y: 10},
+				{X: 2, Y: 10, Curve: 90}, {X: 0, Y: 8},
+			},
+		},
+		{
+			// an empty style is round
+			name: "mixed round, chamfer and square",
+			x2:   20, y2: 10,
+			corners: Corners{BottomLeft: round(2), BottomRight: chamfer(3), TopLeft: Corner{Size: 1}},
+			want: []geometry.Vertex{
+				{X: 0, Y: 2, Curve: 90}, {X: 2, Y: 0},
+				{X: 17, Y: 0}, {X: 20, Y: 3},
+				{X: 20, Y: 10},
+				{X: 1, Y: 10, Curve: 90}, {X: 0, Y: 9},
+			},
+		},
+		{
+			name: "round corners using the whole bottom edge",
+			x2:   10, y2: 20,
+			corners: Corners{BottomLeft: round(5), BottomRight: round(5)},
+			want: []geometry.Vertex{
+				{X: 0, Y: 5, Curve: 90}, {X: 5, Y: 0, Curve: 90}, {X: 10, Y: 5},
+				{X: 10, Y: 20}, {X: 0, Y: 20},
+			},
+		},
+		{
+			name: "chamfers using the whole bottom edge",
+			x2:   10, y2: 20,
+			corners: Corners{BottomLeft: chamfer(5), BottomRight: chamfer(5)},
+			want: []geometry.Vertex{
+				{X: 0, Y: 5}, {X: 5, Y: 0}, {X: 10, Y: 5},
+				{X: 10, Y: 20}, {X: 0, Y: 20},
+			},
+		},
+		{
+			// the edge wraps around the end of the outline
+			name: "round corners using the whole left edge",
+			x2:   20, y2: 10,
+			corners: Corners{BottomLeft: round(5), TopLeft: round(5)},
+			want: []geometry.Vertex{
+				{X: 0, Y: 5, Curve: 90}, {X: 5, Y: 0},
+				{X: 20, Y: 0}, {X: 20, Y: 10},
+				{X: 5, Y: 10, Curve: 90},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RectangleOutline(0, 0, test.x2, test.y2, test.corners)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got outline %v, want %v", got, test.want)
+			}
+			if area := geometry.Area(geometry.Flatten(got, 0.01)); area <= 0 {
+				t.Errorf("outline should run counter-clockwise, has area %v", area)
+			}
+		})
+	}
+}
+
+func TestCornersValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		corners  Corners
+		errorHas string
+	}{
+		{name: "square", corners: Corners{}},
+		{name: "uniform", corners: UniformCorners(3)},
+		{name: "exactly the panel width", corners: Corners{BottomLeft: Corner{Size: 25}, BottomRight: Corner{Style: CornerChamfer, Size: 25}}},
+		{name: "bad style", corners: Corners{TopRight: Corner{Style: "bevel", Size: 1}}, errorHas: "top right corner style"},
+		{name: "negative size", corners: Corners{BottomRight: Corner{Size: -1}}, errorHas: "bottom right corner size"},
+		{name: "too wide", corners: Corners{TopLeft: Corner{Size: 30}, TopRight: Corner{Size: 25}}, errorHas: "width"},
+		{name: "too tall", corners: Corners{BottomRight: Corner{Size: 20}, TopRight: Corner{Size: 20.5}}, errorHas: "height"},
+	}
+	for _, test := range tests {
+		err := test.corners.Validate(50, 40)
+		if test.errorHas == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name, err, test.errorHas)
+		}
+	}
+}
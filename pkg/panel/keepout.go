@@ -0,0 +1,76 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+)
+
+const (
+	// KeepoutRectangle is an axis-aligned rectangle, optionally with rounded
+	// corners
+	KeepoutRectangle = ShapeRectangle
+	// KeepoutCircle is a circle
+	KeepoutCircle = ShapeCircle
+	// KeepoutPolygon is a closed polygon, optionally with rounded corners
+	KeepoutPolygon = ShapePolygon
+
+	// SideTop keeps components off the top of the board only
+	SideTop = "top"
+	// SideBottom keeps components off the bottom of the board only
+	SideBottom = "bottom"
+	// SideBoth keeps components off both sides of the board. This is the
+	// default as panels rarely have anything mounted on them.
+	SideBoth = "both"
+)
+
+// Keepout describes an area of a panel that must be kept clear of holes and
+// components, eg. where an enclosure has a screw boss behind the panel
+type Keepout struct {
+	Region `yaml:",inline"`
+	Side   string `yaml:"side"`
+}
+
+// KeepoutRegions is implemented by panels that define keepout areas
+type KeepoutRegions interface {
+	// Keepouts returns a list of areas that must be kept clear
+	Keepouts() []Keepout
+}
+
+// Validate checks that a keepout is fully and sensibly described
+func (k Keepout) Validate() error {
+	switch k.Side {
+	case "", SideTop, SideBottom, SideBoth:
+	default:
+		return fmt.Errorf("keepout side must be %q, %q or %q, not %q", SideTop, SideBottom, SideBoth, k.Side)
+	}
+	return k.Region.validate("keepout")
+}
+
+// Top reports whether a keepout applies to the top of the board
+func (k Keepout) Top() bool {
+	return k.Side != SideBottom
+}
+
+// Bottom reports whether a keepout applies to the bottom of the board
+func (k Keepout) Bottom() bool {
+	return k.Side != SideTop
+}
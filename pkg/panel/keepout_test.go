@@ -0,0 +1,71 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeepoutValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		keepout  Keepout
+		errorHas string
+	}{
+		{name: "default side", keepout: Keepout{Region: testCircle}},
+		{name: "top", keepout: Keepout{Region: testRectangle, Side: SideTop}},
+		{name: "bottom", keepout: Keepout{Region: testTriangle, Side: SideBottom}},
+		{name: "both", keepout: Keepout{Region: testRounded, Side: SideBoth}},
+		{name: "unknown side", keepout: Keepout{Region: testCircle, Side: "front"}, errorHas: `not "front"`},
+		{name: "bad region", keepout: Keepout{Region: Region{Shape: "star"}, Side: SideTop}, errorHas: `unsupported keepout shape "star"`},
+	}
+	for _, test := range tests {
+		err := test.keepout.Validate()
+		if test.errorHas == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name, err, test.errorHas)
+		}
+	}
+}
+
+func TestKeepoutSides(t *testing.T) {
+	tests := []struct {
+		side        string
+		top, bottom bool
+	}{
+		{side: "", top: true, bottom: true},
+		{side: SideBoth, top: true, bottom: true},
+		{side: SideTop, top: true},
+		{side: SideBottom, bottom: true},
+	}
+	for _, test := range tests {
+		k := Keepout{Region: testCircle, Side: test.side}
+		if k.Top() != test.top || k.Bottom() != test.bottom {
+			t.Errorf("side %q: got top %v bottom %v, want top %v bottom %v", test.side, k.Top(), k.Bottom(), test.top, test.bottom)
+		}
+	}
+}
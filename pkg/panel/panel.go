@@ -20,13 +20,19 @@
 
 package panel
 
+import "github.com/jsleeio/go-eagle/pkg/geometry"
+
 // Point defines a single metric coordinate in a 2D space.
 type Point struct {
 	X, Y float64
 }
 
-// Panel types encapsulate physical characteristics of a rail-mounted panel
-// All coordinates, distances and sizes are indicated in millimetres
+// Panel types encapsulate the physical characteristics common to all
+// panels: an outline and some mounting holes. Other features are described
+// by the optional ShapedOutline, ShapedCorners, Rails, Anchors,
+// KeepoutRegions, CutoutRegions and SlottedMountingHoles interfaces, which
+// users should discover with type assertions. All coordinates, distances
+// and sizes are indicated in millimetres
 type Panel interface {
 	// MountingHoles returns a list of Points indicating mounting hole locations
 	MountingHoles() []Point
@@ -58,7 +64,11 @@ type Panel interface {
 	// zero value will result in no corner segments being generated, and so the
 	// board outline will consist of four straight lines.
 	CornerRadius() float64
+}
 
+// Rails is implemented by panels that are fixed to horizontal mounting rails
+// along their top and bottom edges, as in most modular synthesizer formats
+type Rails interface {
 	// RailHeightFromMountingHole indicates how far up (from centre of bottom
 	// mounting hole) or down (from centre of top mounting hole) the mounting
 	// rail extends. This can be used to define KeepOut areas on the panel
@@ -77,7 +87,38 @@ type Panel interface {
 	// MountingHoleBottomY returns the Y coordinate for the bottom row of
 	// mounting holes
 	MountingHoleBottomY() float64
+}
 
+// RailBand is the span of Y coordinates covered by one mounting rail
+type RailBand struct {
+	Bottom, Top float64
+}
+
+// RailBands returns the spans covered by the bottom and top mounting rails,
+// in that order, or nil if the rails have no height
+func RailBands(r Rails) []RailBand {
+	height := r.RailHeightFromMountingHole()
+	if height <= 0 {
+		return nil
+	}
+	return []RailBand{
+		{Bottom: r.MountingHoleBottomY(), Top: r.MountingHoleBottomY() + height},
+		{Bottom: r.MountingHoleTopY() - height, Top: r.MountingHoleTopY()},
+	}
+}
+
+// ShapedOutline is implemented by panels that may not be rectangular. The
+// HorizontalFit and CornerRadius of such panels don't apply to the outline.
+type ShapedOutline interface {
+	// Outline returns the panel outline as a closed, counter-clockwise
+	// polygon that may have curved edges, or nil if the panel is a plain
+	// rectangle after all
+	Outline() []geometry.Vertex
+}
+
+// Anchors is implemented by panels with designated locations for header and
+// footer text
+type Anchors interface {
 	// HeaderLocation returns the location of the header text
 	HeaderLocation() Point
 
@@ -85,6 +126,20 @@ type Panel interface {
 	FooterLocation() Point
 }
 
+// SlottedMountingHoles is implemented by panels that may have oval mounting
+// slots instead of round mounting holes, allowing some sideways tolerance
+// when fitting a panel to rails with sliding nuts. Slots are horizontal,
+// centred on the MountingHoles locations, and MountingHoleDiameter wide.
+type SlottedMountingHoles interface {
+	// MountingSlotLength returns the overall length of the mounting slots,
+	// or zero if the panel has round mounting holes
+	MountingSlotLength() float64
+
+	// SetMountingSlotLength sets the overall length of the mounting slots.
+	// Use zero for round mounting holes
+	SetMountingSlotLength(length float64)
+}
+
 func LeftX(spec Panel) float64 {
 	return spec.HorizontalFit() / 2
 }
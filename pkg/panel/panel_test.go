@@ -0,0 +1,46 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testRails are rails with mounting holes 3mm from the bottom and top of a
+// 128.5mm panel
+type testRails struct {
+	height float64
+}
+
+func (r testRails) RailHeightFromMountingHole() float64 { return r.height }
+func (r testRails) MountingHoleTopY() float64           { return 125.5 }
+func (r testRails) MountingHoleBottomY() float64        { return 3 }
+
+func TestRailBands(t *testing.T) {
+	want := []RailBand{{Bottom: 3, Top: 8}, {Bottom: 120.5, Top: 125.5}}
+	if got := RailBands(testRails{height: 5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got bands %v, want %v", got, want)
+	}
+	if got := RailBands(testRails{}); got != nil {
+		t.Errorf("got bands %v for rails with no height, want none", got)
+	}
+}
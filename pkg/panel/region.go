@@ -0,0 +1,137 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+const (
+	// ShapeRectangle is an axis-aligned rectangle, optionally with rounded
+	// corners
+	ShapeRectangle = "rectangle"
+	// ShapeCircle is a circle
+	ShapeCircle = "circle"
+	// ShapePolygon is a closed polygon, optionally with rounded corners
+	ShapePolygon = "polygon"
+
+	// regionTolerance is the maximum deviation from true arcs when testing
+	// regions with rounded corners for intersections
+	regionTolerance = 0.01
+)
+
+// Region describes an area of a panel. Which fields are relevant depends on
+// the shape. All coordinates and sizes are in millimetres, relative to the
+// panel origin.
+type Region struct {
+	Shape string `yaml:"shape"`
+	// X, Y and Radius describe a circle
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Radius float64 `yaml:"radius"`
+	// X1, Y1, X2 and Y2 are opposite corners of a rectangle
+	X1 float64 `yaml:"x1"`
+	Y1 float64 `yaml:"y1"`
+	X2 float64 `yaml:"x2"`
+	Y2 float64 `yaml:"y2"`
+	// Vertices describes a polygon
+	Vertices []Point `yaml:"vertices"`
+	// CornerRadius rounds the corners of rectangles and polygons
+	CornerRadius float64 `yaml:"cornerRadius"`
+}
+
+// validate checks that a region is fully and sensibly described. The kind
+// of region, eg. "keepout", is used in error messages
+func (r Region) validate(kind string) error {
+	if r.CornerRadius < 0 {
+		return fmt.Errorf("%s corner radius can't be negative", kind)
+	}
+	switch r.Shape {
+	case ShapeCircle:
+		if r.Radius <= 0 {
+			return fmt.Errorf("circular %s needs a positive radius", kind)
+		}
+	case ShapeRectangle:
+		if r.X1 == r.X2 || r.Y1 == r.Y2 {
+			return fmt.Errorf("rectangular %s has zero area", kind)
+		}
+	case ShapePolygon:
+		if len(r.Vertices) < 3 {
+			return fmt.Errorf("polygon %s needs at least three vertices", kind)
+		}
+	default:
+		return fmt.Errorf("unsupported %s shape %q", kind, r.Shape)
+	}
+	return nil
+}
+
+// Outline returns the outline of a region, with any corner rounding
+// applied. Circles are described by two semicircular edges.
+func (r Region) Outline() []geometry.Vertex {
+	points := []geometry.Point{}
+	switch r.Shape {
+	case ShapeCircle:
+		return []geometry.Vertex{
+			{X: r.X + r.Radius, Y: r.Y, Curve: 180},
+			{X: r.X - r.Radius, Y: r.Y, Curve: 180},
+		}
+	case ShapeRectangle:
+		points = append(points,
+			geometry.Point{X: r.X1, Y: r.Y1},
+			geometry.Point{X: r.X2, Y: r.Y1},
+			geometry.Point{X: r.X2, Y: r.Y2},
+			geometry.Point{X: r.X1, Y: r.Y2})
+	case ShapePolygon:
+		for _, v := range r.Vertices {
+			points = append(points, geometry.Point{X: v.X, Y: v.Y})
+		}
+	default:
+		return nil
+	}
+	return geometry.RoundCorners(points, r.CornerRadius)
+}
+
+// IntersectsCircle reports whether a circle, eg. a panel hole or its
+// soldermask ring, overlaps a region
+func (r Region) IntersectsCircle(x, y, radius float64) bool {
+	centre := geometry.Point{X: x, Y: y}
+	if r.Shape == ShapeCircle {
+		return math.Hypot(x-r.X, y-r.Y) < radius+r.Radius
+	}
+	outline := geometry.Flatten(r.Outline(), regionTolerance)
+	if len(outline) == 0 {
+		return false
+	}
+	return geometry.PointInPolygon(centre, outline) || geometry.PolygonDistance(centre, outline) < radius
+}
+
+// IntersectsOutline reports whether a closed outline, eg. a panel cutout,
+// comes within margin millimetres of a region
+func (r Region) IntersectsOutline(outline []geometry.Point, margin float64) bool {
+	if r.Shape == ShapeCircle {
+		centre := geometry.Point{X: r.X, Y: r.Y}
+		return geometry.PointInPolygon(centre, outline) || geometry.PolygonDistance(centre, outline) < r.Radius+margin
+	}
+	return geometry.PolygonsDistance(geometry.Flatten(r.Outline(), regionTolerance), outline) < margin
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+var (
+	testCircle    = Region{Shape: ShapeCircle, X: 10, Y: 10, Radius: 5}
+	testRectangle = Region{Shape: ShapeRectangle, X1: 0, Y1: 0, X2: 20, Y2: 10}
+	testRounded   = Region{Shape: ShapeRectangle, X1: 0, Y1: 0, X2: 20, Y2: 10, CornerRadius: 4}
+	testTriangle  = Region{Shape: ShapePolygon, Vertices: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}}
+)
+
+func TestCutoutValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   Region
+		errorHas string
+	}{
+		{name: "circle", region: testCircle},
+		{name: "rectangle drawn backwards", region: Region{Shape: ShapeRectangle, X1: 20, Y1: 10, X2: 0, Y2: 0}},
+		{name: "rounded rectangle", region: testRounded},
+		{name: "polygon", region: testTriangle},
+		{name: "no shape", region: Region{Radius: 5}, errorHas: `unsupported cutout shape ""`},
+		{name: "unknown shape", region: Region{Shape: "star"}, errorHas: `unsupported cutout shape "star"`},
+		{name: "zero radius circle", region: Region{Shape: ShapeCircle, X: 1, Y: 1}, errorHas: "positive radius"},
+		{name: "flat rectangle", region: Region{Shape: ShapeRectangle, X1: 0, Y1: 5, X2: 20, Y2: 5}, errorHas: "zero area"},
+		{name: "two-vertex polygon", region: Region{Shape: ShapePolygon, Vertices: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}, errorHas: "three vertices"},
+		{name: "negative corner radius", region: Region{Shape: ShapeRectangle, X2: 1, Y2: 1, CornerRadius: -1}, errorHas: "corner radius"},
+	}
+	for _, test := range tests {
+		err := Cutout{Region: test.region}.Validate()
+		if test.errorHas == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name, err, test.errorHas)
+		}
+	}
+}
+
+func TestRegionOutline(t *testing.T) {
+	tests := []struct {
+		name   string
+		region Region
+		want   []geometry.Vertex
+	}{
+		{
+			// circles are two semicircles, not nil, so that they can be
+			// drawn like any other outline
+			name:   "circle",
+			region: testCircle,
+			want:   []geometry.Vertex{{X: 15, Y: 10, Curve: 180}, {X: 5, Y: 10, Curve: 180}},
+		},
+		{
+			name:   "rectangle",
+			region: testRectangle,
+			want:   []geometry.Vertex{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}, {X: 0, Y: 10}},
+		},
+		{
+			name:   "polygon",
+			region: testTriangle,
+			want:   []geometry.Vertex{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}},
+		},
+		{
+			name:   "unknown shape",
+			region: Region{Shape: "star"},
+		},
+	}
+	for _, test := range tests {
+		if got := test.region.Outline(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got outline %v, want %v", test.name, got, test.want)
+		}
+	}
+	// a rounded rectangle has each corner replaced by a quarter circle;
+	// flattening the arcs into chords loses a little area
+	got := testRounded.Outline()
+	if len(got) != 8 {
+		t.Fatalf("rounded rectangle: got outline %v, want 8 vertices", got)
+	}
+	area := geometry.Area(geometry.Flatten(got, 0.001))
+	if want := 20*10 - (4-math.Pi)*4*4; math.Abs(area-want) > 0.05 {
+		t.Errorf("rounded rectangle: got area %v, want %v", area, want)
+	}
+}
+
+func TestRegionIntersectsCircle(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     Region
+		x, y, r    float64
+		intersects bool
+	}{
+		{name: "circles overlapping", region: testCircle, x: 17, y: 10, r: 2.5, intersects: true},
+		{name: "circles apart", region: testCircle, x: 17, y: 10, r: 1.5},
+		{name: "inside rectangle", region: testRectangle, x: 10, y: 5, r: 1, intersects: true},
+		{name: "over rectangle edge", region: testRectangle, x: 21, y: 5, r: 1.5, intersects: true},
+		{name: "clear of rectangle", region: testRectangle, x: 22, y: 5, r: 1.5},
+		// clear of the rounded corner, but not of the square one
+		{name: "by rounded corner", region: testRounded, x: 21, y: 11, r: 1.2},
+		{name: "by square corner", region: testRectangle, x: 21, y: 11, r: 1.5, intersects: true},
+		{name: "beside triangle hypotenuse", region: testTriangle, x: 6, y: 6, r: 1},
+		{name: "touching triangle hypotenuse", region: testTriangle, x: 6, y: 6, r: 1.5, intersects: true},
+		{name: "unknown shape", region: Region{Shape: "star"}, x: 0, y: 0, r: 100},
+	}
+	for _, test := range tests {
+		if got := test.region.IntersectsCircle(test.x, test.y, test.r); got != test.intersects {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.intersects)
+		}
+	}
+}
+
+func TestRegionIntersectsOutline(t *testing.T) {
+	square := func(x1, y1, x2, y2 float64) []geometry.Point {
+		return []geometry.Point{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}}
+	}
+	tests := []struct {
+		name       string
+		region     Region
+		outline    []geometry.Point
+		margin     float64
+		intersects bool
+	}{
+		{name: "circle inside outline", region: testCircle, outline: square(0, 0, 30, 30), intersects: true},
+		{name: "circle near outline", region: testCircle, outline: square(16, 0, 20, 20), margin: 1.5, intersects: true},
+		{name: "circle clear of outline", region: testCircle, outline: square(16, 0, 20, 20), margin: 0.5},
+		{name: "rectangle near outline", region: testRectangle, outline: square(21, 0, 25, 5), margin: 1.5, intersects: true},
+		{name: "rectangle clear of outline", region: testRectangle, outline: square(21, 0, 25, 5), margin: 0.5},
+		{name: "outline crossing triangle", region: testTriangle, outline: square(2, -1, 3, 1), margin: 0.1, intersects: true},
+	}
+	for _, test := range tests {
+		if got := test.region.IntersectsOutline(test.outline, test.margin); got != test.intersects {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.intersects)
+		}
+	}
+}
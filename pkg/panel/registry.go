@@ -0,0 +1,168 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultFormat is the panel format commands use when none is specified
+	DefaultFormat = "eurorack"
+)
+
+// Options holds the parameters a command passes when constructing a panel.
+// Formats use whichever of these are relevant to them.
+type Options struct {
+	// Width is the size of the panel, in the integer units described by the
+	// format's Units field
+	Width int
+	// SpecFile is the name of a file describing the panel, for formats that
+	// are defined by one
+	SpecFile string
+	// MountingSlotLength, if not nil, overrides the format's own mounting
+	// slot length. Zero asks for round mounting holes.
+	MountingSlotLength *float64
+}
+
+// Format describes a panel format that commands may construct panels in
+type Format struct {
+	// Name is the short name used to select the format, eg. "eurorack"
+	Name string
+	// Description is a one-line, human-readable description of the format
+	Description string
+	// Units describes what Options.Width measures for this format, eg. "HP".
+	// Empty if the format ignores Options.Width.
+	Units string
+	// New constructs a panel in this format
+	New func(opts Options) (Panel, error)
+	// WidthForBoard returns the smallest Options.Width that a circuit board
+	// of the given dimensions, in millimetres, fits behind. May be nil if the
+	// format ignores Options.Width.
+	WidthForBoard func(width, height float64) int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Format{}
+)
+
+// Register makes a panel format available by name. It is intended to be
+// called from the init function of packages implementing formats, and
+// panics if the format is incomplete or its name is already taken.
+func Register(f Format) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if f.Name == "" || f.New == nil {
+		panic("panel: Register called with an incomplete format")
+	}
+	if _, dup := registry[f.Name]; dup {
+		panic("panel: Register called twice for format " + f.Name)
+	}
+	registry[f.Name] = f
+}
+
+// Lookup finds a registered panel format by name
+func Lookup(name string) (Format, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Formats returns all registered panel formats, sorted by name
+func Formats() []Format {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	formats := make([]Format, 0, len(registry))
+	for _, f := range registry {
+		formats = append(formats, f)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].Name < formats[j].Name })
+	return formats
+}
+
+// FormatNames returns the names of all registered panel formats, sorted
+func FormatNames() []string {
+	names := []string{}
+	for _, f := range Formats() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// FormatHelp describes all registered panel formats, one per line, suitable
+// for command usage messages
+func FormatHelp() string {
+	b := &strings.Builder{}
+	for _, f := range Formats() {
+		fmt.Fprintf(b, "  %s\n    \t%s", f.Name, f.Description)
+		if f.Units != "" {
+			fmt.Fprintf(b, " (width in %s)", f.Units)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// NewPanel constructs a panel in the named format, and applies the options
+// common to every format
+func NewPanel(name string, opts Options) (Panel, error) {
+	f, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", name)
+	}
+	p, err := f.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyOptions(p, opts); err != nil {
+		return nil, fmt.Errorf("format %s: %v", name, err)
+	}
+	return p, nil
+}
+
+// ApplyOptions applies the options common to every format to a newly
+// constructed panel. Formats handle the other options themselves.
+func ApplyOptions(p Panel, opts Options) error {
+	if opts.MountingSlotLength != nil {
+		return SetMountingSlotLength(p, *opts.MountingSlotLength)
+	}
+	return nil
+}
+
+// SetMountingSlotLength sets the overall length of a panel's mounting slots,
+// or asks for round mounting holes if length is zero. Only round holes are
+// possible for formats that don't implement SlottedMountingHoles.
+func SetMountingSlotLength(p Panel, length float64) error {
+	if length < 0 {
+		return fmt.Errorf("mounting slot length can't be negative")
+	}
+	if smh, ok := p.(SlottedMountingHoles); ok {
+		smh.SetMountingSlotLength(length)
+	} else if length > 0 {
+		return fmt.Errorf("mounting slots aren't supported")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testPanel is a plain panel whose width is set by Options.Width, in
+// millimetres
+type testPanel struct {
+	width      float64
+	slotLength float64
+}
+
+func (p *testPanel) MountingHoles() []Point        { return []Point{{X: 5, Y: 3}} }
+func (p *testPanel) MountingHoleDiameter() float64 { return 3.2 }
+func (p *testPanel) Height() float64               { return 100 }
+func (p *testPanel) Width() float64                { return p.width }
+func (p *testPanel) HorizontalFit() float64        { return 0 }
+func (p *testPanel) CornerRadius() float64         { return 0 }
+
+// slottedPanel is a testPanel that may have mounting slots
+type slottedPanel struct {
+	testPanel
+}
+
+func (p *slottedPanel) MountingSlotLength() float64          { return p.slotLength }
+func (p *slottedPanel) SetMountingSlotLength(length float64) { p.slotLength = length }
+
+var registerOnce sync.Once
+
+// registerTestFormats registers the test formats, once only, as the
+// registry can't be emptied again
+func registerTestFormats() {
+	registerOnce.Do(func() {
+		Register(Format{
+			Name:        "test-plain",
+			Description: "plain test panel",
+			Units:       "mm",
+			New: func(opts Options) (Panel, error) {
+				if opts.Width <= 0 {
+					return nil, fmt.Errorf("width must be positive")
+				}
+				return &testPanel{width: float64(opts.Width)}, nil
+			},
+		})
+		Register(Format{
+			Name:        "test-slotted",
+			Description: "slotted test panel",
+			New: func(opts Options) (Panel, error) {
+				return &slottedPanel{testPanel{width: 50, slotLength: 6}}, nil
+			},
+		})
+	})
+}
+
+func TestRegisterAndLookup(t *testing.T) {
+	registerTestFormats()
+	f, ok := Lookup("test-plain")
+	if !ok {
+		t.Fatalf("test-plain not found")
+	}
+	if f.Name != "test-plain" || f.Units != "mm" {
+		t.Errorf("got format %+v", f)
+	}
+	if _, ok := Lookup("no-such-format"); ok {
+		t.Errorf("found a format that was never registered")
+	}
+	names := FormatNames()
+	if !reflect.DeepEqual(names, []string{"test-plain", "test-slotted"}) {
+		t.Errorf("got format names %v", names)
+	}
+	help := FormatHelp()
+	want := "  test-plain\n    \tplain test panel (width in mm)\n  test-slotted\n    \tslotted test panel\n"
+	if help != want {
+		t.Errorf("got help %q, want %q", help, want)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	registerTestFormats()
+	newPanel := func(Options) (Panel, error) { return &testPanel{}, nil }
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{name: "duplicate", format: Format{Name: "test-plain", New: newPanel}},
+		{name: "no name", format: Format{New: newPanel}},
+		{name: "no constructor", format: Format{Name: "test-incomplete"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register didn't panic")
+				}
+			}()
+			Register(test.format)
+		})
+	}
+	if _, ok := Lookup("test-incomplete"); ok {
+		t.Errorf("incomplete format was registered")
+	}
+}
+
+func TestNewPanel(t *testing.T) {
+	registerTestFormats()
+	length := func(l float64) *float64 { return &l }
+	tests := []struct {
+		name     string
+		format   string
+		opts     Options
+		width    float64
+		slot     float64
+		errorHas string
+	}{
+		{name: "plain", format: "test-plain", opts: Options{Width: 40}, width: 40},
+		{name: "constructor error", format: "test-plain", errorHas: "width must be positive"},
+		{name: "unknown format", format: "no-such-format", errorHas: "unsupported format"},
+		{name: "format's own slots", format: "test-slotted", width: 50, slot: 6},
+		{name: "longer slots", format: "test-slotted", opts: Options{MountingSlotLength: length(10)}, width: 50, slot: 10},
+		{name: "round holes", format: "test-slotted", opts: Options{MountingSlotLength: length(0)}, width: 50, slot: 0},
+		{name: "negative slots", format: "test-slotted", opts: Options{MountingSlotLength: length(-1)}, errorHas: "negative"},
+		{name: "round holes, unslotted format", format: "test-plain", opts: Options{Width: 40, MountingSlotLength: length(0)}, width: 40},
+		{name: "slots, unslotted format", format: "test-plain", opts: Options{Width: 40, MountingSlotLength: length(6)}, errorHas: "format test-plain: mounting slots aren't supported"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := NewPanel(test.format, test.opts)
+			if test.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+					t.Errorf("got error %v, want one containing %q", err, test.errorHas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Width() != test.width {
+				t.Errorf("got width %v, want %v", p.Width(), test.width)
+			}
+			slot := 0.0
+			if smh, ok := p.(SlottedMountingHoles); ok {
+				slot = smh.MountingSlotLength()
+			}
+			if slot != test.slot {
+				t.Errorf("got slot length %v, want %v", slot, test.slot)
+			}
+		})
+	}
+}
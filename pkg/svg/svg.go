@@ -0,0 +1,317 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package svg renders Eagle boards as layered SVG images, for previewing
+// panel artwork without needing Eagle.
+package svg
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+	"github.com/jsleeio/go-eagle/pkg/vectorfont"
+)
+
+const (
+	// Margin is the amount of empty space added around the drawing
+	Margin = 2.0
+
+	// HairlineWidth is used in place of zero-width wires, which Eagle draws
+	// as thin as possible
+	HairlineWidth = 0.1
+
+	// Background is the colour behind all layers, matching Eagle's default
+	// black background palette
+	Background = "#000000"
+
+	// arcTolerance is used when approximating arcs to find drawing extents
+	arcTolerance = 0.05
+)
+
+// palette holds Eagle's default colours for a black background. Layer colour
+// indices beyond this are shown as a lighter version of the colour with the
+// same index modulo 16, which is close enough for previews.
+var palette = []string{
+	"#000000", "#3232c8", "#32c832", "#32c8c8", "#c83232", "#c832c8", "#c8c832", "#c8c8c8",
+	"#646464", "#0000ff", "#00ff00", "#00ffff", "#ff0000", "#ff00ff", "#ffff00", "#ffffff",
+}
+
+// LayerColour returns an SVG colour for an Eagle layer colour index
+func LayerColour(index int) string {
+	if index >= 0 && index < len(palette) {
+		return palette[index]
+	}
+	base, _ := strconv.ParseUint(palette[(index%16+16)%16][1:], 16, 32)
+	r, g, b := (base>>16)&0xff, (base>>8)&0xff, base&0xff
+	return fmt.Sprintf("#%02x%02x%02x", (r+0xff)/2, (g+0xff)/2, (b+0xff)/2)
+}
+
+// WriteFile renders an Eagle board to an SVG file
+func WriteFile(e *eagle.Eagle, filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return Render(e, file)
+}
+
+// Render draws the Plain section of an Eagle board as SVG. Each Eagle layer
+// with something on it becomes an SVG group, drawn in its Eagle colour, with
+// bottom-side layers drawn first. Holes are drawn last, on top of everything.
+func Render(e *eagle.Eagle, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	xmin, ymin, xmax, ymax, err := extents(e.Board.Plain)
+	if err != nil {
+		return err
+	}
+	xmin, ymin, xmax, ymax = xmin-Margin, ymin-Margin, xmax+Margin, ymax+Margin
+	width, height := xmax-xmin, ymax-ymin
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%smm\" height=\"%smm\" viewBox=\"%s %s %s %s\">\n",
+		num(width), num(height), num(xmin), num(-ymax), num(width), num(height))
+	fmt.Fprintf(out, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+		num(xmin), num(-ymax), num(width), num(height), Background)
+	// Eagle's Y axis points up, SVG's points down
+	fmt.Fprintf(out, "<g transform=\"scale(1,-1)\" stroke-linecap=\"round\" stroke-linejoin=\"round\">\n")
+	for _, layer := range drawingOrder(e.Layers) {
+		if err := renderLayer(out, e.Board.Plain, layer); err != nil {
+			return err
+		}
+	}
+	holeColour := "#808080"
+	for _, layer := range e.Layers {
+		if layer.Name == "Holes" {
+			holeColour = LayerColour(layer.Color)
+		}
+	}
+	if len(e.Board.Plain.Holes) > 0 {
+		fmt.Fprintf(out, "<g id=\"holes\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%s\">\n", Background, holeColour, num(HairlineWidth))
+		for _, hole := range e.Board.Plain.Holes {
+			fmt.Fprintf(out, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\"/>\n", num(hole.X), num(hole.Y), num(hole.Drill/2))
+		}
+		fmt.Fprintf(out, "</g>\n")
+	}
+	fmt.Fprintf(out, "</g>\n</svg>\n")
+	return out.Flush()
+}
+
+// drawingOrder sorts layers so that bottom-side copper is drawn first, then
+// the other copper layers working upwards, then everything else in layer
+// number order
+func drawingOrder(layers []eagle.Layer) []eagle.Layer {
+	sorted := append([]eagle.Layer{}, layers...)
+	key := func(l eagle.Layer) int {
+		if l.Number <= 16 {
+			return -l.Number
+		}
+		return l.Number
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i]) < key(sorted[j])
+	})
+	return sorted
+}
+
+func renderLayer(out io.Writer, plain eagle.Plain, layer eagle.Layer) error {
+	var body strings.Builder
+	colour := LayerColour(layer.Color)
+	for _, polygon := range plain.Polygons {
+		if polygon.Layer != layer.Number {
+			continue
+		}
+		fill := colour
+		if polygon.Pour == "cutout" {
+			fill = Background
+		}
+		fmt.Fprintf(&body, "<path d=\"%s\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+			vertexPath(polygon.Vertices), fill, fill, num(polygon.Width))
+	}
+	for _, rectangle := range plain.Rectangles {
+		if rectangle.Layer != layer.Number {
+			continue
+		}
+		corners, err := rectangle.Corners()
+		if err != nil {
+			return fmt.Errorf("rectangle on layer %d: %v", rectangle.Layer, err)
+		}
+		fmt.Fprintf(&body, "<path d=\"%s\" fill=\"%s\" stroke=\"none\"/>\n", vertexPath(corners), colour)
+	}
+	for _, wire := range plain.Wires {
+		if wire.Layer != layer.Number {
+			continue
+		}
+		fmt.Fprintf(&body, "<path d=\"M%s %s%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+			num(wire.X1), num(wire.Y1), arcTo(wire.X1, wire.Y1, wire.X2, wire.Y2, wire.Curve), colour, num(strokeWidth(wire.Width)))
+	}
+	for _, circle := range plain.Circles {
+		if circle.Layer != layer.Number {
+			continue
+		}
+		if circle.Width == 0 {
+			fmt.Fprintf(&body, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"%s\"/>\n", num(circle.X), num(circle.Y), num(circle.Radius), colour)
+		} else {
+			fmt.Fprintf(&body, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+				num(circle.X), num(circle.Y), num(circle.Radius), colour, num(circle.Width))
+		}
+	}
+	for _, text := range plain.Texts {
+		if text.Layer != layer.Number {
+			continue
+		}
+		strokes, err := vectorfont.Strokes(text)
+		if err != nil {
+			return fmt.Errorf("text %q: %v", text.Text, err)
+		}
+		fmt.Fprintf(&body, "<path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+			strokesPath(strokes), colour, num(vectorfont.StrokeWidth(text)))
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "<g id=\"layer-%d\" class=\"%s\">\n%s</g>\n", layer.Number, html.EscapeString(layer.Name), body.String())
+	return nil
+}
+
+func strokeWidth(w float64) float64 {
+	if w <= 0 {
+		return HairlineWidth
+	}
+	return w
+}
+
+// arcTo returns an SVG path command continuing from (x1,y1) to (x2,y2),
+// sweeping curve degrees as Eagle does. After the Y axis flip, a positive
+// sweep flag is counter-clockwise, matching positive Eagle curve values.
+func arcTo(x1, y1, x2, y2, curve float64) string {
+	if curve == 0 {
+		return fmt.Sprintf(" L%s %s", num(x2), num(y2))
+	}
+	_, _, r := geometry.ArcCentre(x1, y1, x2, y2, curve)
+	large, sweep := 0, 0
+	if math.Abs(curve) > 180 {
+		large = 1
+	}
+	if curve > 0 {
+		sweep = 1
+	}
+	return fmt.Sprintf(" A%s %s 0 %d %d %s %s", num(r), num(r), large, sweep, num(x2), num(y2))
+}
+
+// vertexPath converts a closed list of Eagle vertices into SVG path data.
+// Each vertex's curve applies to the edge running from it to the next.
+func vertexPath(vertices []eagle.Vertex) string {
+	if len(vertices) == 0 {
+		return ""
+	}
+	var d strings.Builder
+	fmt.Fprintf(&d, "M%s %s", num(vertices[0].X), num(vertices[0].Y))
+	for i, v := range vertices {
+		next := vertices[(i+1)%len(vertices)]
+		d.WriteString(arcTo(v.X, v.Y, next.X, next.Y, v.Curve))
+	}
+	d.WriteString(" Z")
+	return d.String()
+}
+
+func strokesPath(strokes []vectorfont.Stroke) string {
+	var d strings.Builder
+	for _, stroke := range strokes {
+		for i, p := range stroke {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(&d, "%s%s %s ", command, num(p.X), num(p.Y))
+		}
+		if len(stroke) == 1 {
+			// zero-length segment, so that round caps draw a dot
+			fmt.Fprintf(&d, "L%s %s ", num(stroke[0].X), num(stroke[0].Y))
+		}
+	}
+	return strings.TrimSpace(d.String())
+}
+
+// extents finds the area covered by everything in a Plain section
+func extents(plain eagle.Plain) (xmin, ymin, xmax, ymax float64, err error) {
+	first := true
+	add := func(x, y, r float64) {
+		if first {
+			xmin, ymin, xmax, ymax = x-r, y-r, x+r, y+r
+			first = false
+		}
+		xmin, ymin = math.Min(xmin, x-r), math.Min(ymin, y-r)
+		xmax, ymax = math.Max(xmax, x+r), math.Max(ymax, y+r)
+	}
+	for _, wire := range plain.Wires {
+		for _, p := range geometry.ArcPoints(wire.X1, wire.Y1, wire.X2, wire.Y2, wire.Curve, arcTolerance) {
+			add(p.X, p.Y, wire.Width/2)
+		}
+	}
+	for _, circle := range plain.Circles {
+		add(circle.X, circle.Y, circle.Radius+circle.Width/2)
+	}
+	for _, hole := range plain.Holes {
+		add(hole.X, hole.Y, hole.Drill/2)
+	}
+	for _, rectangle := range plain.Rectangles {
+		corners, err := rectangle.Corners()
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("rectangle on layer %d: %v", rectangle.Layer, err)
+		}
+		for _, v := range corners {
+			add(v.X, v.Y, 0)
+		}
+	}
+	for _, polygon := range plain.Polygons {
+		for i, v := range polygon.Vertices {
+			next := polygon.Vertices[(i+1)%len(polygon.Vertices)]
+			for _, p := range geometry.ArcPoints(v.X, v.Y, next.X, next.Y, v.Curve, arcTolerance) {
+				add(p.X, p.Y, polygon.Width/2)
+			}
+		}
+	}
+	for _, text := range plain.Texts {
+		x1, y1, x2, y2, ok, err := vectorfont.Bounds(text)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("text %q: %v", text.Text, err)
+		}
+		if ok {
+			add(x1, y1, 0)
+			add(x2, y2, 0)
+		}
+	}
+	return xmin, ymin, xmax, ymax, nil
+}
+
+// num formats a coordinate compactly, to a precision well beyond anything
+// a fab house can manage
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
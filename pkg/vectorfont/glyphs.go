@@ -0,0 +1,157 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package vectorfont
+
+import (
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+// glyphs holds a simple stroke font covering printable ASCII. Each glyph is
+// a space-separated list of strokes, and each stroke is a list of points
+// encoded as two characters: an X coordinate of 0-6 and a Y coordinate of
+// 0-9, or 'a' and 'b' for -1 and -2 (descenders). Capitals are 8 units high.
+var glyphs = map[rune]string{
+	' ':  "",
+	'!':  "3832 3130",
+	'"':  "2826 4846",
+	'#':  "2721 4741 0565 0363",
+	'$':  "675818070514546361501001 393a",
+	'%':  "0068 0828260608 4262604042",
+	'&':  "601517283847460201103063",
+	'\'': "3836",
+	'(':  "48262240",
+	')':  "28464220",
+	'*':  "3236 1355 1553",
+	'+':  "3137 0464",
+	',':  "31302a",
+	'-':  "1454",
+	'.':  "3130",
+	'/':  "0068",
+	'0':  "100107185867615010",
+	'1':  "163830 1050",
+	'2':  "07185867650060",
+	'3':  "07185867655424 546361501001",
+	'4':  "50580262",
+	'5':  "680805556461501001",
+	'6':  "58280601105061635404",
+	'7':  "086820",
+	'8':  "14050718586765541403011050616354",
+	'9':  "10406267581807051464",
+	':':  "3635 3130",
+	';':  "3635 31302a",
+	'<':  "670461",
+	'=':  "0565 0363",
+	'>':  "076401",
+	'?':  "07185867653332 3130",
+	'@':  "45251413224245 4252636646282806022050",
+	'A':  "000628486660 0464",
+	'B':  "00085867655404 5463615000",
+	'C':  "6758180701105061",
+	'D':  "00084866624000",
+	'E':  "68080060 0444",
+	'F':  "680800 0444",
+	'G':  "67581807011050616434",
+	'H':  "0008 6860 0464",
+	'I':  "1858 3830 1050",
+	'J':  "686150100102 3868",
+	'K':  "0008 6803 2560",
+	'L':  "080060",
+	'M':  "0008346860",
+	'N':  "00086068",
+	'O':  "100107185867615010",
+	'P':  "00085867655404",
+	'Q':  "100107185867615010 4260",
+	'R':  "00085867655404 3460",
+	'S':  "675818070514546361501001",
+	'T':  "0868 3830",
+	'U':  "080110506168",
+	'V':  "083068",
+	'W':  "0810345068",
+	'X':  "0068 0860",
+	'Y':  "083468 3430",
+	'Z':  "08680060",
+	'[':  "48282040",
+	'\\': "0860",
+	']':  "28484020",
+	'^':  "163856",
+	'_':  "0a6a",
+	'`':  "2837",
+	'a':  "15556460 63130201105061",
+	'b':  "0800 0415556461501001",
+	'c':  "6455150401105061",
+	'd':  "6860 6455150401105061",
+	'e':  "036364551504011050",
+	'f':  "58382720 0545",
+	'g':  "656a5b1b0a 6455150402115162",
+	'h':  "0800 0415556460",
+	'i':  "3530 3736",
+	'j':  "454a3b1b 4746",
+	'k':  "0800 5502 2350",
+	'l':  "28213040",
+	'm':  "0500 0415253430 3445556460",
+	'n':  "0500 0415556460",
+	'o':  "100104155564615010",
+	'p':  "050b 0415556461501001",
+	'q':  "656b 6455150401105061",
+	'r':  "0500 032555",
+	's':  "64551504135261501001",
+	't':  "27213050 0545",
+	'u':  "0501105061 6560",
+	'v':  "053065",
+	'w':  "0510335065",
+	'x':  "0065 0560",
+	'y':  "0530 652b",
+	'z':  "05650060",
+	'{':  "483827251423213040",
+	'|':  "393a",
+	'}':  "283847455443413020",
+	'~':  "041525435364",
+}
+
+// glyph decodes the strokes for a character, in glyph units. Characters
+// without a glyph are drawn as a question mark.
+func glyph(r rune) [][]geometry.Point {
+	encoded, ok := glyphs[r]
+	if !ok {
+		encoded = glyphs['?']
+	}
+	strokes := [][]geometry.Point{}
+	for _, field := range strings.Fields(encoded) {
+		stroke := []geometry.Point{}
+		for i := 0; i+1 < len(field); i += 2 {
+			stroke = append(stroke, geometry.Point{X: glyphCoord(field[i]), Y: glyphCoord(field[i+1])})
+		}
+		strokes = append(strokes, stroke)
+	}
+	return strokes
+}
+
+func glyphCoord(c byte) float64 {
+	switch c {
+	case 'a':
+		return -1
+	case 'b':
+		return -2
+	}
+	return float64(c - '0')
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 John Slee <jslee@jslee.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package vectorfont renders Eagle text objects as strokes, approximating
+// Eagle's built-in vector font. This is needed anywhere text has to be drawn
+// without Eagle's help, eg. fabrication outputs and previews.
+package vectorfont
+
+import (
+	"math"
+	"strings"
+
+	"github.com/jsleeio/go-eagle/pkg/eagle"
+	"github.com/jsleeio/go-eagle/pkg/geometry"
+)
+
+const (
+	// capHeight is the height of a capital letter, in glyph units. Eagle's
+	// text size is the cap height, so this is also the glyph unit scale
+	capHeight = 8.0
+
+	// advance is the horizontal distance between successive character
+	// origins, in glyph units
+	advance = 7.5
+
+	// glyphWidth is the width of the widest glyph, in glyph units
+	glyphWidth = 6.0
+
+	// DefaultRatio is the Eagle default stroke width, as a percentage of the
+	// text size
+	DefaultRatio = 8
+
+	// DefaultDistance is the Eagle default line spacing, as a percentage of
+	// the text size
+	DefaultDistance = 50
+)
+
+// Stroke is a connected series of line segments. A single-point stroke is
+// a dot.
+type Stroke []geometry.Point
+
+// StrokeWidth returns the pen width Eagle would use for a text object
+func StrokeWidth(t eagle.Text) float64 {
+	ratio := t.Ratio
+	if ratio == 0 {
+		ratio = DefaultRatio
+	}
+	return t.Size * float64(ratio) / 100.0
+}
+
+// Strokes lays out a text object and returns the strokes required to draw
+// it, in board coordinates. Alignment, rotation, mirroring, multiple lines
+// and Eagle's keep-text-readable behaviour for unspun text are honoured.
+func Strokes(t eagle.Text) ([]Stroke, error) {
+	rot, err := eagle.ParseRotation(t.Rotate)
+	if err != nil {
+		return nil, err
+	}
+	halign, valign := parseAlign(t.Align)
+	// Eagle keeps text readable unless the spin flag is set, by rotating it
+	// a further 180 degrees and flipping its alignment
+	if !rot.Spin && rot.Angle > 90 && rot.Angle <= 270 {
+		rot.Angle -= 180
+		halign, valign = -halign, -valign
+	}
+	distance := t.Distance
+	if distance == 0 {
+		distance = DefaultDistance
+	}
+	scale := t.Size / capHeight
+	pitch := t.Size * (1 + distance/100.0)
+	lines := strings.Split(t.Text, "\n")
+	blockHeight := t.Size + pitch*float64(len(lines)-1)
+	// baseline Y of the first line, relative to the text origin
+	top := 0.0
+	switch valign {
+	case alignBottom:
+		top = blockHeight - t.Size
+	case alignMiddle:
+		top = blockHeight/2 - t.Size
+	case alignTop:
+		top = -t.Size
+	}
+	place := geometry.Placement(t.X, t.Y, rot)
+	strokes := []Stroke{}
+	for index, line := range lines {
+		width := lineWidth(line) * scale
+		left := 0.0
+		switch halign {
+		case alignCentre:
+			left = -width / 2
+		case alignRight:
+			left = -width
+		}
+		baseline := top - pitch*float64(index)
+		for column, r := range []rune(line) {
+			originX := left + float64(column)*advance*scale
+			for _, gs := range glyph(r) {
+				stroke := Stroke{}
+				for _, gp := range gs {
+					stroke = append(stroke, place.Point(geometry.Point{X: originX + gp.X*scale, Y: baseline + gp.Y*scale}))
+				}
+				strokes = append(strokes, stroke)
+			}
+		}
+	}
+	return strokes, nil
+}
+
+// Bounds returns the extents of the strokes for a text object, including
+// the stroke width. ok is false if there is nothing to draw.
+func Bounds(t eagle.Text) (xmin, ymin, xmax, ymax float64, ok bool, err error) {
+	strokes, err := Strokes(t)
+	if err != nil {
+		return 0, 0, 0, 0, false, err
+	}
+	half := StrokeWidth(t) / 2
+	for _, stroke := range strokes {
+		for _, p := range stroke {
+			if !ok {
+				xmin, ymin, xmax, ymax = p.X, p.Y, p.X, p.Y
+				ok = true
+			}
+			xmin, ymin = math.Min(xmin, p.X), math.Min(ymin, p.Y)
+			xmax, ymax = math.Max(xmax, p.X), math.Max(ymax, p.Y)
+		}
+	}
+	return xmin - half, ymin - half, xmax + half, ymax + half, ok, nil
+}
+
+func lineWidth(line string) float64 {
+	n := len([]rune(line))
+	if n == 0 {
+		return 0
+	}
+	return float64(n-1)*advance + glyphWidth
+}
+
+const (
+	alignLeft   = -1
+	alignCentre = 0
+	alignRight  = 1
+
+	alignBottom = -1
+	alignMiddle = 0
+	alignTop    = 1
+)
+
+// parseAlign converts an Eagle align attribute value into horizontal and
+// vertical alignment values. Eagle's default is bottom-left.
+func parseAlign(align string) (int, int) {
+	switch align {
+	case "center":
+		return alignCentre, alignMiddle
+	case "":
+		return alignLeft, alignBottom
+	}
+	halign, valign := alignLeft, alignBottom
+	parts := strings.SplitN(align, "-", 2)
+	switch parts[0] {
+	case "center":
+		valign = alignMiddle
+	case "top":
+		valign = alignTop
+	}
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "center":
+			halign = alignCentre
+		case "right":
+			halign = alignRight
+		}
+	}
+	return halign, valign
+}